@@ -0,0 +1,69 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+
+	"helm.sh/helm/v3/cmd/helm/require"
+	"helm.sh/helm/v3/pkg/action"
+)
+
+const capabilitiesExportHelp = `
+This command prints the Kubernetes version and the API versions of the cluster
+as YAML:
+
+    apiVersions:
+    - apps/v1
+    - v1
+    kubeVersion: v1.18.3
+
+Given to 'helm template', 'helm lint' or 'helm install --dry-run' with
+'--capabilities-file', the file makes them render charts as they would be
+rendered in the cluster, without access to it:
+
+    $ helm capabilities export > caps.yaml
+    $ helm template --capabilities-file caps.yaml myredis ./redis
+`
+
+func newCapabilitiesExportCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
+	client := action.NewCapabilities(cfg)
+
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "print the capabilities of the cluster",
+		Long:  capabilitiesExportHelp,
+		Args:  require.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			caps, err := client.Run()
+			if err != nil {
+				return err
+			}
+			s, err := caps.YAML()
+			if err != nil {
+				return err
+			}
+			fmt.Fprint(out, s)
+			return nil
+		},
+	}
+
+	return cmd
+}
@@ -18,28 +18,56 @@ package main
 
 import (
 	"fmt"
+	"io/ioutil"
+	"os"
 	"strings"
 
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 
 	"helm.sh/helm/v3/internal/completion"
 	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/chartutil"
 	"helm.sh/helm/v3/pkg/cli/output"
 	"helm.sh/helm/v3/pkg/cli/values"
+	"helm.sh/helm/v3/pkg/engine"
+	"helm.sh/helm/v3/pkg/lint"
+	"helm.sh/helm/v3/pkg/lint/support"
 	"helm.sh/helm/v3/pkg/postrender"
+	"helm.sh/helm/v3/pkg/storage"
 )
 
 const outputFlag = "output"
 const postRenderFlag = "post-renderer"
+const lookupFixturesFlag = "lookup-fixtures"
+const capabilitiesFileFlag = "capabilities-file"
 
 func addValueOptionsFlags(f *pflag.FlagSet, v *values.Options) {
 	f.StringSliceVarP(&v.ValueFiles, "values", "f", []string{}, "specify values in a YAML file or a URL (can specify multiple)")
-	f.StringArrayVar(&v.Values, "set", []string{}, "set values on the command line (can specify multiple or separate values with commas: key1=val1,key2=val2)")
+	f.StringArrayVar(&v.PatchFiles, "values-patch", []string{}, "apply a JSON patch (RFC 6902) or JSON merge patch (RFC 7386) in a YAML or JSON file or a URL to the values files (can specify multiple)")
+	f.StringArrayVar(&v.Values, "set", []string{}, "set values on the command line (can specify multiple or separate values with commas: key1=val1,key2=val2). Keys prefixed with 'pointer:' are JSON pointers: pointer:/key1/key~1with~1slashes=val1")
+	f.StringArrayVar(&v.JSONValues, "set-json", []string{}, "set JSON values on the command line (can specify multiple or separate values with commas: key1=jsonval1,key2=jsonval2)")
 	f.StringArrayVar(&v.StringValues, "set-string", []string{}, "set STRING values on the command line (can specify multiple or separate values with commas: key1=val1,key2=val2)")
 	f.StringArrayVar(&v.FileValues, "set-file", []string{}, "set values from respective files specified via the command line (can specify multiple or separate values with commas: key1=path1,key2=path2)")
 }
 
+// addReleaseValuesFlags adds the flags reading values from other releases. The
+// releases of the current namespace are read from cfg.
+func addReleaseValuesFlags(f *pflag.FlagSet, v *values.Options, cfg *action.Configuration) {
+	f.StringArrayVar(&v.ReleaseValues, "set-from-release", []string{}, "set values from a deployed release (can specify multiple or separate values with commas: key1=[namespace/]release:.Values.path,key2=[namespace/]release:.Outputs.path)")
+	v.ReleaseStorage = func(namespace string) (*storage.Storage, error) {
+		if namespace == "" || namespace == settings.Namespace() {
+			return cfg.Releases, nil
+		}
+		other := new(action.Configuration)
+		if err := other.Init(settings.RESTClientGetter(), namespace, os.Getenv("HELM_DRIVER"), debug); err != nil {
+			return nil, err
+		}
+		return other.Releases, nil
+	}
+}
+
 func addChartPathOptionsFlags(f *pflag.FlagSet, c *action.ChartPathOptions) {
 	f.StringVar(&c.Version, "version", "", "specify the exact chart version to install. If this is not specified, the latest version is installed")
 	f.BoolVar(&c.Verify, "verify", false, "verify the package before installing it")
@@ -58,10 +86,23 @@ func bindOutputFlag(cmd *cobra.Command, varRef *output.Format) {
 	f := cmd.Flags()
 	flag := f.VarPF(newOutputValue(output.Table, varRef), outputFlag, "o",
 		fmt.Sprintf("prints the output in the specified format. Allowed values: %s", strings.Join(output.Formats(), ", ")))
+	registerFormatCompletion(flag, output.Formats())
+}
 
+// bindReportOutputFlag adds the output flag to a command writing a report
+// with an output.ReportWriter, which also supports the formats of code
+// scanning and CI tools
+func bindReportOutputFlag(cmd *cobra.Command, varRef *output.Format) {
+	f := cmd.Flags()
+	flag := f.VarPF(reportOutputValue{newOutputValue(output.Table, varRef)}, outputFlag, "o",
+		fmt.Sprintf("prints the output in the specified format. Allowed values: %s", strings.Join(output.ReportFormats(), ", ")))
+	registerFormatCompletion(flag, output.ReportFormats())
+}
+
+func registerFormatCompletion(flag *pflag.Flag, formats []string) {
 	completion.RegisterFlagCompletionFunc(flag, func(cmd *cobra.Command, args []string, toComplete string) ([]string, completion.BashCompDirective) {
 		var formatNames []string
-		for _, format := range output.Formats() {
+		for _, format := range formats {
 			if strings.HasPrefix(format, toComplete) {
 				formatNames = append(formatNames, format)
 			}
@@ -97,6 +138,19 @@ func (o *outputValue) Set(s string) error {
 	return nil
 }
 
+type reportOutputValue struct {
+	*outputValue
+}
+
+func (o reportOutputValue) Set(s string) error {
+	outfmt, err := output.ParseReportFormat(s)
+	if err != nil {
+		return err
+	}
+	*o.outputValue = outputValue(outfmt)
+	return nil
+}
+
 func bindPostRenderFlag(cmd *cobra.Command, varRef *postrender.PostRenderer) {
 	cmd.Flags().Var(&postRenderer{varRef}, postRenderFlag, "the path to an executable to be used for post rendering. If it exists in $PATH, the binary will be used, otherwise it will try to look for the executable at the given path")
 }
@@ -124,3 +178,181 @@ func (p postRenderer) Set(s string) error {
 	*p.renderer = pr
 	return nil
 }
+
+func addStrictValuesFlags(f *pflag.FlagSet, strict *bool, optional *[]string) {
+	f.BoolVar(strict, "strict-values", false, "fail on every reference of the templates to a value which is not set, with its template and line")
+	f.StringSliceVar(optional, "optional-values", []string{}, "paths of values which may be missing with --strict-values, e.g. 'ingress.annotations'. '*' matches any key (can specify multiple or separate values with commas)")
+}
+
+func bindLookupFixturesFlag(cmd *cobra.Command, varRef **engine.Fixtures) {
+	cmd.Flags().Var(&lookupFixtures{varRef}, lookupFixturesFlag, "a YAML or JSON file, or a directory of such files, of Kubernetes objects returned by the lookup template function instead of querying the cluster")
+}
+
+type lookupFixtures struct {
+	fixtures **engine.Fixtures
+}
+
+func (l lookupFixtures) String() string {
+	return ""
+}
+
+func (l lookupFixtures) Type() string {
+	return "path"
+}
+
+func (l lookupFixtures) Set(s string) error {
+	if s == "" {
+		return nil
+	}
+	f, err := engine.LoadFixtures(s)
+	if err != nil {
+		return err
+	}
+	*l.fixtures = f
+	return nil
+}
+
+func bindCapabilitiesFileFlag(cmd *cobra.Command, varRef **chartutil.Capabilities) {
+	cmd.Flags().Var(&capabilitiesFile{varRef}, capabilitiesFileFlag, "a file of the Kubernetes version and API versions used for rendering instead of the defaults, as exported by 'helm capabilities export'")
+}
+
+type capabilitiesFile struct {
+	caps **chartutil.Capabilities
+}
+
+func (c capabilitiesFile) String() string {
+	return ""
+}
+
+func (c capabilitiesFile) Type() string {
+	return "path"
+}
+
+func (c capabilitiesFile) Set(s string) error {
+	if s == "" {
+		return nil
+	}
+	caps, err := chartutil.ReadCapabilitiesFile(s)
+	if err != nil {
+		return err
+	}
+	*c.caps = caps
+	return nil
+}
+
+func bindDeprecatedAPIsFileFlag(cmd *cobra.Command, varRef *chartutil.DeprecatedAPIs) {
+	cmd.Flags().Var(&deprecatedAPIsFile{varRef}, "deprecations-file", "a file of deprecated Kubernetes APIs, with the versions deprecating and removing them, overriding the built-in table")
+}
+
+type deprecatedAPIsFile struct {
+	apis *chartutil.DeprecatedAPIs
+}
+
+func (d deprecatedAPIsFile) String() string {
+	return ""
+}
+
+func (d deprecatedAPIsFile) Type() string {
+	return "path"
+}
+
+func (d deprecatedAPIsFile) Set(s string) error {
+	if s == "" {
+		return nil
+	}
+	apis, err := chartutil.ReadDeprecatedAPIsFile(s)
+	if err != nil {
+		return err
+	}
+	*d.apis = apis
+	return nil
+}
+
+// lintConfigFile is the lint configuration file given with --config. It is
+// checked against the rules once the lint plugins are loaded.
+type lintConfigFile struct {
+	config **support.Config
+	path   string
+}
+
+func (c *lintConfigFile) String() string {
+	return c.path
+}
+
+func (c *lintConfigFile) Type() string {
+	return "path"
+}
+
+func (c *lintConfigFile) Set(s string) error {
+	if s == "" {
+		return nil
+	}
+	data, err := ioutil.ReadFile(s)
+	if err != nil {
+		return err
+	}
+	config, err := support.ParseConfig(data)
+	if err != nil {
+		return errors.Wrapf(err, "invalid lint configuration %s", s)
+	}
+	*c.config, c.path = config, s
+	return nil
+}
+
+// resolve checks that the configuration configures known rules.
+func (c *lintConfigFile) resolve(known []support.Rule, checkers []support.Checker) error {
+	if *c.config == nil {
+		return nil
+	}
+	if err := lint.CheckPluginConfig(*c.config, checkers); err != nil {
+		return errors.Wrapf(err, "invalid lint configuration %s", c.path)
+	}
+	if _, err := (*c.config).Resolve(known); err != nil {
+		return errors.Wrapf(err, "invalid lint configuration %s", c.path)
+	}
+	return nil
+}
+
+// auditedFlags are the flags whose values are recorded in the audit trail of
+// a release. The values of other flags, which may carry secrets such as
+// credentials, values or URLs, are redacted; those of boolean flags are always
+// recorded.
+var auditedFlags = map[string]bool{
+	"api-versions":      true,
+	"capabilities-file": true,
+	"deprecations-file": true,
+	"description":       true,
+	"history-max":       true,
+	"kube-context":      true,
+	"kube-version":      true,
+	"lookup-fixtures":   true,
+	"max":               true,
+	"name-template":     true,
+	"namespace":         true,
+	"optional-values":   true,
+	"output":            true,
+	"render-seed":       true,
+	"render-time":       true,
+	"revision":          true,
+	"timeout":           true,
+	"to-driver":         true,
+	"to-namespace":      true,
+	"version":           true,
+}
+
+// auditFlags returns the flags explicitly set on the command line in the form
+// --name=value. The values of flags that may carry secrets are redacted.
+func auditFlags(fs *pflag.FlagSet) []string {
+	var flags []string
+	fs.VisitAll(func(f *pflag.Flag) {
+		if !f.Changed {
+			return
+		}
+		value := f.Value.String()
+		if !auditedFlags[f.Name] && f.Value.Type() != "bool" {
+			value = "<redacted>"
+		}
+		flags = append(flags, fmt.Sprintf("--%s=%s", f.Name, value))
+	})
+	return flags
+}
@@ -86,3 +86,17 @@ func outputFlagCompletionTest(t *testing.T, cmdName string) {
 	}}
 	runTestCmd(t, tests)
 }
+
+func TestAuditFlags(t *testing.T) {
+	defer resetEnv()()
+
+	cmd, _, err := executeActionCommandC(storageFixture(), "template --set password=hunter2 --password hunter2 --username admin --values testdata/testcharts/empty/values.yaml --namespace test --version 0.1.0 --devel foo testdata/testcharts/empty")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "[--devel=true --namespace=test --password=<redacted> --set=<redacted> --username=<redacted> --values=<redacted> --version=0.1.0]"
+	if flags := fmt.Sprint(auditFlags(cmd.Flags())); flags != expected {
+		t.Errorf("expected %s, got %s", expected, flags)
+	}
+}
@@ -0,0 +1,94 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+
+	"helm.sh/helm/v3/cmd/helm/require"
+	"helm.sh/helm/v3/internal/completion"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/cli/output"
+)
+
+var getOutputsHelp = `
+This command shows the outputs of a named release.
+
+Outputs are structured data published by a chart through its
+templates/OUTPUTS.yaml template, for example the host of a service or the name
+of a generated secret. Use '--output json' to consume them from other tools.
+`
+
+type outputsWriter struct {
+	outputs map[string]interface{}
+}
+
+func newGetOutputsCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
+	var outfmt output.Format
+	client := action.NewGet(cfg)
+
+	cmd := &cobra.Command{
+		Use:   "outputs RELEASE_NAME",
+		Short: "download the outputs for a named release",
+		Long:  getOutputsHelp,
+		Args:  require.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			res, err := client.Run(args[0])
+			if err != nil {
+				return err
+			}
+			return outfmt.Write(out, &outputsWriter{res.Info.Outputs})
+		},
+	}
+
+	// Function providing dynamic auto-completion
+	completion.RegisterValidArgsFunc(cmd, func(cmd *cobra.Command, args []string, toComplete string) ([]string, completion.BashCompDirective) {
+		if len(args) != 0 {
+			return nil, completion.BashCompDirectiveNoFileComp
+		}
+		return compListReleases(toComplete, cfg)
+	})
+
+	f := cmd.Flags()
+	f.IntVar(&client.Version, "revision", 0, "get the named release with revision")
+	flag := f.Lookup("revision")
+	completion.RegisterFlagCompletionFunc(flag, func(cmd *cobra.Command, args []string, toComplete string) ([]string, completion.BashCompDirective) {
+		if len(args) == 1 {
+			return compListRevisions(cfg, args[0])
+		}
+		return nil, completion.BashCompDirectiveNoFileComp
+	})
+	bindOutputFlag(cmd, &outfmt)
+
+	return cmd
+}
+
+func (o outputsWriter) WriteTable(out io.Writer) error {
+	fmt.Fprintln(out, "OUTPUTS:")
+	return output.EncodeYAML(out, o.outputs)
+}
+
+func (o outputsWriter) WriteJSON(out io.Writer) error {
+	return output.EncodeJSON(out, o.outputs)
+}
+
+func (o outputsWriter) WriteYAML(out io.Writer) error {
+	return output.EncodeYAML(out, o.outputs)
+}
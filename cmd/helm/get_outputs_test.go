@@ -0,0 +1,59 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"testing"
+
+	"helm.sh/helm/v3/pkg/release"
+)
+
+func TestGetOutputsCmd(t *testing.T) {
+	rel := release.Mock(&release.MockReleaseOptions{Name: "postgres-prod"})
+	rel.Info.Outputs = map[string]interface{}{
+		"host":       "postgres-prod.default.svc",
+		"port":       5432,
+		"secretName": "postgres-prod-credentials",
+	}
+
+	tests := []cmdTestCase{{
+		name:   "get outputs with a release",
+		cmd:    "get outputs postgres-prod",
+		golden: "output/get-outputs.txt",
+		rels:   []*release.Release{rel},
+	}, {
+		name:   "get outputs to json",
+		cmd:    "get outputs postgres-prod --output json",
+		golden: "output/get-outputs.json",
+		rels:   []*release.Release{rel},
+	}, {
+		name:      "get outputs requires release name arg",
+		cmd:       "get outputs",
+		golden:    "output/get-outputs-no-args.txt",
+		rels:      []*release.Release{rel},
+		wantError: true,
+	}}
+	runTestCmd(t, tests)
+}
+
+func TestGetOutputsRevisionCompletion(t *testing.T) {
+	revisionFlagCompletionTest(t, "get outputs")
+}
+
+func TestGetOutputsOutputCompletion(t *testing.T) {
+	outputFlagCompletionTest(t, "get outputs")
+}
@@ -17,19 +17,30 @@ limitations under the License.
 package main
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
+	"strings"
 
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
+	"sigs.k8s.io/yaml"
 
 	"helm.sh/helm/v3/cmd/helm/require"
 	"helm.sh/helm/v3/internal/completion"
 	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/chartutil"
 	"helm.sh/helm/v3/pkg/cli/output"
 )
 
 var getValuesHelp = `
 This command downloads a values file for a given release.
+
+With '--all --explain', every computed value is annotated with its origin: the
+values supplied by the user, the values.yaml file of the chart or of one of its
+subcharts, or a global value of a parent chart. Values which override others
+list the sources they override, and keys removed by setting them to null are
+shown as comments.
 `
 
 type valuesWriter struct {
@@ -37,8 +48,13 @@ type valuesWriter struct {
 	allValues bool
 }
 
+type valueOriginsWriter struct {
+	origins []chartutil.ValueOrigin
+}
+
 func newGetValuesCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 	var outfmt output.Format
+	var explain bool
 	client := action.NewGetValues(cfg)
 
 	cmd := &cobra.Command{
@@ -47,6 +63,16 @@ func newGetValuesCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 		Long:  getValuesHelp,
 		Args:  require.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if explain {
+				if !client.AllValues {
+					return errors.New("--explain requires --all")
+				}
+				origins, err := client.Explain(args[0])
+				if err != nil {
+					return err
+				}
+				return outfmt.Write(out, &valueOriginsWriter{origins})
+			}
 			vals, err := client.Run(args[0])
 			if err != nil {
 				return err
@@ -73,6 +99,7 @@ func newGetValuesCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 		return nil, completion.BashCompDirectiveNoFileComp
 	})
 	f.BoolVarP(&client.AllValues, "all", "a", false, "dump all (computed) values")
+	f.BoolVar(&explain, "explain", false, "annotate each computed value with its origin. Requires --all")
 	bindOutputFlag(cmd, &outfmt)
 
 	return cmd
@@ -94,3 +121,72 @@ func (v valuesWriter) WriteJSON(out io.Writer) error {
 func (v valuesWriter) WriteYAML(out io.Writer) error {
 	return output.EncodeYAML(out, v.vals)
 }
+
+func (v valueOriginsWriter) WriteTable(out io.Writer) error {
+	fmt.Fprintln(out, "COMPUTED VALUES:")
+	return writeValueOrigins(out, v.origins)
+}
+
+func (v valueOriginsWriter) WriteJSON(out io.Writer) error {
+	return output.EncodeJSON(out, v.origins)
+}
+
+func (v valueOriginsWriter) WriteYAML(out io.Writer) error {
+	return output.EncodeYAML(out, v.origins)
+}
+
+// writeValueOrigins prints the values as YAML, with the origin of each value in
+// a trailing comment. Removed keys are printed as comments. The origins must be
+// sorted by path, as returned by chartutil.ExplainValues.
+func writeValueOrigins(out io.Writer, origins []chartutil.ValueOrigin) error {
+	var prev []string
+	for _, o := range origins {
+		parents := o.Path[:len(o.Path)-1]
+
+		// Open the maps this value is nested in, unless the previous value
+		// already did.
+		common := 0
+		for common < len(parents) && common < len(prev)-1 && parents[common] == prev[common] {
+			common++
+		}
+		for i := common; i < len(parents); i++ {
+			fmt.Fprintf(out, "%s%s:\n", strings.Repeat("  ", i), explainedScalar(parents[i]))
+		}
+		prev = o.Path
+
+		indent := strings.Repeat("  ", len(parents))
+		key := explainedScalar(o.Path[len(o.Path)-1])
+		if o.Removed {
+			fmt.Fprintf(out, "%s# %s: null  # removed by %s", indent, key, o.Origin)
+			if len(o.Overrides) > 0 {
+				fmt.Fprintf(out, " (was %s)", strings.Join(o.Overrides, ", "))
+			}
+			fmt.Fprintln(out)
+			continue
+		}
+
+		fmt.Fprintf(out, "%s%s: %s", indent, key, explainedScalar(o.Value))
+		if o.Origin != "" {
+			fmt.Fprintf(out, "  # %s", o.Origin)
+			if len(o.Overrides) > 0 {
+				fmt.Fprintf(out, " (overrides %s)", strings.Join(o.Overrides, ", "))
+			}
+		}
+		fmt.Fprintln(out)
+	}
+	return nil
+}
+
+// explainedScalar formats a value to fit on a single line of YAML.
+func explainedScalar(v interface{}) string {
+	if b, err := yaml.Marshal(v); err == nil {
+		if s := strings.TrimSuffix(string(b), "\n"); !strings.Contains(s, "\n") {
+			return s
+		}
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(b)
+}
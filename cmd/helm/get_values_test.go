@@ -39,6 +39,17 @@ func TestGetValuesCmd(t *testing.T) {
 		cmd:    "get values thomas-guide --all",
 		golden: "output/get-values-all.txt",
 		rels:   []*release.Release{release.Mock(&release.MockReleaseOptions{Name: "thomas-guide"})},
+	}, {
+		name:   "get values thomas-guide (explain)",
+		cmd:    "get values thomas-guide --all --explain",
+		golden: "output/get-values-explain.txt",
+		rels:   []*release.Release{release.Mock(&release.MockReleaseOptions{Name: "thomas-guide"})},
+	}, {
+		name:      "get values explain requires --all",
+		cmd:       "get values thomas-guide --explain",
+		golden:    "output/get-values-explain-all.txt",
+		rels:      []*release.Release{release.Mock(&release.MockReleaseOptions{Name: "thomas-guide"})},
+		wantError: true,
 	}, {
 		name:   "get values to json",
 		cmd:    "get values thomas-guide --output json",
@@ -41,8 +41,13 @@ import (
 
 func testTimestamper() time.Time { return time.Unix(242085845, 0).UTC() }
 
+// testAuditor disables the audit trail so that golden files do not depend on
+// the user and host running the tests.
+func testAuditor(_ *action.Configuration) *release.Audit { return nil }
+
 func init() {
 	action.Timestamper = testTimestamper
+	action.Auditor = testAuditor
 }
 
 func runTestCmd(t *testing.T, tests []cmdTestCase) {
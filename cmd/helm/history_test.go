@@ -66,10 +66,28 @@ func TestHistoryCmd(t *testing.T) {
 			mk("angry-bird", 3, release.StatusSuperseded),
 		},
 		golden: "output/history.json",
+	}, {
+		name: "get history with audit trail",
+		cmd:  "history angry-bird --output yaml",
+		rels: []*release.Release{
+			audited(mk("angry-bird", 2, release.StatusDeployed)),
+			mk("angry-bird", 1, release.StatusSuperseded),
+		},
+		golden: "output/history-audit.yaml",
 	}}
 	runTestCmd(t, tests)
 }
 
+func audited(rel *release.Release) *release.Release {
+	rel.Info.Audit = &release.Audit{
+		User:        "system:serviceaccount:ci:deployer",
+		HelmVersion: "v3.2",
+		Hostname:    "ci-runner",
+		Flags:       []string{"--set=<redacted>", "--wait=true"},
+	}
+	return rel
+}
+
 func TestHistoryOutputCompletion(t *testing.T) {
 	outputFlagCompletionTest(t, "history")
 }
@@ -19,6 +19,7 @@ package main
 import (
 	"fmt"
 	"io"
+	"os"
 	"time"
 
 	"github.com/pkg/errors"
@@ -63,21 +64,58 @@ or
 
     $ helm install --set-file my_script=dothings.sh myredis ./redis
 
+or
+
+    $ helm install --set-json 'master.sidecars=[{"name":"sidecar","image":"myImage"}]' myredis ./redis
+
+Keys given to '--set' and related flags may also be JSON pointers (RFC 6901)
+prefixed with 'pointer:', which allow keys containing dots. A '/' within a key
+is escaped as '~1'. Without the prefix, a key starting with '/' is a plain key:
+
+    $ helm install --set 'pointer:/podAnnotations/prometheus.io~1scrape=true' myredis ./redis
+
 You can specify the '--values'/'-f' flag multiple times. The priority will be given to the
 last (right-most) file specified. For example, if both myvalues.yaml and override.yaml
 contained a key called 'Test', the value set in override.yaml would take precedence:
 
     $ helm install -f myvalues.yaml -f override.yaml  myredis ./redis
 
+To remove keys or change lists of the values files, use '--values-patch' with
+either an RFC 6902 JSON Patch, a list of operations, or an RFC 7386 JSON Merge
+Patch, a map in which null removes a key. Patches are applied in order after all
+values files have been merged. A removed key is set to null, which also removes
+the default of the chart, and a key only the chart sets can be replaced:
+
+    $ cat patch.yaml
+    - op: remove
+      path: /ingress/annotations/kubernetes.io~1ingress.class
+    - op: add
+      path: /sidecars/1
+      value: {name: proxy, image: envoy}
+    $ helm install -f myvalues.yaml --values-patch patch.yaml myredis ./redis
+
 You can specify the '--set' flag multiple times. The priority will be given to the
 last (right-most) set specified. For example, if both 'bar' and 'newbar' values are
 set for a key called 'foo', the 'newbar' value would take precedence:
 
     $ helm install --set foo=bar --set foo=newbar  myredis ./redis
 
+To reuse data of another release, use '--set-from-release' and reference either
+the computed values or the outputs of its last deployed revision. Releases in
+other namespaces must be prefixed with their namespace. The command fails if the
+referenced release has no deployed revision:
+
+    $ helm install --set-from-release db.port=postgres-prod:.Values.service.port myapp ./app
+    $ helm install --set-from-release db.host=data/postgres-prod:.Outputs.host myapp ./app
+
 
 To check the generated manifests of a release without installing the chart,
-the '--debug' and '--dry-run' flags can be combined.
+the '--debug' and '--dry-run' flags can be combined. A dry run does not query
+the cluster, so the 'lookup' template function returns empty objects, unless
+objects are given with '--lookup-fixtures':
+
+    $ kubectl get secret myredis -o yaml > objects.yaml
+    $ helm install --dry-run --lookup-fixtures objects.yaml myredis ./redis
 
 If --verify is set, the chart MUST have a provenance file, and the provenance
 file MUST pass all verification steps.
@@ -100,6 +138,10 @@ chart in that repository whose name is 'mariadb'. It will install the latest sta
 until you specify '--devel' flag to also include development version (alpha, beta, and release candidate releases), or
 supply a version number with the '--version' flag.
 
+The install prints a warning for each rendered object using a Kubernetes API
+which the cluster, or its next minor version, no longer serves. The built-in
+table of deprecated APIs can be overridden with '--deprecations-file'.
+
 To see the list of chart repositories, use 'helm repo list'. To search for
 charts in a repository, use 'helm search'.
 `
@@ -115,6 +157,7 @@ func newInstallCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 		Long:  installDesc,
 		Args:  require.MinimumNArgs(1),
 		RunE: func(_ *cobra.Command, args []string) error {
+			client.Warnings = os.Stderr
 			rel, err := runInstall(args, client, valueOpts, out)
 			if err != nil {
 				return err
@@ -130,8 +173,12 @@ func newInstallCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 	})
 
 	addInstallFlags(cmd.Flags(), client, valueOpts)
+	addReleaseValuesFlags(cmd.Flags(), valueOpts, cfg)
 	bindOutputFlag(cmd, &outfmt)
 	bindPostRenderFlag(cmd, &client.PostRenderer)
+	bindLookupFixturesFlag(cmd, &client.LookupFixtures)
+	bindCapabilitiesFileFlag(cmd, &client.Capabilities)
+	bindDeprecatedAPIsFileFlag(cmd, &client.DeprecatedAPIs)
 
 	return cmd
 }
@@ -149,9 +196,11 @@ func addInstallFlags(f *pflag.FlagSet, client *action.Install, valueOpts *values
 	f.BoolVar(&client.Devel, "devel", false, "use development versions, too. Equivalent to version '>0.0.0-0'. If --version is set, this is ignored")
 	f.BoolVar(&client.DependencyUpdate, "dependency-update", false, "run helm dependency update before installing the chart")
 	f.BoolVar(&client.DisableOpenAPIValidation, "disable-openapi-validation", false, "if set, the installation process will not validate rendered templates against the Kubernetes OpenAPI Schema")
+	f.BoolVar(&client.SchemaDefaults, "schema-defaults", false, "apply the defaults of the values schemas of the chart to missing values")
 	f.BoolVar(&client.Atomic, "atomic", false, "if set, the installation process deletes the installation on failure. The --wait flag will be set automatically if --atomic is used")
 	f.BoolVar(&client.SkipCRDs, "skip-crds", false, "if set, no CRDs will be installed. By default, CRDs are installed if not already present")
 	f.BoolVar(&client.SubNotes, "render-subchart-notes", false, "if set, render subchart notes along with the parent")
+	addStrictValuesFlags(f, &client.StrictValues, &client.OptionalValues)
 	addValueOptionsFlags(f, valueOpts)
 	addChartPathOptionsFlags(f, &client.ChartPathOptions)
 }
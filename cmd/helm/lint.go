@@ -23,12 +23,18 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/gosuri/uitable"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 
 	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/cli/output"
 	"helm.sh/helm/v3/pkg/cli/values"
 	"helm.sh/helm/v3/pkg/getter"
+	"helm.sh/helm/v3/pkg/lint"
+	"helm.sh/helm/v3/pkg/lint/rules"
+	"helm.sh/helm/v3/pkg/lint/support"
 )
 
 var longLintHelp = `
@@ -38,17 +44,62 @@ the chart is well-formed.
 If the linter encounters things that will cause the chart to fail installation,
 it will emit [ERROR] messages. If it encounters issues that break with convention
 or recommendation, it will emit [WARNING] messages.
+
+The chart is rendered reproducibly, the way 'helm install' renders it, and its
+dependencies, hooks and CRDs are checked as they would be installed. Each rule
+has a stable ID, printed with its messages; use '--list-rules' to list them.
+
+A '.helmlint.yaml' file in a chart, or a file given with '--config', enables or
+disables rules, or changes their severity, by ID or name:
+
+    rules:
+      chart-sources:
+        enabled: false
+
+A 'helm-lint-disable' comment in a file disables rules for that file only.
+
+Use '--kube-version' to report the APIs the chart uses that are deprecated or
+removed in that Kubernetes version, '--capabilities-file' and
+'--lookup-fixtures' to render it as it would be for a cluster, and
+'--values-matrix' with '--kube-versions' to lint every combination of values
+files and Kubernetes versions:
+
+    $ helm lint --values-matrix 'ci/*-values.yaml' --kube-versions 1.17,1.18 mychart
+
+'--strict-values', '--values-usage' and '--best-practices' enable further rules
+for the values and workloads of the chart, and '--plugins' runs the linters of
+the named plugins.
+
+Use '--output' to print the messages as json, yaml, sarif or junit.
 `
 
 func newLintCmd(out io.Writer) *cobra.Command {
 	client := action.NewLint()
 	valueOpts := &values.Options{}
+	var listRules bool
+	var plugins []string
+	config := &lintConfigFile{config: &client.Config}
+	var kubeVersion string
+	var outfmt output.Format
 
 	cmd := &cobra.Command{
 		Use:   "lint PATH",
 		Short: "examine a chart for possible issues",
 		Long:  longLintHelp,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			checkers, err := lint.PluginCheckers(settings, plugins, func(format string, v ...interface{}) {
+				fmt.Fprintf(os.Stderr, "WARNING: "+format+"\n", v...)
+			})
+			if err != nil {
+				return err
+			}
+			if err := config.resolve(lintRules(checkers), checkers); err != nil {
+				return err
+			}
+			if listRules {
+				return printLintRules(out, lintRules(checkers))
+			}
+			client.Checkers = checkers
 			paths := []string{"."}
 			if len(args) > 0 {
 				paths = args
@@ -58,61 +109,45 @@ func newLintCmd(out io.Writer) *cobra.Command {
 					filepath.Walk(filepath.Join(p, "charts"), func(path string, info os.FileInfo, err error) error {
 						if info != nil {
 							if info.Name() == "Chart.yaml" {
-								paths = append(paths, filepath.Dir(path))
+								client.Subcharts = append(client.Subcharts, filepath.Dir(path))
 							} else if strings.HasSuffix(path, ".tgz") || strings.HasSuffix(path, ".tar.gz") {
-								paths = append(paths, path)
+								client.Subcharts = append(client.Subcharts, path)
 							}
 						}
 						return nil
 					})
 				}
+				paths = append(paths, client.Subcharts...)
 			}
 
 			client.Namespace = settings.Namespace()
+			if kubeVersion != "" {
+				v, err := chartutil.ParseKubeVersion(kubeVersion)
+				if err != nil {
+					return err
+				}
+				caps := *chartutil.DefaultCapabilities
+				if client.Capabilities != nil {
+					caps = *client.Capabilities
+				}
+				caps.KubeVersion = v
+				client.Capabilities = &caps
+			}
 			vals, err := valueOpts.MergeValues(getter.All(settings))
 			if err != nil {
 				return err
 			}
 
-			var message strings.Builder
-			failed := 0
-
+			w := &lintWriter{failureSeverity: client.FailureSeverity(), rules: lintRules(checkers)}
 			for _, path := range paths {
-				fmt.Fprintf(&message, "==> Linting %s\n", path)
-
-				result := client.Run([]string{path}, vals)
-
-				// All the Errors that are generated by a chart
-				// that failed a lint will be included in the
-				// results.Messages so we only need to print
-				// the Errors if there are no Messages.
-				if len(result.Messages) == 0 {
-					for _, err := range result.Errors {
-						fmt.Fprintf(&message, "Error %s\n", err)
-					}
-				}
-
-				for _, msg := range result.Messages {
-					fmt.Fprintf(&message, "%s\n", msg)
-				}
-
-				if len(result.Errors) != 0 {
-					failed++
-				}
-
-				// Adding extra new line here to break up the
-				// results, stops this from being a big wall of
-				// text and makes it easier to follow.
-				fmt.Fprint(&message, "\n")
+				w.charts = append(w.charts, lintChartResult{path: path, result: client.Run([]string{path}, vals)})
 			}
-
-			fmt.Fprint(out, message.String())
-
-			summary := fmt.Sprintf("%d chart(s) linted, %d chart(s) failed", len(paths), failed)
-			if failed > 0 {
-				return errors.New(summary)
+			if err := outfmt.Write(out, w); err != nil {
+				return err
+			}
+			if w.failed() > 0 {
+				return errors.New(w.summary())
 			}
-			fmt.Fprintln(out, summary)
 			return nil
 		},
 	}
@@ -121,6 +156,38 @@ func newLintCmd(out io.Writer) *cobra.Command {
 	f.BoolVar(&client.Strict, "strict", false, "fail on lint warnings")
 	f.BoolVar(&client.WithSubcharts, "with-subcharts", false, "lint dependent charts")
 	addValueOptionsFlags(f, valueOpts)
+	addStrictValuesFlags(f, &client.StrictValues, &client.OptionalValues)
+	bindCapabilitiesFileFlag(cmd, &client.Capabilities)
+	f.StringVar(&kubeVersion, "kube-version", "", "the Kubernetes version used for rendering and checked for deprecated and removed APIs, e.g. '1.22'")
+	bindDeprecatedAPIsFileFlag(cmd, &client.DeprecatedAPIs)
+	bindLookupFixturesFlag(cmd, &client.LookupFixtures)
+	f.StringSliceVar(&client.ValuesMatrix, "values-matrix", []string{}, "lint with each values file matching the patterns, relative to the charts, e.g. 'ci/*-values.yaml' (can specify multiple)")
+	f.StringSliceVar(&client.KubeVersions, "kube-versions", []string{}, "lint with the capabilities of each Kubernetes version, e.g. '1.16,1.17,1.18'")
+	cmd.Flags().Var(config, "config", "a lint configuration file enabling, disabling or changing the severity of rules, overriding the "+support.ConfigFileName+" file of the charts")
+	f.StringSliceVar(&plugins, "plugins", []string{}, "run the lint rules of the given installed plugins, whose linters are otherwise not run")
+	f.BoolVar(&client.BestPractices, "best-practices", false, "enable the best-practice rules checking the rendered workloads")
+	f.BoolVar(&client.ValuesUsage, "values-usage", false, "report the values which the templates do not read, and the values read by the templates which values.yaml and values.schema.json do not define")
+	f.BoolVar(&listRules, "list-rules", false, "list the lint rules and exit")
+	bindReportOutputFlag(cmd, &outfmt)
 
 	return cmd
 }
+
+// lintRules returns the rules of Helm and those of the checkers of the lint
+// plugins.
+func lintRules(checkers []support.Checker) []support.Rule {
+	all := rules.Rules()
+	for _, c := range checkers {
+		all = append(all, c.Rules()...)
+	}
+	return all
+}
+
+func printLintRules(out io.Writer, all []support.Rule) error {
+	table := uitable.New()
+	table.AddRow("ID", "NAME", "SEVERITY", "ENABLED", "DESCRIPTION")
+	for _, r := range all {
+		table.AddRow(r.ID, r.Name, support.SeverityName(r.Severity), !r.Disabled, r.Description)
+	}
+	return output.EncodeTable(out, table)
+}
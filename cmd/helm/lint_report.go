@@ -0,0 +1,395 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"helm.sh/helm/v3/internal/version"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/cli/output"
+	"helm.sh/helm/v3/pkg/lint/support"
+)
+
+// lintChartResult is the result of linting a chart.
+type lintChartResult struct {
+	path   string
+	result *action.LintResult
+}
+
+func (r lintChartResult) failed() bool {
+	return len(r.result.Errors) != 0
+}
+
+// loadErrors returns the errors of a chart which could not be linted, and
+// so has no messages.
+func (r lintChartResult) loadErrors() []error {
+	if len(r.result.Messages) == 0 {
+		return r.result.Errors
+	}
+	return nil
+}
+
+type lintWriter struct {
+	charts []lintChartResult
+	// failureSeverity is the lowest severity of the messages failing a chart.
+	failureSeverity int
+	// rules are the rules the charts are linted with.
+	rules []support.Rule
+}
+
+func (w *lintWriter) failed() int {
+	failed := 0
+	for _, c := range w.charts {
+		if c.failed() {
+			failed++
+		}
+	}
+	return failed
+}
+
+func (w *lintWriter) summary() string {
+	return fmt.Sprintf("%d chart(s) linted, %d chart(s) failed", len(w.charts), w.failed())
+}
+
+func (w *lintWriter) WriteTable(out io.Writer) error {
+	var message strings.Builder
+	for _, c := range w.charts {
+		fmt.Fprintf(&message, "==> Linting %s\n", c.path)
+
+		// All the Errors that are generated by a chart
+		// that failed a lint will be included in the
+		// results.Messages so we only need to print
+		// the Errors if there are no Messages.
+		for _, err := range c.loadErrors() {
+			fmt.Fprintf(&message, "Error %s\n", err)
+		}
+
+		for _, msg := range c.result.Messages {
+			fmt.Fprintf(&message, "%s\n", msg)
+		}
+
+		// Adding extra new line here to break up the
+		// results, stops this from being a big wall of
+		// text and makes it easier to follow.
+		fmt.Fprint(&message, "\n")
+	}
+	if w.failed() == 0 {
+		fmt.Fprintln(&message, w.summary())
+	}
+	_, err := io.WriteString(out, message.String())
+	return err
+}
+
+// lintReport is the report of 'helm lint' written as JSON or YAML.
+type lintReport struct {
+	Charts []lintChartReport `json:"charts"`
+	Linted int               `json:"linted"`
+	Failed int               `json:"failed"`
+}
+
+type lintChartReport struct {
+	Path     string        `json:"path"`
+	Failed   bool          `json:"failed"`
+	Messages []lintMessage `json:"messages"`
+	// Errors are the errors of a chart which could not be linted.
+	Errors []string `json:"errors,omitempty"`
+}
+
+type lintMessage struct {
+	RuleID   string `json:"ruleID,omitempty"`
+	Severity string `json:"severity"`
+	Chart    string `json:"chart"`
+	File     string `json:"file"`
+	Line     int    `json:"line,omitempty"`
+	Column   int    `json:"column,omitempty"`
+	Message  string `json:"message"`
+	// Contexts are the combinations of values files and Kubernetes versions
+	// producing the message, when only some of them do.
+	Contexts []string `json:"contexts,omitempty"`
+}
+
+func newLintMessage(chart string, m support.Message) lintMessage {
+	file, line, column := m.Location()
+	return lintMessage{
+		RuleID:   m.RuleID,
+		Severity: strings.ToLower(support.SeverityName(m.Severity)),
+		Chart:    chart,
+		File:     file,
+		Line:     line,
+		Column:   column,
+		Message:  m.Err.Error(),
+		Contexts: m.Contexts,
+	}
+}
+
+// text returns the message with the combinations producing it.
+func (m lintMessage) text() string {
+	if len(m.Contexts) == 0 {
+		return m.Message
+	}
+	return fmt.Sprintf("%s (in %s)", m.Message, strings.Join(m.Contexts, "; "))
+}
+
+func (w *lintWriter) report() lintReport {
+	report := lintReport{Charts: []lintChartReport{}, Linted: len(w.charts), Failed: w.failed()}
+	for _, c := range w.charts {
+		cr := lintChartReport{Path: c.path, Failed: c.failed(), Messages: []lintMessage{}}
+		for _, m := range c.result.Messages {
+			cr.Messages = append(cr.Messages, newLintMessage(c.path, m))
+		}
+		for _, err := range c.loadErrors() {
+			cr.Errors = append(cr.Errors, err.Error())
+		}
+		report.Charts = append(report.Charts, cr)
+	}
+	return report
+}
+
+func (w *lintWriter) WriteJSON(out io.Writer) error {
+	return output.EncodeJSON(out, w.report())
+}
+
+func (w *lintWriter) WriteYAML(out io.Writer) error {
+	return output.EncodeYAML(out, w.report())
+}
+
+// The SARIF 2.1.0 log written for code scanning tools.
+//
+// See https://docs.oasis-open.org/sarif/sarif/v2.1.0/sarif-v2.1.0.html
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	Version        string      `json:"version"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID                   string                 `json:"id"`
+	Name                 string                 `json:"name"`
+	ShortDescription     sarifText              `json:"shortDescription"`
+	DefaultConfiguration sarifRuleConfiguration `json:"defaultConfiguration"`
+}
+
+type sarifRuleConfiguration struct {
+	Level   string `json:"level"`
+	Enabled bool   `json:"enabled"`
+}
+
+type sarifText struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId,omitempty"`
+	RuleIndex *int            `json:"ruleIndex,omitempty"`
+	Level     string          `json:"level"`
+	Message   sarifText       `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn,omitempty"`
+}
+
+// sarifLevel returns the SARIF level of a severity.
+func sarifLevel(severity int) string {
+	switch severity {
+	case support.ErrorSev:
+		return "error"
+	case support.WarningSev:
+		return "warning"
+	}
+	return "note"
+}
+
+// sarifLocationOf returns the location of a file of a chart. Code scanning
+// tools map the URIs to the files of the repository they scan, so they are
+// relative to the working directory, from which helm lint is run.
+func sarifLocationOf(chart, file string, line, column int) sarifLocation {
+	if filepath.IsAbs(chart) {
+		if wd, err := os.Getwd(); err == nil {
+			if rel, err := filepath.Rel(wd, chart); err == nil {
+				chart = rel
+			}
+		}
+	}
+	loc := sarifLocation{PhysicalLocation: sarifPhysicalLocation{
+		ArtifactLocation: sarifArtifactLocation{URI: path.Join(filepath.ToSlash(chart), file)},
+	}}
+	if line > 0 {
+		loc.PhysicalLocation.Region = &sarifRegion{StartLine: line, StartColumn: column}
+	}
+	return loc
+}
+
+func (w *lintWriter) WriteSARIF(out io.Writer) error {
+	driver := sarifDriver{
+		Name:           "helm-lint",
+		Version:        version.GetVersion(),
+		InformationURI: "https://helm.sh/docs/helm/helm_lint/",
+		Rules:          []sarifRule{},
+	}
+	ruleIndex := map[string]int{}
+	for i, r := range w.rules {
+		ruleIndex[r.ID] = i
+		driver.Rules = append(driver.Rules, sarifRule{
+			ID:                   r.ID,
+			Name:                 r.Name,
+			ShortDescription:     sarifText{Text: r.Description},
+			DefaultConfiguration: sarifRuleConfiguration{Level: sarifLevel(r.Severity), Enabled: !r.Disabled},
+		})
+	}
+
+	results := []sarifResult{}
+	for _, c := range w.charts {
+		for _, m := range c.result.Messages {
+			file, line, column := m.Location()
+			result := sarifResult{
+				RuleID:    m.RuleID,
+				Level:     sarifLevel(m.Severity),
+				Message:   sarifText{Text: newLintMessage(c.path, m).text()},
+				Locations: []sarifLocation{sarifLocationOf(c.path, file, line, column)},
+			}
+			if i, ok := ruleIndex[m.RuleID]; ok {
+				result.RuleIndex = &i
+			}
+			results = append(results, result)
+		}
+		for _, err := range c.loadErrors() {
+			results = append(results, sarifResult{
+				Level:     "error",
+				Message:   sarifText{Text: err.Error()},
+				Locations: []sarifLocation{sarifLocationOf(c.path, "", 0, 0)},
+			})
+		}
+	}
+
+	return output.EncodeJSON(out, sarifLog{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs:    []sarifRun{{Tool: sarifTool{Driver: driver}, Results: results}},
+	})
+}
+
+// The JUnit XML report written for CI tools, with a test suite for each chart
+// and a test case for each message.
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Errors   int              `xml:"errors,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Errors   int             `xml:"errors,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	Error     *junitFailure `xml:"error,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+func (w *lintWriter) WriteJUnit(out io.Writer) error {
+	suites := junitTestSuites{Name: "helm lint"}
+	for _, c := range w.charts {
+		suite := junitTestSuite{Name: c.path}
+		for _, m := range c.result.Messages {
+			name := m.Path
+			if m.RuleID != "" {
+				name = m.RuleID + " " + name
+			}
+			tc := junitTestCase{Name: name, ClassName: c.path}
+			if m.Severity >= w.failureSeverity {
+				tc.Failure = &junitFailure{Message: m.Err.Error(), Type: support.SeverityName(m.Severity), Text: m.Error()}
+				suite.Failures++
+			} else {
+				tc.SystemOut = m.Error()
+			}
+			suite.Cases = append(suite.Cases, tc)
+		}
+		for _, err := range c.loadErrors() {
+			suite.Cases = append(suite.Cases, junitTestCase{
+				Name:      "lint",
+				ClassName: c.path,
+				Error:     &junitFailure{Message: err.Error(), Type: "ERROR", Text: err.Error()},
+			})
+			suite.Errors++
+		}
+		// A chart without messages passes.
+		if len(suite.Cases) == 0 {
+			suite.Cases = append(suite.Cases, junitTestCase{Name: "lint", ClassName: c.path})
+		}
+		suite.Tests = len(suite.Cases)
+
+		suites.Tests += suite.Tests
+		suites.Failures += suite.Failures
+		suites.Errors += suite.Errors
+		suites.Suites = append(suites.Suites, suite)
+	}
+	return output.EncodeXML(out, suites)
+}
@@ -18,6 +18,8 @@ package main
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
 	"testing"
 )
 
@@ -36,3 +38,293 @@ func TestLintCmdWithSubchartsFlag(t *testing.T) {
 	}}
 	runTestCmd(t, tests)
 }
+
+func TestLintCmdWithCapabilitiesFile(t *testing.T) {
+	testChart := "testdata/testcharts/chart-with-kube-version"
+	tests := []cmdTestCase{{
+		name:   "lint chart requiring a Kubernetes version",
+		cmd:    fmt.Sprintf("lint %s", testChart),
+		golden: "output/lint-chart-with-kube-version.txt",
+	}, {
+		name:      "lint chart requiring a Kubernetes version with the capabilities of an older cluster",
+		cmd:       fmt.Sprintf("lint --capabilities-file testdata/capabilities/capabilities.yaml %s", testChart),
+		golden:    "output/lint-chart-with-kube-version-capabilities-file.txt",
+		wantError: true,
+	}}
+	runTestCmd(t, tests)
+}
+
+func TestLintCmdWithStrictValues(t *testing.T) {
+	testChart := "testdata/testcharts/strict-values"
+	tests := []cmdTestCase{{
+		name:      "lint chart referencing missing values",
+		cmd:       fmt.Sprintf("lint --strict-values %s", testChart),
+		golden:    "output/lint-strict-values.txt",
+		wantError: true,
+	}, {
+		name:   "lint chart referencing optional values",
+		cmd:    fmt.Sprintf("lint --strict-values --optional-values partOf,service.targetPort,image.tag %s", testChart),
+		golden: "output/lint-strict-values-optional.txt",
+	}}
+	runTestCmd(t, tests)
+}
+
+func TestLintCmdWithConfig(t *testing.T) {
+	testChart := "testdata/testcharts/chart-with-lint-config"
+	tests := []cmdTestCase{{
+		name:   "lint chart with a lint configuration and suppressions",
+		cmd:    fmt.Sprintf("lint %s", testChart),
+		golden: "output/lint-chart-with-lint-config.txt",
+	}, {
+		name:      "lint chart with a lint configuration overridden by --config",
+		cmd:       fmt.Sprintf("lint --config testdata/lint-config.yaml %s", testChart),
+		golden:    "output/lint-chart-with-lint-config-override.txt",
+		wantError: true,
+	}, {
+		name:      "lint chart with an unknown rule in --config",
+		cmd:       fmt.Sprintf("lint --config testdata/lint-config-invalid.yaml %s", testChart),
+		golden:    "output/lint-config-invalid.txt",
+		wantError: true,
+	}, {
+		name:   "list the lint rules",
+		cmd:    "lint --list-rules",
+		golden: "output/lint-list-rules.txt",
+	}}
+	runTestCmd(t, tests)
+}
+
+func TestLintCmdWithBestPractices(t *testing.T) {
+	testChart := "testdata/testcharts/best-practices"
+	tests := []cmdTestCase{{
+		name:   "lint chart without the best-practice rules",
+		cmd:    fmt.Sprintf("lint %s", testChart),
+		golden: "output/lint-best-practices-disabled.txt",
+	}, {
+		name:   "lint chart with the best-practice rules",
+		cmd:    fmt.Sprintf("lint --best-practices %s", testChart),
+		golden: "output/lint-best-practices.txt",
+	}, {
+		name:      "lint chart with the best-practice rules and stricter severities",
+		cmd:       fmt.Sprintf("lint --best-practices --config testdata/lint-config-best-practices.yaml %s", testChart),
+		golden:    "output/lint-best-practices-config.txt",
+		wantError: true,
+	}}
+	runTestCmd(t, tests)
+}
+
+func TestLintCmdWithOutput(t *testing.T) {
+	testCharts := "testdata/testcharts/strict-values testdata/testcharts/chart-with-lint-config"
+	tests := []cmdTestCase{{
+		name:      "lint charts with JSON output",
+		cmd:       fmt.Sprintf("lint --strict-values -o json %s", testCharts),
+		golden:    "output/lint-output-json.txt",
+		wantError: true,
+	}, {
+		name:      "lint charts with SARIF output",
+		cmd:       fmt.Sprintf("lint --strict-values -o sarif %s", testCharts),
+		golden:    "output/lint-output-sarif.txt",
+		wantError: true,
+	}, {
+		name:      "lint charts with JUnit output",
+		cmd:       fmt.Sprintf("lint --strict-values -o junit %s", testCharts),
+		golden:    "output/lint-output-junit.txt",
+		wantError: true,
+	}, {
+		name:      "lint chart failing to render with JSON output",
+		cmd:       "lint -o json testdata/testcharts/chart-with-template-with-invalid-yaml testdata/testcharts/missing",
+		golden:    "output/lint-output-json-render-error.txt",
+		wantError: true,
+	}}
+	runTestCmd(t, tests)
+}
+
+func TestLintCmdWithMatrix(t *testing.T) {
+	testChart := "testdata/testcharts/values-matrix"
+	tests := []cmdTestCase{{
+		name:      "lint chart with a matrix of values files and Kubernetes versions",
+		cmd:       fmt.Sprintf("lint --values-matrix 'ci/*-values.yaml' --kube-versions 1.12,1.13,1.16 %s", testChart),
+		golden:    "output/lint-matrix.txt",
+		wantError: true,
+	}, {
+		name:      "lint chart with a matrix of values files and Kubernetes versions with JSON output",
+		cmd:       fmt.Sprintf("lint --values-matrix 'ci/*-values.yaml' --kube-versions 1.12,1.16 -o json %s", testChart),
+		golden:    "output/lint-matrix-json.txt",
+		wantError: true,
+	}, {
+		name:   "lint chart with a matrix of Kubernetes versions",
+		cmd:    fmt.Sprintf("lint --kube-versions 1.13,1.16 %s", testChart),
+		golden: "output/lint-matrix-kube-versions.txt",
+	}, {
+		name:   "lint chart with a matrix of values files overridden by --set",
+		cmd:    fmt.Sprintf("lint --values-matrix ci/default-values.yaml,ci/ingress-values.yaml --set ingress.name=web %s", testChart),
+		golden: "output/lint-matrix-values.txt",
+	}, {
+		name:      "lint chart with an invalid Kubernetes version",
+		cmd:       fmt.Sprintf("lint --kube-versions latest %s", testChart),
+		golden:    "output/lint-matrix-invalid.txt",
+		wantError: true,
+	}, {
+		name:      "lint chart with a values files pattern matching no files",
+		cmd:       fmt.Sprintf("lint --values-matrix 'ci/*-values.yaml,ci/*-values.yml' %s", testChart),
+		golden:    "output/lint-matrix-no-files.txt",
+		wantError: true,
+	}, {
+		name:      "lint chart and subcharts with a matrix of values files the subcharts do not have",
+		cmd:       fmt.Sprintf("lint --with-subcharts --values-matrix 'ci/*-values.yaml' %s", testChart),
+		golden:    "output/lint-matrix-subcharts.txt",
+		wantError: true,
+	}}
+	runTestCmd(t, tests)
+}
+
+func TestLintCmdWithKubeVersion(t *testing.T) {
+	testChart := "testdata/testcharts/deprecated-apis"
+	tests := []cmdTestCase{{
+		name:   "lint chart with deprecated APIs",
+		cmd:    fmt.Sprintf("lint %s", testChart),
+		golden: "output/lint-deprecated-apis.txt",
+	}, {
+		name:   "lint chart with APIs deprecated by the Kubernetes version",
+		cmd:    fmt.Sprintf("lint --kube-version 1.19 %s", testChart),
+		golden: "output/lint-deprecated-apis-1.19.txt",
+	}, {
+		name:      "lint chart with APIs removed by the Kubernetes version",
+		cmd:       fmt.Sprintf("lint --kube-version 1.22 %s", testChart),
+		golden:    "output/lint-deprecated-apis-1.22.txt",
+		wantError: true,
+	}, {
+		name:   "lint chart with a deprecations file",
+		cmd:    fmt.Sprintf("lint --deprecations-file testdata/deprecations.yaml %s", testChart),
+		golden: "output/lint-deprecations-file.txt",
+	}, {
+		name:      "lint chart with a deprecations file removing an API",
+		cmd:       fmt.Sprintf("lint --deprecations-file testdata/deprecations.yaml --kube-version 1.19 %s", testChart),
+		golden:    "output/lint-deprecations-file-removed.txt",
+		wantError: true,
+	}, {
+		name:      "lint chart with an invalid deprecations file",
+		cmd:       fmt.Sprintf("lint --deprecations-file testdata/deprecations-invalid.yaml %s", testChart),
+		golden:    "output/lint-deprecations-file-invalid.txt",
+		wantError: true,
+	}, {
+		name:      "lint chart with an invalid Kubernetes version",
+		cmd:       fmt.Sprintf("lint --kube-version latest %s", testChart),
+		golden:    "output/lint-kube-version-invalid.txt",
+		wantError: true,
+	}}
+	runTestCmd(t, tests)
+}
+
+func TestLintCmdWithValuesUsage(t *testing.T) {
+	testChart := "testdata/testcharts/values-usage"
+	tests := []cmdTestCase{{
+		name:   "lint chart without values usage",
+		cmd:    fmt.Sprintf("lint %s", testChart),
+		golden: "output/lint-values-usage-disabled.txt",
+	}, {
+		name:   "lint chart with values usage",
+		cmd:    fmt.Sprintf("lint --values-usage %s", testChart),
+		golden: "output/lint-values-usage.txt",
+	}, {
+		name:      "lint chart and subcharts with values usage",
+		cmd:       fmt.Sprintf("lint --values-usage --with-subcharts --strict %s", testChart),
+		golden:    "output/lint-values-usage-subcharts.txt",
+		wantError: true,
+	}}
+	runTestCmd(t, tests)
+}
+
+func TestLintCmdWithDependencies(t *testing.T) {
+	testChart := "testdata/testcharts/chart-with-dependency-issues"
+	tests := []cmdTestCase{{
+		name:      "lint chart with dependencies inconsistent with its lock, charts and values",
+		cmd:       fmt.Sprintf("lint %s", testChart),
+		golden:    "output/lint-chart-with-dependency-issues.txt",
+		wantError: true,
+	}}
+	runTestCmd(t, tests)
+}
+
+func TestLintCmdWithInstallErrors(t *testing.T) {
+	testChart := "testdata/testcharts/chart-with-install-errors"
+	tests := []cmdTestCase{{
+		name:      "lint chart rendering, but failing to install",
+		cmd:       fmt.Sprintf("lint %s", testChart),
+		golden:    "output/lint-chart-with-install-errors.txt",
+		wantError: true,
+	}}
+	runTestCmd(t, tests)
+}
+
+func TestLintCmdWithDuplicateResources(t *testing.T) {
+	testChart := "testdata/testcharts/chart-with-duplicate-resources"
+	tests := []cmdTestCase{{
+		name:      "lint chart rendering the objects of its subchart",
+		cmd:       fmt.Sprintf("lint %s", testChart),
+		golden:    "output/lint-chart-with-duplicate-resources.txt",
+		wantError: true,
+	}}
+	runTestCmd(t, tests)
+}
+
+func TestLintCmdWithLookupFixtures(t *testing.T) {
+	testChart := "testdata/testcharts/lookup-required"
+	tests := []cmdTestCase{{
+		name:      "lint chart looking up a missing object",
+		cmd:       fmt.Sprintf("lint %s", testChart),
+		golden:    "output/lint-lookup-missing.txt",
+		wantError: true,
+	}, {
+		name:   "lint chart with lookup fixtures",
+		cmd:    fmt.Sprintf("lint %s --lookup-fixtures testdata/lookup-fixtures", testChart),
+		golden: "output/lint-lookup-fixtures.txt",
+	}}
+	runTestCmd(t, tests)
+}
+
+func TestLintCmdWithPlugins(t *testing.T) {
+	testChart := "testdata/testcharts/chart-with-policy-violations"
+	tests := []cmdTestCase{{
+		name:      "lint chart with the rules of a lint plugin",
+		cmd:       fmt.Sprintf("lint --plugins policy %s", testChart),
+		golden:    "output/lint-plugin.txt",
+		wantError: true,
+	}, {
+		name:   "lint chart without running the installed lint plugins",
+		cmd:    fmt.Sprintf("lint %s", testChart),
+		golden: "output/lint-plugin-not-run.txt",
+	}, {
+		name:   "lint chart with the rules of a lint plugin configured by --config",
+		cmd:    fmt.Sprintf("lint --plugins policy --config testdata/lint-config-plugin.yaml %s", testChart),
+		golden: "output/lint-plugin-config.txt",
+	}, {
+		name:      "lint chart with --config configuring the rules of a lint plugin which is not run",
+		cmd:       fmt.Sprintf("lint --config testdata/lint-config-plugin.yaml %s", testChart),
+		golden:    "output/lint-plugin-config-not-run.txt",
+		wantError: true,
+	}, {
+		name:      "lint chart with a lint plugin which is not installed",
+		cmd:       fmt.Sprintf("lint --plugins missing %s", testChart),
+		golden:    "output/lint-plugin-missing.txt",
+		wantError: true,
+	}, {
+		name:   "list the lint rules with those of a lint plugin",
+		cmd:    "lint --plugins policy --list-rules",
+		golden: "output/lint-list-rules-plugin.txt",
+	}}
+	for _, test := range tests {
+		settings.PluginsDirectory = "testdata/lint-plugins"
+		runTestCmd(t, []cmdTestCase{test})
+	}
+}
+
+func TestSARIFLocationOfAbsoluteChart(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	loc := sarifLocationOf(filepath.Join(wd, "testdata", "testcharts", "alpine"), "templates/alpine-pod.yaml", 3, 0)
+	if uri := loc.PhysicalLocation.ArtifactLocation.URI; uri != "testdata/testcharts/alpine/templates/alpine-pod.yaml" {
+		t.Errorf("expected a URI relative to the working directory, got %q", uri)
+	}
+}
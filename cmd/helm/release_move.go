@@ -0,0 +1,94 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"helm.sh/helm/v3/cmd/helm/require"
+	"helm.sh/helm/v3/internal/completion"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/kube"
+)
+
+const releaseMoveDesc = `
+This command moves a release to another namespace.
+
+The release history is written to the target namespace, using the storage
+driver given by '--to-driver', and removed from the current one. Namespaced
+resources are recreated in the target namespace and deleted from the current
+one. Use '--keep-resources' to leave them where they are and only transfer
+their ownership to the moved release. A release whose chart sets the namespace
+of its resources to another namespace can only be moved with '--keep-resources'.
+`
+
+func newReleaseMoveCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
+	client := action.NewReleaseMove(cfg)
+	var targetDriver string
+
+	cmd := &cobra.Command{
+		Use:   "move RELEASE_NAME --to-namespace NAMESPACE",
+		Short: "move a release to another namespace",
+		Long:  releaseMoveDesc,
+		Args:  require.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if client.Namespace == "" {
+				return fmt.Errorf("a target namespace must be given with --to-namespace")
+			}
+
+			target := new(action.Configuration)
+			if err := target.Init(settings.RESTClientGetter(), client.Namespace, targetDriver, debug); err != nil {
+				return err
+			}
+			if kc, ok := target.KubeClient.(*kube.Client); ok {
+				kc.Namespace = client.Namespace
+			}
+			client.Target = target
+
+			if _, err := client.Run(args[0]); err != nil {
+				return err
+			}
+
+			if client.DryRun {
+				fmt.Fprintf(out, "release %q would be moved to namespace %q\n", args[0], client.Namespace)
+				return nil
+			}
+			fmt.Fprintf(out, "release %q moved to namespace %q\n", args[0], client.Namespace)
+			return nil
+		},
+	}
+
+	// Function providing dynamic auto-completion
+	completion.RegisterValidArgsFunc(cmd, func(cmd *cobra.Command, args []string, toComplete string) ([]string, completion.BashCompDirective) {
+		if len(args) != 0 {
+			return nil, completion.BashCompDirectiveNoFileComp
+		}
+		return compListReleases(toComplete, cfg)
+	})
+
+	f := cmd.Flags()
+	f.StringVar(&client.Namespace, "to-namespace", "", "namespace to move the release to")
+	f.StringVar(&targetDriver, "to-driver", os.Getenv("HELM_DRIVER"), "storage driver used for the release history in the target namespace")
+	f.BoolVar(&client.KeepResources, "keep-resources", false, "leave namespaced resources in place and only transfer their ownership")
+	f.BoolVar(&client.DryRun, "dry-run", false, "simulate a move")
+
+	return cmd
+}
@@ -0,0 +1,57 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"testing"
+
+	"helm.sh/helm/v3/pkg/release"
+)
+
+func TestReleaseMoveCmd(t *testing.T) {
+	rels := []*release.Release{
+		release.Mock(&release.MockReleaseOptions{Name: "funny-honey", Version: 1, Status: release.StatusSuperseded}),
+		release.Mock(&release.MockReleaseOptions{Name: "funny-honey", Version: 2}),
+	}
+	namespaced := release.Mock(&release.MockReleaseOptions{Name: "angry-bird"})
+	namespaced.Manifest = "apiVersion: v1\nkind: Secret\nmetadata:\n  name: fixture\n  namespace: default\n"
+
+	tests := []cmdTestCase{{
+		name:   "move a release in a dry run",
+		cmd:    "release move funny-honey --to-namespace spaced --to-driver memory --dry-run",
+		golden: "output/release-move-dry-run.txt",
+		rels:   rels,
+	}, {
+		name:   "move a release keeping its resources",
+		cmd:    "release move funny-honey --to-namespace spaced --to-driver memory --keep-resources",
+		golden: "output/release-move-keep-resources.txt",
+		rels:   rels,
+	}, {
+		name:      "move a release with resources in an explicit namespace",
+		cmd:       "release move angry-bird --to-namespace spaced --to-driver memory --dry-run",
+		golden:    "output/release-move-explicit-namespace.txt",
+		rels:      []*release.Release{namespaced},
+		wantError: true,
+	}, {
+		name:      "move a release without target namespace",
+		cmd:       "release move funny-honey",
+		golden:    "output/release-move-no-namespace.txt",
+		rels:      rels,
+		wantError: true,
+	}}
+	runTestCmd(t, tests)
+}
@@ -0,0 +1,72 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+
+	"helm.sh/helm/v3/cmd/helm/require"
+	"helm.sh/helm/v3/internal/completion"
+	"helm.sh/helm/v3/pkg/action"
+)
+
+const releaseRenameDesc = `
+This command renames a release.
+
+The whole release history is rewritten under the new name and the resources
+deployed by the release are relabeled so that they are owned by it. The
+resources themselves are not renamed.
+`
+
+func newReleaseRenameCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
+	client := action.NewReleaseRename(cfg)
+
+	cmd := &cobra.Command{
+		Use:   "rename RELEASE_NAME NEW_NAME",
+		Short: "rename a release",
+		Long:  releaseRenameDesc,
+		Args:  require.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if _, err := client.Run(args[0], args[1]); err != nil {
+				return err
+			}
+
+			if client.DryRun {
+				fmt.Fprintf(out, "release %q would be renamed to %q\n", args[0], args[1])
+				return nil
+			}
+			fmt.Fprintf(out, "release %q renamed to %q\n", args[0], args[1])
+			return nil
+		},
+	}
+
+	// Function providing dynamic auto-completion
+	completion.RegisterValidArgsFunc(cmd, func(cmd *cobra.Command, args []string, toComplete string) ([]string, completion.BashCompDirective) {
+		if len(args) != 0 {
+			return nil, completion.BashCompDirectiveNoFileComp
+		}
+		return compListReleases(toComplete, cfg)
+	})
+
+	f := cmd.Flags()
+	f.BoolVar(&client.DryRun, "dry-run", false, "simulate a rename")
+
+	return cmd
+}
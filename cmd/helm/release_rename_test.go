@@ -0,0 +1,56 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"testing"
+
+	"helm.sh/helm/v3/pkg/release"
+)
+
+func TestReleaseRenameCmd(t *testing.T) {
+	rels := []*release.Release{
+		release.Mock(&release.MockReleaseOptions{Name: "funny-honey", Version: 1, Status: release.StatusSuperseded}),
+		release.Mock(&release.MockReleaseOptions{Name: "funny-honey", Version: 2}),
+		release.Mock(&release.MockReleaseOptions{Name: "angry-bird"}),
+	}
+
+	tests := []cmdTestCase{{
+		name:   "rename a release",
+		cmd:    "release rename funny-honey sunny-honey",
+		golden: "output/release-rename.txt",
+		rels:   rels,
+	}, {
+		name:   "simulate renaming a release",
+		cmd:    "release rename --dry-run funny-honey sunny-honey",
+		golden: "output/release-rename-dry-run.txt",
+		rels:   rels,
+	}, {
+		name:      "rename a release to an existing name",
+		cmd:       "release rename funny-honey angry-bird",
+		golden:    "output/release-rename-existing.txt",
+		rels:      rels,
+		wantError: true,
+	}, {
+		name:      "rename a release without new name",
+		cmd:       "release rename funny-honey",
+		golden:    "output/release-rename-no-args.txt",
+		rels:      rels,
+		wantError: true,
+	}}
+	runTestCmd(t, tests)
+}
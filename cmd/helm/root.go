@@ -75,6 +75,11 @@ func newRootCmd(actionConfig *action.Configuration, out io.Writer, args []string
 		Long:                   globalUsage,
 		SilenceUsage:           true,
 		BashCompletionFunction: completion.GetBashCustomFunction(),
+		PersistentPreRun: func(cmd *cobra.Command, args []string) {
+			// Record the flags of the executed command in the audit trail of
+			// any release revision it writes.
+			actionConfig.AuditFlags = auditFlags(cmd.Flags())
+		},
 	}
 	flags := cmd.PersistentFlags()
 
@@ -140,16 +145,20 @@ func newRootCmd(actionConfig *action.Configuration, out io.Writer, args []string
 		newPullCmd(out),
 		newShowCmd(out),
 		newLintCmd(out),
+		newSchemaCmd(out),
+		newUnitTestCmd(out),
 		newPackageCmd(out),
 		newRepoCmd(out),
 		newSearchCmd(out),
 		newVerifyCmd(out),
 
 		// release commands
+		newCapabilitiesCmd(actionConfig, out),
 		newGetCmd(actionConfig, out),
 		newHistoryCmd(actionConfig, out),
 		newInstallCmd(actionConfig, out),
 		newListCmd(actionConfig, out),
+		newReleaseCmd(actionConfig, out),
 		newReleaseTestCmd(actionConfig, out),
 		newRollbackCmd(actionConfig, out),
 		newStatusCmd(actionConfig, out),
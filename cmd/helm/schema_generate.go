@@ -0,0 +1,82 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+
+	"helm.sh/helm/v3/cmd/helm/require"
+	"helm.sh/helm/v3/pkg/action"
+)
+
+const schemaGenerateDesc = `
+This command generates the values.schema.json file of a chart from its
+values.yaml file.
+
+The type of each value is inferred from its default. A value can be described in
+more detail by a comment above it, or at the end of its line, starting with
+'@schema' and followed by JSON Schema keywords and their values. Values are read
+as YAML, so lists are written as 'enum=[a,b]'. The keyword 'required' marks the
+value as required:
+
+    # @schema type=integer minimum=1 required description="Number of replicas"
+    replicaCount: 1
+    image:
+      pullPolicy: IfNotPresent  # @schema enum=[Always,IfNotPresent,Never]
+
+The schemas of the subcharts in the charts/ directory are nested under their
+name, or alias. If a subchart has a values.schema.json file, it is used as is.
+
+With '--check', the schema is not written. Instead, the command fails if the
+values.schema.json file of the chart differs from the generated schema, e.g.
+in a CI pipeline.
+`
+
+func newSchemaGenerateCmd(out io.Writer) *cobra.Command {
+	client := action.NewSchemaGenerate()
+
+	cmd := &cobra.Command{
+		Use:   "generate [CHART]",
+		Short: "generate values.schema.json from values.yaml",
+		Long:  schemaGenerateDesc,
+		Args:  require.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path := "."
+			if len(args) > 0 {
+				path = args[0]
+			}
+			schemaPath, err := client.Run(path)
+			if err != nil {
+				return err
+			}
+			if client.Check {
+				fmt.Fprintf(out, "%s is up to date\n", schemaPath)
+				return nil
+			}
+			fmt.Fprintf(out, "Wrote %s\n", schemaPath)
+			return nil
+		},
+	}
+
+	f := cmd.Flags()
+	f.BoolVar(&client.Check, "check", false, "fail if the values.schema.json file of the chart is out of date instead of writing it")
+
+	return cmd
+}
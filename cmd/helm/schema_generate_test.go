@@ -0,0 +1,45 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"testing"
+)
+
+func TestSchemaGenerateCmd(t *testing.T) {
+	tests := []cmdTestCase{{
+		name:   "check an up to date schema",
+		cmd:    "schema generate testdata/testcharts/chart-with-schema-annotations --check",
+		golden: "output/schema-generate-check.txt",
+	}, {
+		name:      "check a stale schema",
+		cmd:       "schema generate testdata/testcharts/chart-with-schema --check",
+		golden:    "output/schema-generate-check-stale.txt",
+		wantError: true,
+	}, {
+		name:      "check a missing schema",
+		cmd:       "schema generate testdata/testcharts/alpine --check",
+		golden:    "output/schema-generate-check-missing.txt",
+		wantError: true,
+	}, {
+		name:      "generate requires a chart directory",
+		cmd:       "schema generate testdata/testcharts/compressedchart-0.1.0.tgz",
+		golden:    "output/schema-generate-archive.txt",
+		wantError: true,
+	}}
+	runTestCmd(t, tests)
+}
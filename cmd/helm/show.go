@@ -20,11 +20,14 @@ import (
 	"fmt"
 	"io"
 
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 
 	"helm.sh/helm/v3/cmd/helm/require"
 	"helm.sh/helm/v3/internal/completion"
 	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/cli/values"
+	"helm.sh/helm/v3/pkg/getter"
 )
 
 const showDesc = `
@@ -39,6 +42,15 @@ This command inspects a chart (directory, file, or URL) and displays all its con
 const showValuesDesc = `
 This command inspects a chart (directory, file, or URL) and displays the contents
 of the values.yaml file
+
+With '--explain', the values computed from the chart, its subcharts and the
+values given with '--values', '--set' and related flags are shown instead, and
+each of them is annotated with its origin, e.g. 'charts/redis/values.yaml',
+'-f prod.yaml', '--set image.tag' or 'global from parent'. Values which
+override others list the sources they override, and keys removed by setting
+them to null are shown as comments.
+
+    $ helm show values ./mychart --explain -f prod.yaml --set image.tag=1.2.3
 `
 
 const showChartDesc = `
@@ -53,6 +65,8 @@ of the README file
 
 func newShowCmd(out io.Writer) *cobra.Command {
 	client := action.NewShow(action.ShowAll)
+	valueOpts := &values.Options{}
+	var explain bool
 
 	showCommand := &cobra.Command{
 		Use:     "show",
@@ -93,6 +107,12 @@ func newShowCmd(out io.Writer) *cobra.Command {
 		Args:  require.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			client.OutputFormat = action.ShowValues
+			if explain {
+				return runShowExplain(out, args, client, valueOpts)
+			}
+			if len(valueOpts.ValueFiles) > 0 || len(valueOpts.PatchFiles) > 0 || len(valueOpts.Values) > 0 || len(valueOpts.StringValues) > 0 || len(valueOpts.FileValues) > 0 || len(valueOpts.JSONValues) > 0 {
+				return errors.New("values can only be given together with --explain")
+			}
 			output, err := runShow(args, client)
 			if err != nil {
 				return err
@@ -142,6 +162,10 @@ func newShowCmd(out io.Writer) *cobra.Command {
 		completion.RegisterValidArgsFunc(subCmd, validArgsFunc)
 	}
 
+	f := valuesSubCmd.Flags()
+	f.BoolVar(&explain, "explain", false, "show the computed values, annotated with their origin")
+	addValueOptionsFlags(f, valueOpts)
+
 	return showCommand
 }
 
@@ -153,16 +177,38 @@ func addShowFlags(showCmd *cobra.Command, subCmd *cobra.Command, client *action.
 	showCmd.AddCommand(subCmd)
 }
 
+func runShowExplain(out io.Writer, args []string, client *action.Show, valueOpts *values.Options) error {
+	cp, err := locateShowChart(args, client)
+	if err != nil {
+		return err
+	}
+
+	vals, layers, err := valueOpts.MergeLayeredValues(getter.All(settings))
+	if err != nil {
+		return err
+	}
+
+	origins, err := client.ExplainValues(cp, vals, layers)
+	if err != nil {
+		return err
+	}
+	return writeValueOrigins(out, origins)
+}
+
 func runShow(args []string, client *action.Show) (string, error) {
+	cp, err := locateShowChart(args, client)
+	if err != nil {
+		return "", err
+	}
+	return client.Run(cp)
+}
+
+func locateShowChart(args []string, client *action.Show) (string, error) {
 	debug("Original chart version: %q", client.Version)
 	if client.Version == "" && client.Devel {
 		debug("setting version to >0.0.0-0")
 		client.Version = ">0.0.0-0"
 	}
 
-	cp, err := client.ChartPathOptions.LocateChart(args[0], settings)
-	if err != nil {
-		return "", err
-	}
-	return client.Run(cp)
+	return client.ChartPathOptions.LocateChart(args[0], settings)
 }
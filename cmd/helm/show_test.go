@@ -80,3 +80,17 @@ func TestShowPreReleaseChart(t *testing.T) {
 		})
 	}
 }
+
+func TestShowValuesExplain(t *testing.T) {
+	tests := []cmdTestCase{{
+		name:   "explain values of a chart",
+		cmd:    "show values testdata/testcharts/explain --explain -f testdata/explain-values.yaml --set image.tag=2.1",
+		golden: "output/show-values-explain.txt",
+	}, {
+		name:      "values require --explain",
+		cmd:       "show values testdata/testcharts/explain --set image.tag=2.1",
+		golden:    "output/show-values-explain-required.txt",
+		wantError: true,
+	}}
+	runTestCmd(t, tests)
+}
@@ -114,6 +114,18 @@ func (s statusPrinter) WriteTable(out io.Writer) error {
 	fmt.Fprintf(out, "NAMESPACE: %s\n", s.release.Namespace)
 	fmt.Fprintf(out, "STATUS: %s\n", s.release.Info.Status.String())
 	fmt.Fprintf(out, "REVISION: %d\n", s.release.Version)
+	if a := s.release.Info.Audit; a != nil {
+		fmt.Fprintf(out, "PERFORMED BY: %s\n", a.User)
+		if a.Hostname != "" {
+			fmt.Fprintf(out, "HOSTNAME: %s\n", a.Hostname)
+		}
+		if a.HelmVersion != "" {
+			fmt.Fprintf(out, "HELM VERSION: %s\n", a.HelmVersion)
+		}
+		if len(a.Flags) > 0 {
+			fmt.Fprintf(out, "FLAGS: %s\n", strings.Join(a.Flags, " "))
+		}
+	}
 
 	executions := executionsByHookEvent(s.release)
 	if tests, ok := executions[release.HookTest]; !ok || len(tests) == 0 {
@@ -52,6 +52,19 @@ func TestStatusCmd(t *testing.T) {
 			Status: release.StatusDeployed,
 			Notes:  "release notes",
 		}),
+	}, {
+		name:   "get status of a deployed release with audit trail",
+		cmd:    "status flummoxed-chickadee",
+		golden: "output/status-with-audit.txt",
+		rels: releasesMockWithStatus(&release.Info{
+			Status: release.StatusDeployed,
+			Audit: &release.Audit{
+				User:        "jane",
+				HelmVersion: "v3.2",
+				Hostname:    "laptop",
+				Flags:       []string{"--namespace=default"},
+			},
+		}),
 	}, {
 		name:   "get status of a deployed release with notes in json",
 		cmd:    "status flummoxed-chickadee -o json",
@@ -20,11 +20,14 @@ import (
 	"bytes"
 	"fmt"
 	"io"
+	"os"
 	"path/filepath"
 	"regexp"
 	"sort"
 	"strings"
+	"time"
 
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 
 	"helm.sh/helm/v3/cmd/helm/require"
@@ -32,6 +35,7 @@ import (
 	"helm.sh/helm/v3/pkg/action"
 	"helm.sh/helm/v3/pkg/chartutil"
 	"helm.sh/helm/v3/pkg/cli/values"
+	"helm.sh/helm/v3/pkg/engine"
 	"helm.sh/helm/v3/pkg/releaseutil"
 )
 
@@ -41,6 +45,44 @@ Render chart templates locally and display the output.
 Any values that would normally be looked up or retrieved in-cluster will be
 faked locally. Additionally, none of the server-side testing of chart validity
 (e.g. whether an API is supported) is done.
+
+The 'lookup' template function returns empty objects, unless Kubernetes
+objects, e.g. exported with 'kubectl get -o yaml', are given with
+'--lookup-fixtures' as a file or a directory of files. Objects are then looked
+up by apiVersion, kind, namespace and name, and listed when no name is given.
+
+Templates are rendered with default capabilities, a Kubernetes version and API
+versions, extended by '--api-versions'. To render a chart as it would be
+rendered in a cluster, export the capabilities of the cluster once and render
+with '--capabilities-file', without access to the cluster:
+
+    $ helm capabilities export > caps.yaml
+    $ helm template --capabilities-file caps.yaml myredis ./redis
+
+Use '--strict-values' to fail on every reference of the templates to a value
+which is not set, with its template and line, rather than rendering it empty:
+
+    $ helm template --strict-values --optional-values 'ingress.annotations,*.podLabels' myredis ./redis
+
+References guarded by 'if' or 'with', such as '.Values.ingress.host' in
+'{{ if .Values.ingress }}', or given to 'default', are intended to be optional
+and not reported.
+
+Template functions such as 'randAlphaNum', 'uuidv4' or 'now' produce different
+output every time. Use '--render-seed' to seed the random functions and
+'--render-time' to fix the current time, so the same chart and values always
+render the same output. Functions which cannot be seeded, such as
+'genPrivateKey' or 'genCA', then return a placeholder, and the date functions
+read and format dates in UTC.
+
+With '--snapshot-dir', the manifests are compared with snapshot files in the
+given directory instead of being displayed, one file per resource. Snapshots of
+new resources are written, and the command fails with a diff if a resource
+differs from its snapshot, or is no longer rendered. Use '--update-snapshots' to
+accept the changes, or '--check-snapshots' to also fail on missing snapshots
+without writing any file, e.g. in a CI pipeline. Snapshots are always rendered
+with a seed and time, 0 and 1970-01-01T00:00:00Z unless given, so they are
+stable.
 `
 
 func newTemplateCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
@@ -50,19 +92,42 @@ func newTemplateCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 	valueOpts := &values.Options{}
 	var extraAPIs []string
 	var showFiles []string
+	snapshot := action.NewManifestSnapshot("")
+	var renderSeed int64
+	var renderTime string
 
 	cmd := &cobra.Command{
 		Use:   "template [NAME] [CHART]",
 		Short: "locally render templates",
 		Long:  templateDesc,
 		Args:  require.MinimumNArgs(1),
-		RunE: func(_ *cobra.Command, args []string) error {
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if snapshot.Dir == "" && (snapshot.Check || snapshot.Update) {
+				return errors.New("--check-snapshots and --update-snapshots require --snapshot-dir")
+			}
+			if snapshot.Dir != "" && (client.OutputDir != "" || len(showFiles) > 0) {
+				return errors.New("--snapshot-dir cannot be combined with --output-dir or --show-only")
+			}
+			if cmd.Flags().Changed("render-seed") || snapshot.Dir != "" {
+				client.RenderSeed = &renderSeed
+			}
+			if renderTime != "" {
+				t, err := time.Parse(time.RFC3339, renderTime)
+				if err != nil {
+					return errors.Wrap(err, "invalid --render-time")
+				}
+				client.RenderTime = &t
+			} else if snapshot.Dir != "" {
+				t := engine.DefaultRenderTime
+				client.RenderTime = &t
+			}
 			client.DryRun = true
 			client.ReleaseName = "RELEASE-NAME"
 			client.Replace = true // Skip the name check
 			client.ClientOnly = !validate
 			client.APIVersions = chartutil.VersionSet(extraAPIs)
 			client.IncludeCRDs = includeCrds
+			client.Warnings = os.Stderr
 			rel, err := runInstall(args, client, valueOpts, out)
 
 			if err != nil && !settings.Debug {
@@ -84,6 +149,13 @@ func newTemplateCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 					}
 				}
 
+				if snapshot.Dir != "" {
+					if err != nil {
+						return err
+					}
+					return runSnapshot(snapshot, manifests.String(), out)
+				}
+
 				// if we have a list of files to render, then check that each of the
 				// provided files exists in the chart.
 				if len(showFiles) > 0 {
@@ -143,6 +215,7 @@ func newTemplateCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 
 	f := cmd.Flags()
 	addInstallFlags(f, client, valueOpts)
+	addReleaseValuesFlags(f, valueOpts, cfg)
 	f.StringArrayVarP(&showFiles, "show-only", "s", []string{}, "only show manifests rendered from the given templates")
 	f.StringVar(&client.OutputDir, "output-dir", "", "writes the executed templates to files in output-dir instead of stdout")
 	f.BoolVar(&validate, "validate", false, "validate your manifests against the Kubernetes cluster you are currently pointing at. This is the same validation performed on an install")
@@ -150,7 +223,42 @@ func newTemplateCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 	f.BoolVar(&client.IsUpgrade, "is-upgrade", false, "set .Release.IsUpgrade instead of .Release.IsInstall")
 	f.StringArrayVarP(&extraAPIs, "api-versions", "a", []string{}, "Kubernetes api versions used for Capabilities.APIVersions")
 	f.BoolVar(&client.UseReleaseName, "release-name", false, "use release name in the output-dir path.")
+	f.Int64Var(&renderSeed, "render-seed", 0, "seed the random template functions, such as randAlphaNum, so they render the same values every time")
+	f.StringVar(&renderTime, "render-time", "", "the time returned by the now template function, in RFC 3339 format, e.g. 2020-01-01T00:00:00Z")
+	f.StringVar(&snapshot.Dir, "snapshot-dir", "", "compare the rendered resources with the snapshot files in the given directory instead of displaying them")
+	f.BoolVar(&snapshot.Check, "check-snapshots", false, "fail if a snapshot is missing or differs, without writing any snapshot file")
+	f.BoolVar(&snapshot.Update, "update-snapshots", false, "update the snapshots which differ from the rendered resources and remove obsolete ones")
 	bindPostRenderFlag(cmd, &client.PostRenderer)
+	bindLookupFixturesFlag(cmd, &client.LookupFixtures)
+	bindCapabilitiesFileFlag(cmd, &client.Capabilities)
+	bindDeprecatedAPIsFileFlag(cmd, &client.DeprecatedAPIs)
 
 	return cmd
 }
+
+func runSnapshot(snapshot *action.ManifestSnapshot, manifests string, out io.Writer) error {
+	result, err := snapshot.Run(manifests)
+	if err != nil {
+		return err
+	}
+	for _, name := range result.Written {
+		fmt.Fprintf(out, "wrote %s\n", filepath.Join(snapshot.Dir, name))
+	}
+	for _, name := range result.Updated {
+		fmt.Fprintf(out, "updated %s\n", filepath.Join(snapshot.Dir, name))
+	}
+	for _, name := range result.Removed {
+		fmt.Fprintf(out, "removed %s\n", filepath.Join(snapshot.Dir, name))
+	}
+	for _, d := range result.Diffs {
+		fmt.Fprint(out, d.Diff)
+	}
+
+	summary := fmt.Sprintf("%d snapshot(s) unchanged, %d written, %d updated, %d removed",
+		len(result.Unchanged), len(result.Written), len(result.Updated), len(result.Removed))
+	if len(result.Diffs) > 0 {
+		return errors.Errorf("%d snapshot(s) differ from the rendered resources, run with --update-snapshots to accept the changes (%s)", len(result.Diffs), summary)
+	}
+	fmt.Fprintln(out, summary)
+	return nil
+}
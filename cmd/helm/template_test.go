@@ -79,6 +79,17 @@ func TestTemplateCmd(t *testing.T) {
 			cmd:    fmt.Sprintf("template --api-versions helm.k8s.io/test '%s'", chartPath),
 			golden: "output/template-with-api-version.txt",
 		},
+		{
+			name:   "check capabilities file",
+			cmd:    fmt.Sprintf("template --capabilities-file testdata/capabilities/capabilities.yaml '%s' --show-only templates/service.yaml", chartPath),
+			golden: "output/template-capabilities-file.txt",
+		},
+		{
+			name:      "check invalid capabilities file",
+			cmd:       fmt.Sprintf("template --capabilities-file testdata/capabilities/invalid.yaml '%s'", chartPath),
+			golden:    "output/template-capabilities-file-invalid.txt",
+			wantError: true,
+		},
 		{
 			name:   "template with CRDs",
 			cmd:    fmt.Sprintf("template '%s' --include-crds", chartPath),
@@ -121,6 +132,56 @@ func TestTemplateCmd(t *testing.T) {
 			wantError: true,
 			golden:    "output/template-with-invalid-yaml-debug.txt",
 		},
+		{
+			name:   "check reproducible rendering",
+			cmd:    "template testdata/testcharts/snapshot --render-seed 3 --render-time 2020-01-01T10:00:00+02:00 --show-only templates/secret.yaml",
+			golden: "output/template-render-seed.txt",
+		},
+		{
+			name:      "check invalid render time",
+			cmd:       "template testdata/testcharts/snapshot --render-time yesterday",
+			golden:    "output/template-render-time-invalid.txt",
+			wantError: true,
+		},
+		{
+			name:   "check lookup fixtures",
+			cmd:    "template testdata/testcharts/lookup --lookup-fixtures testdata/lookup-fixtures",
+			golden: "output/template-lookup-fixtures.txt",
+		},
+		{
+			name:      "check missing lookup fixtures",
+			cmd:       "template testdata/testcharts/lookup --lookup-fixtures testdata/lookup-fixtures/missing.yaml",
+			golden:    "output/template-lookup-fixtures-missing.txt",
+			wantError: true,
+		},
+		{
+			name:      "check strict values",
+			cmd:       "template testdata/testcharts/strict-values --strict-values",
+			golden:    "output/template-strict-values.txt",
+			wantError: true,
+		},
+		{
+			name:   "check strict values with optional values",
+			cmd:    "template testdata/testcharts/strict-values --strict-values --optional-values partOf,service.targetPort --optional-values image.tag",
+			golden: "output/template-strict-values-optional.txt",
+		},
+		{
+			name:   "check up to date snapshots",
+			cmd:    "template testdata/testcharts/snapshot --snapshot-dir testdata/snapshots/snapshot --check-snapshots",
+			golden: "output/template-check-snapshots.txt",
+		},
+		{
+			name:      "check stale snapshots",
+			cmd:       "template testdata/testcharts/snapshot --snapshot-dir testdata/snapshots/snapshot-stale --check-snapshots",
+			golden:    "output/template-check-snapshots-stale.txt",
+			wantError: true,
+		},
+		{
+			name:      "check snapshots without a snapshot directory",
+			cmd:       "template testdata/testcharts/snapshot --check-snapshots",
+			golden:    "output/template-check-snapshots-no-dir.txt",
+			wantError: true,
+		},
 	}
 	runTestCmd(t, tests)
 }
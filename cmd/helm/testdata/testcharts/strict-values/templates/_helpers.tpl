@@ -0,0 +1,4 @@
+{{- define "strict-values.labels" -}}
+app.kubernetes.io/name: {{ .Chart.Name }}
+app.kubernetes.io/part-of: {{ .Values.partOf }}
+{{- end }}
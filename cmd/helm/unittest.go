@@ -0,0 +1,158 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"helm.sh/helm/v3/cmd/helm/require"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/unittest"
+)
+
+const unittestDesc = `
+This command runs the unit tests of a chart against its rendered templates,
+without a Kubernetes cluster.
+
+The tests are read from the files ending in '_test.yaml' in the 'tests'
+directory of the chart. Each file is a suite of tests. A test renders the
+templates of the chart with the values, release and capabilities given by the
+suite and the test, and checks the rendered documents with assertions:
+
+    suite: deployment
+    templates:
+      - templates/deployment.yaml
+    values:
+      - values/prod.yaml
+    tests:
+      - it: should set the replicas
+        set:
+          replicaCount: 3
+        release:
+          name: my-release
+          namespace: prod
+        capabilities:
+          kubeVersion: v1.17.0
+          apiVersions:
+            - monitoring.coreos.com/v1
+        asserts:
+          - equal:
+              path: spec.replicas
+              value: 3
+          - matchRegex:
+              path: metadata.name
+              pattern: ^my-release-
+          - hasDocuments:
+              count: 1
+          - matchSnapshot: {}
+      - it: should require an image
+        set:
+          image: null
+        asserts:
+          - failedTemplate:
+              errorMessage: image is required
+
+Paths are JSONPath expressions, with or without the enclosing braces. Dots in
+keys are escaped with a backslash, as in 'metadata.labels.app\.kubernetes\.io/name'.
+The assertions are equal, notEqual, matchRegex, notMatchRegex, hasDocuments,
+failedTemplate and matchSnapshot. An assertion applies to every document of the
+templates of the test, unless it is restricted with 'template' or
+'documentIndex'.
+
+Templates are rendered reproducibly: random functions such as 'randAlphaNum'
+are seeded, and 'now' returns 1970-01-01T00:00:00Z.
+
+Snapshots are stored in the '__snapshot__' directory next to the suites. A
+missing snapshot is recorded on the first run. Use '--update-snapshots' to
+accept changed snapshots.
+
+Use '--output junit' to print the results as JUnit XML, e.g. for a CI system.
+`
+
+func newUnitTestCmd(out io.Writer) *cobra.Command {
+	client := action.NewUnitTest()
+	var outfmt string
+
+	cmd := &cobra.Command{
+		Use:   "unittest [CHART]",
+		Short: "run the unit tests of a chart",
+		Long:  unittestDesc,
+		Args:  require.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if outfmt != "text" && outfmt != "junit" {
+				return errors.Errorf("invalid output format %q, must be one of: text, junit", outfmt)
+			}
+			path := "."
+			if len(args) > 0 {
+				path = args[0]
+			}
+			result, err := client.Run(path)
+			if err != nil {
+				return err
+			}
+
+			if outfmt == "junit" {
+				if err := unittest.WriteJUnit(out, result.Chart, result.Suites); err != nil {
+					return err
+				}
+			} else {
+				writeUnitTestResults(out, result)
+			}
+
+			summary := fmt.Sprintf("%d test(s) run, %d test(s) failed", result.Total(), result.Failed())
+			if result.Failed() > 0 {
+				return errors.New(summary)
+			}
+			if outfmt == "text" {
+				fmt.Fprintln(out, summary)
+			}
+			return nil
+		},
+	}
+
+	f := cmd.Flags()
+	f.BoolVarP(&client.UpdateSnapshots, "update-snapshots", "u", false, "update the snapshots of the tests instead of comparing them")
+	f.BoolVar(&client.Strict, "strict", false, "fail on references to missing values")
+	f.StringVarP(&outfmt, "output", "o", "text", "prints the results in the specified format. Allowed values: text, junit")
+
+	return cmd
+}
+
+func writeUnitTestResults(out io.Writer, result *action.UnitTestResult) {
+	for _, s := range result.Suites {
+		for _, t := range s.Tests {
+			switch {
+			case t.Err != nil:
+				fmt.Fprintf(out, "ERROR %s: %s\n", s.Suite.Name, t.Test.It)
+				fmt.Fprintf(out, "    %s\n", t.Err)
+			case len(t.Failures) > 0:
+				fmt.Fprintf(out, "FAIL  %s: %s\n", s.Suite.Name, t.Test.It)
+				for _, f := range t.Failures {
+					fmt.Fprintf(out, "    %s\n", strings.ReplaceAll(strings.TrimRight(f, "\n"), "\n", "\n    "))
+				}
+			default:
+				fmt.Fprintf(out, "PASS  %s: %s\n", s.Suite.Name, t.Test.It)
+			}
+		}
+	}
+	fmt.Fprintln(out)
+}
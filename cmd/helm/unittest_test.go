@@ -0,0 +1,45 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"testing"
+)
+
+func TestUnitTestCmd(t *testing.T) {
+	tests := []cmdTestCase{{
+		name:   "run passing tests",
+		cmd:    "unittest testdata/testcharts/unittest",
+		golden: "output/unittest.txt",
+	}, {
+		name:      "run failing tests",
+		cmd:       "unittest testdata/testcharts/unittest-failing",
+		golden:    "output/unittest-failing.txt",
+		wantError: true,
+	}, {
+		name:      "chart without tests",
+		cmd:       "unittest testdata/testcharts/alpine",
+		golden:    "output/unittest-no-suites.txt",
+		wantError: true,
+	}, {
+		name:      "invalid output format",
+		cmd:       "unittest testdata/testcharts/unittest -o json",
+		golden:    "output/unittest-invalid-output.txt",
+		wantError: true,
+	}}
+	runTestCmd(t, tests)
+}
@@ -31,6 +31,7 @@ import (
 	"k8s.io/client-go/discovery"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
+	"sigs.k8s.io/yaml"
 
 	"helm.sh/helm/v3/internal/experimental/registry"
 	"helm.sh/helm/v3/pkg/chart"
@@ -91,24 +92,41 @@ type Configuration struct {
 	// Capabilities describes the capabilities of the Kubernetes cluster.
 	Capabilities *chartutil.Capabilities
 
+	// AuditFlags are the command-line flags recorded in the audit trail of every
+	// revision written. Secret values must be redacted by the caller.
+	AuditFlags []string
+
 	Log func(string, ...interface{})
 }
 
-// renderResources renders the templates in a chart
-//
-// TODO: This function is badly in need of a refactor.
-func (c *Configuration) renderResources(ch *chart.Chart, values chartutil.Values, releaseName, outputDir string, subNotes, useReleaseName, includeCrds bool, pr postrender.PostRenderer, dryRun bool) ([]*release.Hook, *bytes.Buffer, string, error) {
-	hs := []*release.Hook{}
-	b := bytes.NewBuffer(nil)
+// renderedManifests is a chart rendered as Helm installs it.
+type renderedManifests struct {
+	hooks     []*release.Hook
+	manifests []releaseutil.Manifest
+	notes     string
+	outputs   map[string]interface{}
+	// rendered are all the rendered templates, including the notes and the
+	// outputs, for lint to check them one by one.
+	rendered map[string]string
+	// files are the rendered templates, returned with the errors sorting
+	// them to help debugging.
+	files map[string]string
+}
+
+// renderManifests renders the templates of a chart, and sorts them into
+// hooks and manifests in install order, apart from its notes and outputs.
+// It is the rendering of renderResources, which lint shares.
+func (c *Configuration) renderManifests(ch *chart.Chart, values chartutil.Values, subNotes bool, opts renderOptions, dryRun bool) (renderedManifests, error) {
+	var r renderedManifests
 
 	caps, err := c.getCapabilities()
 	if err != nil {
-		return hs, b, "", err
+		return r, err
 	}
 
 	if ch.Metadata.KubeVersion != "" {
 		if !chartutil.IsCompatibleRange(ch.Metadata.KubeVersion, caps.KubeVersion.String()) {
-			return hs, b, "", errors.Errorf("chart requires kubeVersion: %s which is incompatible with Kubernetes %s", ch.Metadata.KubeVersion, caps.KubeVersion.String())
+			return r, errors.Errorf("chart requires kubeVersion: %s which is incompatible with Kubernetes %s", ch.Metadata.KubeVersion, caps.KubeVersion.String())
 		}
 	}
 
@@ -123,15 +141,19 @@ func (c *Configuration) renderResources(ch *chart.Chart, values chartutil.Values
 	if !dryRun && c.RESTClientGetter != nil {
 		rest, err := c.RESTClientGetter.ToRESTConfig()
 		if err != nil {
-			return hs, b, "", err
+			return r, err
 		}
-		files, err2 = engine.RenderWithClient(ch, values, rest)
+		files, err2 = opts.engine(engine.New(rest)).Render(ch, values)
 	} else {
-		files, err2 = engine.Render(ch, values)
+		files, err2 = opts.engine(engine.Engine{}).Render(ch, values)
 	}
 
 	if err2 != nil {
-		return hs, b, "", err2
+		return r, err2
+	}
+	r.rendered = make(map[string]string, len(files))
+	for k, v := range files {
+		r.rendered[k] = v
 	}
 
 	// NOTES.txt gets rendered like all the other files, but because it's not a hook nor a resource,
@@ -152,25 +174,64 @@ func (c *Configuration) renderResources(ch *chart.Chart, values chartutil.Values
 			delete(files, k)
 		}
 	}
-	notes := notesBuffer.String()
+	r.notes = notesBuffer.String()
+
+	// OUTPUTS.yaml is rendered like NOTES.txt, but holds structured data that is
+	// stored on the release for other tools to consume. Only the outputs of the
+	// top-level chart are kept.
+	for k, v := range files {
+		if strings.HasSuffix(k, outputsFileSuffix) {
+			if k == path.Join(ch.Name(), "templates", outputsFileSuffix) {
+				if err := yaml.Unmarshal([]byte(v), &r.outputs); err != nil {
+					return r, errors.Wrapf(err, "unable to parse %s", k)
+				}
+			}
+			delete(files, k)
+		}
+	}
 
 	// Sort hooks, manifests, and partials. Only hooks and manifests are returned,
 	// as partials are not used after renderer.Render. Empty manifests are also
 	// removed here.
-	hs, manifests, err := releaseutil.SortManifests(files, caps.APIVersions, releaseutil.InstallOrder)
+	r.hooks, r.manifests, err = releaseutil.SortManifests(files, caps.APIVersions, releaseutil.InstallOrder)
 	if err != nil {
 		// By catching parse errors here, we can prevent bogus releases from going
 		// to Kubernetes.
-		//
+		r.files = files
+		return r, err
+	}
+	return r, nil
+}
+
+// renderResources renders the templates in a chart, returning the hooks, the
+// manifests, the notes and the outputs of the chart.
+//
+// TODO: This function is badly in need of a refactor.
+func (c *Configuration) renderResources(ch *chart.Chart, values chartutil.Values, releaseName, outputDir string, subNotes, useReleaseName, includeCrds bool, opts renderOptions, pr postrender.PostRenderer, dryRun bool) ([]*release.Hook, *bytes.Buffer, string, map[string]interface{}, error) {
+	hs := []*release.Hook{}
+	b := bytes.NewBuffer(nil)
+
+	r, err := c.renderManifests(ch, values, subNotes, opts, dryRun)
+	if err != nil {
 		// We return the files as a big blob of data to help the user debug parser
 		// errors.
-		for name, content := range files {
+		for name, content := range r.files {
 			if strings.TrimSpace(content) == "" {
 				continue
 			}
 			fmt.Fprintf(b, "---\n# Source: %s\n%s\n", name, content)
 		}
-		return hs, b, "", err
+		if r.hooks != nil {
+			hs = r.hooks
+		}
+		return hs, b, "", nil, err
+	}
+	hs, manifests, notes, outputs := r.hooks, r.manifests, r.notes, r.outputs
+
+	// Objects rendered twice would fail the install half way, or silently
+	// overwrite each other.
+	if dups := releaseutil.FindDuplicateObjects(hs, manifests); len(dups) > 0 {
+		return hs, b, "", nil, dups[0]
 	}
 
 	// Aggregate all valid manifests into one big doc.
@@ -183,7 +244,7 @@ func (c *Configuration) renderResources(ch *chart.Chart, values chartutil.Values
 			} else {
 				err = writeToFile(outputDir, crd.Filename, string(crd.File.Data[:]), fileWritten[crd.Name])
 				if err != nil {
-					return hs, b, "", err
+					return hs, b, "", nil, err
 				}
 				fileWritten[crd.Name] = true
 			}
@@ -204,7 +265,7 @@ func (c *Configuration) renderResources(ch *chart.Chart, values chartutil.Values
 			// used by install or upgrade
 			err = writeToFile(newDir, m.Name, m.Content, fileWritten[m.Name])
 			if err != nil {
-				return hs, b, "", err
+				return hs, b, "", nil, err
 			}
 			fileWritten[m.Name] = true
 		}
@@ -213,11 +274,11 @@ func (c *Configuration) renderResources(ch *chart.Chart, values chartutil.Values
 	if pr != nil {
 		b, err = pr.Run(b)
 		if err != nil {
-			return hs, b, notes, errors.Wrap(err, "error while running post render on files")
+			return hs, b, notes, outputs, errors.Wrap(err, "error while running post render on files")
 		}
 	}
 
-	return hs, b, notes, nil
+	return hs, b, notes, outputs, nil
 }
 
 // RESTClientGetter gets the rest client
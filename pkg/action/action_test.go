@@ -205,6 +205,21 @@ func withNotes(notes string) chartOption {
 	}
 }
 
+func withOutputs(outputs string) chartOption {
+	return func(opts *chartOptions) {
+		opts.Templates = append(opts.Templates, &chart.File{
+			Name: "templates/OUTPUTS.yaml",
+			Data: []byte(outputs),
+		})
+	}
+}
+
+func withTemplates(templates ...*chart.File) chartOption {
+	return func(opts *chartOptions) {
+		opts.Templates = templates
+	}
+}
+
 func withDependency(dependencyOpts ...chartOption) chartOption {
 	return func(opts *chartOptions) {
 		opts.AddDependency(buildChart(dependencyOpts...))
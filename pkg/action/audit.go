@@ -0,0 +1,131 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"io/ioutil"
+	"os"
+	"os/user"
+	"strings"
+
+	"k8s.io/client-go/rest"
+
+	"helm.sh/helm/v3/internal/version"
+	"helm.sh/helm/v3/pkg/release"
+)
+
+// Auditor is a function capable of describing who performs a release operation.
+//
+// By default, this inspects the Kubernetes credentials of the configuration and
+// the local environment. This can be overridden for testing though, so that the
+// audit trail is predictable. Returning nil disables the audit trail.
+var Auditor = defaultAuditor
+
+// audit returns the audit record for a revision written with this configuration.
+func (c *Configuration) audit() *release.Audit {
+	a := Auditor(c)
+	if a != nil && a.Flags == nil {
+		a.Flags = c.AuditFlags
+	}
+	return a
+}
+
+func defaultAuditor(c *Configuration) *release.Audit {
+	a := &release.Audit{
+		User:        localUser(),
+		HelmVersion: version.GetVersion(),
+	}
+	if hostname, err := os.Hostname(); err == nil {
+		a.Hostname = hostname
+	}
+	if c.RESTClientGetter != nil {
+		if conf, err := c.RESTClientGetter.ToRESTConfig(); err == nil {
+			if u := kubeUser(conf); u != "" {
+				a.User = u
+			}
+		}
+	}
+	return a
+}
+
+// kubeUser determines the identity the Kubernetes API server will see for the
+// given configuration, similar to what a SelfSubjectReview would report. It
+// looks at impersonation, basic auth, the subject of a bearer token and the
+// common name of a client certificate, in that order. An empty string is
+// returned if none of those is available.
+func kubeUser(conf *rest.Config) string {
+	if conf.Impersonate.UserName != "" {
+		return conf.Impersonate.UserName
+	}
+	if conf.Username != "" {
+		return conf.Username
+	}
+
+	token := conf.BearerToken
+	if token == "" && conf.BearerTokenFile != "" {
+		if b, err := ioutil.ReadFile(conf.BearerTokenFile); err == nil {
+			token = strings.TrimSpace(string(b))
+		}
+	}
+	if sub := tokenSubject(token); sub != "" {
+		return sub
+	}
+
+	certData := conf.CertData
+	if len(certData) == 0 && conf.CertFile != "" {
+		if b, err := ioutil.ReadFile(conf.CertFile); err == nil {
+			certData = b
+		}
+	}
+	if block, _ := pem.Decode(certData); block != nil {
+		if cert, err := x509.ParseCertificate(block.Bytes); err == nil {
+			return cert.Subject.CommonName
+		}
+	}
+	return ""
+}
+
+// tokenSubject returns the "sub" claim of a JSON Web Token. The signature is not
+// verified; the subject is only recorded for informational purposes.
+func tokenSubject(token string) string {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return ""
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return ""
+	}
+	var claims struct {
+		Subject string `json:"sub"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return ""
+	}
+	return claims.Subject
+}
+
+func localUser() string {
+	if u, err := user.Current(); err == nil {
+		return u.Username
+	}
+	return os.Getenv("USER")
+}
@@ -0,0 +1,67 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/client-go/rest"
+)
+
+func TestKubeUser(t *testing.T) {
+	claims := base64.RawURLEncoding.EncodeToString([]byte(`{"sub":"system:serviceaccount:ci:deployer"}`))
+	token := "eyJhbGciOiJSUzI1NiJ9." + claims + ".c2lnbmF0dXJl"
+
+	tests := []struct {
+		name   string
+		conf   *rest.Config
+		expect string
+	}{
+		{"empty", &rest.Config{}, ""},
+		{"basic auth", &rest.Config{Username: "jane"}, "jane"},
+		{"bearer token", &rest.Config{BearerToken: token}, "system:serviceaccount:ci:deployer"},
+		{"opaque token", &rest.Config{BearerToken: "not-a-jwt"}, ""},
+		{"impersonation", &rest.Config{
+			Username:    "jane",
+			Impersonate: rest.ImpersonationConfig{UserName: "admin"},
+		}, "admin"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expect, kubeUser(tt.conf))
+		})
+	}
+}
+
+func TestInstallRelease_Audit(t *testing.T) {
+	is := assert.New(t)
+	instAction := installAction(t)
+	instAction.cfg.AuditFlags = []string{"--wait=true"}
+
+	res, err := instAction.Run(buildChart(), map[string]interface{}{})
+	if err != nil {
+		t.Fatalf("Failed install: %s", err)
+	}
+
+	is.NotNil(res.Info.Audit)
+	is.NotEmpty(res.Info.Audit.User)
+	is.NotEmpty(res.Info.Audit.HelmVersion)
+	is.Equal([]string{"--wait=true"}, res.Info.Audit.Flags)
+}
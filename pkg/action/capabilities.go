@@ -0,0 +1,42 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"helm.sh/helm/v3/pkg/chartutil"
+)
+
+// Capabilities is the action for reading the capabilities of a cluster, so
+// that charts can be rendered offline as they would be in the cluster.
+//
+// It provides the implementation of 'helm capabilities export'.
+type Capabilities struct {
+	cfg *Configuration
+}
+
+// NewCapabilities creates a new Capabilities object with the given configuration.
+func NewCapabilities(cfg *Configuration) *Capabilities {
+	return &Capabilities{cfg: cfg}
+}
+
+// Run returns the Kubernetes version and the API versions of the cluster.
+func (c *Capabilities) Run() (*chartutil.Capabilities, error) {
+	if err := c.cfg.KubeClient.IsReachable(); err != nil {
+		return nil, err
+	}
+	return c.cfg.getCapabilities()
+}
@@ -0,0 +1,95 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"fmt"
+	"io"
+	"sort"
+
+	"sigs.k8s.io/yaml"
+
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/releaseutil"
+)
+
+// deprecatedAPIWarnings returns warnings about the objects of a manifest
+// using APIs removed by a version of Kubernetes, or by the next minor
+// version, which an upgrade of the cluster would break.
+func deprecatedAPIWarnings(manifest string, apis chartutil.DeprecatedAPIs, kubeVersion chartutil.KubeVersion) []string {
+	if apis == nil {
+		apis = chartutil.DefaultDeprecatedAPIs
+	}
+	next := chartutil.NextMinor(kubeVersion)
+
+	manifests := releaseutil.SplitManifests(manifest)
+	keys := make([]string, 0, len(manifests))
+	for k := range manifests {
+		keys = append(keys, k)
+	}
+	sort.Sort(releaseutil.BySplitManifestsOrder(keys))
+
+	var warnings []string
+	for _, k := range keys {
+		var head releaseutil.SimpleHead
+		if err := yaml.Unmarshal([]byte(manifests[k]), &head); err != nil {
+			continue
+		}
+		api := apis.Lookup(head.Version, head.Kind)
+		if api == nil {
+			continue
+		}
+		var name string
+		if head.Metadata != nil {
+			name = head.Metadata.Name
+		}
+
+		var warning string
+		switch {
+		case api.IsRemoved(kubeVersion):
+			warning = fmt.Sprintf("%s %q uses %s, which is removed in Kubernetes %s", head.Kind, name, api, api.RemovedIn)
+		case api.IsRemoved(next):
+			warning = fmt.Sprintf("%s %q uses %s, which is removed in Kubernetes %s, the next minor version of the cluster", head.Kind, name, api, api.RemovedIn)
+		default:
+			continue
+		}
+		if api.Replacement != "" {
+			warning += fmt.Sprintf(", use %s instead", api.Replacement)
+		}
+		warnings = append(warnings, warning)
+	}
+	return warnings
+}
+
+// warnDeprecatedAPIs logs the warnings about the APIs of the manifest and the
+// hooks of a release removed by the Kubernetes version of the cluster or its
+// next minor version, and writes them to out if it is not nil.
+func (c *Configuration) warnDeprecatedAPIs(rel *release.Release, apis chartutil.DeprecatedAPIs, kubeVersion chartutil.KubeVersion, out io.Writer) {
+	manifests := []string{rel.Manifest}
+	for _, h := range rel.Hooks {
+		manifests = append(manifests, h.Manifest)
+	}
+	for _, manifest := range manifests {
+		for _, w := range deprecatedAPIWarnings(manifest, apis, kubeVersion) {
+			c.Log("warning: %s", w)
+			if out != nil {
+				fmt.Fprintf(out, "WARNING: %s\n", w)
+			}
+		}
+	}
+}
@@ -58,3 +58,21 @@ func (g *GetValues) Run(name string) (map[string]interface{}, error) {
 	}
 	return rel.Config, nil
 }
+
+// Explain computes all values of the given release and reports where each of
+// them came from. Values supplied by the user when the release was installed or
+// upgraded are attributed to "user-supplied".
+func (g *GetValues) Explain(name string) ([]chartutil.ValueOrigin, error) {
+	if err := g.cfg.KubeClient.IsReachable(); err != nil {
+		return nil, err
+	}
+
+	rel, err := g.cfg.releaseContent(name, g.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	layers := []chartutil.ValuesLayer{{Origin: "user-supplied", Values: rel.Config}}
+	_, origins, err := chartutil.ExplainValues(rel.Chart, rel.Config, layers)
+	return origins, err
+}
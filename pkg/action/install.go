@@ -19,6 +19,7 @@ package action
 import (
 	"bytes"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"os"
 	"path"
@@ -39,6 +40,7 @@ import (
 	"helm.sh/helm/v3/pkg/chartutil"
 	"helm.sh/helm/v3/pkg/cli"
 	"helm.sh/helm/v3/pkg/downloader"
+	"helm.sh/helm/v3/pkg/engine"
 	"helm.sh/helm/v3/pkg/getter"
 	"helm.sh/helm/v3/pkg/kube"
 	kubefake "helm.sh/helm/v3/pkg/kube/fake"
@@ -63,6 +65,11 @@ const releaseNameMaxLen = 53
 // since there can be filepath in front of it.
 const notesFileSuffix = "NOTES.txt"
 
+// outputsFileSuffix is the template holding the structured outputs of a chart.
+// Like NOTES.txt it goes through the templating engine, but its result is parsed
+// as YAML and stored on the release instead of being sent to Kubernetes.
+const outputsFileSuffix = "OUTPUTS.yaml"
+
 const defaultDirectoryPermission = 0755
 
 // Install performs an installation operation.
@@ -91,15 +98,60 @@ type Install struct {
 	SubNotes                 bool
 	DisableOpenAPIValidation bool
 	IncludeCRDs              bool
+	// SchemaDefaults applies the defaults of the values schemas of the chart.
+	SchemaDefaults bool
 	// APIVersions allows a manual set of supported API Versions to be passed
 	// (for things like templating). These are ignored if ClientOnly is false
 	APIVersions chartutil.VersionSet
+	// Capabilities, if set, replace the default capabilities when templating,
+	// or those of the cluster on a dry run, e.g. to render a chart as it would
+	// be in a cluster whose capabilities were exported. These are ignored if
+	// both ClientOnly and DryRun are false
+	Capabilities *chartutil.Capabilities
 	// Used by helm template to render charts with .Release.IsUpgrade. Ignored if Dry-Run is false
 	IsUpgrade bool
 	// Used by helm template to add the release as part of OutputDir path
 	// OutputDir/<ReleaseName>
 	UseReleaseName bool
 	PostRenderer   postrender.PostRenderer
+	// RenderSeed seeds the random template functions and RenderTime fixes the
+	// time of the date functions, so that rendering is reproducible.
+	RenderSeed *int64
+	RenderTime *time.Time
+	// LookupFixtures, if set, are queried by the lookup template function
+	// instead of the cluster, e.g. to render a dry run offline.
+	LookupFixtures *engine.Fixtures
+	// StrictValues fails rendering with every reference of the templates to a
+	// value which is not set, except for those in OptionalValues, see
+	// engine.Engine.
+	StrictValues   bool
+	OptionalValues []string
+	// DeprecatedAPIs, if set, replace chartutil.DefaultDeprecatedAPIs when
+	// warning about the APIs removed by the Kubernetes version of the cluster
+	// or its next minor version.
+	DeprecatedAPIs chartutil.DeprecatedAPIs
+	// Warnings, if set, receives the warnings about the rendered manifests.
+	Warnings io.Writer
+}
+
+// renderOptions are the options of the engine rendering a release, see
+// engine.Engine.
+type renderOptions struct {
+	seed           *int64
+	now            *time.Time
+	fixtures       *engine.Fixtures
+	strictValues   bool
+	optionalValues []string
+	// lintMode renders missing required values as empty, as lint does.
+	lintMode bool
+}
+
+// engine returns e with the options set.
+func (o renderOptions) engine(e engine.Engine) engine.Engine {
+	e.RenderSeed, e.RenderTime, e.LookupFixtures = o.seed, o.now, o.fixtures
+	e.StrictValues, e.OptionalValues = o.strictValues, o.optionalValues
+	e.LintMode = o.lintMode
+	return e
 }
 
 // ChartPathOptions captures common options used for controlling chart paths
@@ -194,8 +246,14 @@ func (i *Install) Run(chrt *chart.Chart, vals map[string]interface{}) (*release.
 	if i.ClientOnly {
 		// Add mock objects in here so it doesn't use Kube API server
 		// NOTE(bacongobbler): used for `helm template`
-		i.cfg.Capabilities = chartutil.DefaultCapabilities
-		i.cfg.Capabilities.APIVersions = append(i.cfg.Capabilities.APIVersions, i.APIVersions...)
+		caps := chartutil.DefaultCapabilities
+		if i.Capabilities != nil {
+			caps = i.Capabilities
+		}
+		i.cfg.Capabilities = &chartutil.Capabilities{
+			KubeVersion: caps.KubeVersion,
+			APIVersions: append(append(chartutil.VersionSet{}, caps.APIVersions...), i.APIVersions...),
+		}
 		i.cfg.KubeClient = &kubefake.PrintingKubeClient{Out: ioutil.Discard}
 
 		mem := driver.NewMemory()
@@ -204,6 +262,13 @@ func (i *Install) Run(chrt *chart.Chart, vals map[string]interface{}) (*release.
 	} else if !i.ClientOnly && len(i.APIVersions) > 0 {
 		i.cfg.Log("API Version list given outside of client only mode, this list will be ignored")
 	}
+	if !i.ClientOnly && i.Capabilities != nil {
+		if i.DryRun {
+			i.cfg.Capabilities = i.Capabilities
+		} else {
+			i.cfg.Log("Capabilities given outside of client only or dry run mode, they will be ignored")
+		}
+	}
 
 	if err := chartutil.ProcessDependencies(chrt, vals); err != nil {
 		return nil, err
@@ -221,11 +286,12 @@ func (i *Install) Run(chrt *chart.Chart, vals map[string]interface{}) (*release.
 	//special case for helm template --is-upgrade
 	isUpgrade := i.IsUpgrade && i.DryRun
 	options := chartutil.ReleaseOptions{
-		Name:      i.ReleaseName,
-		Namespace: i.Namespace,
-		Revision:  1,
-		IsInstall: !isUpgrade,
-		IsUpgrade: isUpgrade,
+		Name:           i.ReleaseName,
+		Namespace:      i.Namespace,
+		Revision:       1,
+		IsInstall:      !isUpgrade,
+		IsUpgrade:      isUpgrade,
+		SchemaDefaults: i.SchemaDefaults,
 	}
 	valuesToRender, err := chartutil.ToRenderValues(chrt, vals, options, caps)
 	if err != nil {
@@ -235,7 +301,7 @@ func (i *Install) Run(chrt *chart.Chart, vals map[string]interface{}) (*release.
 	rel := i.createRelease(chrt, vals)
 
 	var manifestDoc *bytes.Buffer
-	rel.Hooks, manifestDoc, rel.Info.Notes, err = i.cfg.renderResources(chrt, valuesToRender, i.ReleaseName, i.OutputDir, i.SubNotes, i.UseReleaseName, i.IncludeCRDs, i.PostRenderer, i.DryRun)
+	rel.Hooks, manifestDoc, rel.Info.Notes, rel.Info.Outputs, err = i.cfg.renderResources(chrt, valuesToRender, i.ReleaseName, i.OutputDir, i.SubNotes, i.UseReleaseName, i.IncludeCRDs, renderOptions{seed: i.RenderSeed, now: i.RenderTime, fixtures: i.LookupFixtures, strictValues: i.StrictValues, optionalValues: i.OptionalValues}, i.PostRenderer, i.DryRun)
 	// Even for errors, attach this if available
 	if manifestDoc != nil {
 		rel.Manifest = manifestDoc.String()
@@ -247,6 +313,8 @@ func (i *Install) Run(chrt *chart.Chart, vals map[string]interface{}) (*release.
 		return rel, err
 	}
 
+	i.cfg.warnDeprecatedAPIs(rel, i.DeprecatedAPIs, caps.KubeVersion, i.Warnings)
+
 	// Mark this release as in-progress
 	rel.SetStatus(release.StatusPendingInstall, "Initial install underway")
 
@@ -440,6 +508,7 @@ func (i *Install) createRelease(chrt *chart.Chart, rawVals map[string]interface{
 			FirstDeployed: ts,
 			LastDeployed:  ts,
 			Status:        release.StatusUnknown,
+			Audit:         i.cfg.audit(),
 		},
 		Version: 1,
 	}
@@ -31,6 +31,7 @@ import (
 	"helm.sh/helm/v3/internal/test"
 	"helm.sh/helm/v3/pkg/chart"
 	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/engine"
 	kubefake "helm.sh/helm/v3/pkg/kube/fake"
 	"helm.sh/helm/v3/pkg/release"
 	"helm.sh/helm/v3/pkg/storage/driver"
@@ -77,6 +78,31 @@ func TestInstallRelease(t *testing.T) {
 	is.Equal(rel.Info.Description, "Install complete")
 }
 
+func TestInstallReleaseWarnsAboutRemovedAPIs(t *testing.T) {
+	is := assert.New(t)
+	instAction := installAction(t)
+	instAction.DeprecatedAPIs = chartutil.DefaultDeprecatedAPIs.Merge(chartutil.DeprecatedAPIs{
+		{APIVersion: "example.com/v1beta1", Kind: "Widget", DeprecatedIn: "1.17", RemovedIn: "1.19"},
+		{APIVersion: "example.com/v1beta1", Kind: "Gadget", DeprecatedIn: "1.17", RemovedIn: "1.20"},
+	})
+	var warnings strings.Builder
+	instAction.Warnings = &warnings
+
+	chrt := buildChart()
+	chrt.Templates = append(chrt.Templates,
+		&chart.File{Name: "templates/deployment", Data: []byte("apiVersion: extensions/v1beta1\nkind: Deployment\nmetadata:\n  name: web\n")},
+		&chart.File{Name: "templates/widget", Data: []byte("apiVersion: example.com/v1beta1\nkind: Widget\nmetadata:\n  name: widget\n")},
+		&chart.File{Name: "templates/gadget", Data: []byte("apiVersion: example.com/v1beta1\nkind: Gadget\nmetadata:\n  name: gadget\n")},
+		&chart.File{Name: "templates/migrate", Data: []byte("apiVersion: extensions/v1beta1\nkind: DaemonSet\nmetadata:\n  name: migrate\n  annotations:\n    helm.sh/hook: pre-upgrade\n")},
+	)
+	_, err := instAction.Run(chrt, map[string]interface{}{})
+	is.NoError(err)
+	is.Equal(`WARNING: Deployment "web" uses extensions/v1beta1 Deployment, which is removed in Kubernetes 1.16, use apps/v1 Deployment instead
+WARNING: Widget "widget" uses example.com/v1beta1 Widget, which is removed in Kubernetes 1.19, the next minor version of the cluster
+WARNING: DaemonSet "migrate" uses extensions/v1beta1 DaemonSet, which is removed in Kubernetes 1.16, use apps/v1 DaemonSet instead
+`, warnings.String())
+}
+
 func TestInstallReleaseWithValues(t *testing.T) {
 	is := assert.New(t)
 	instAction := installAction(t)
@@ -160,6 +186,28 @@ func TestInstallRelease_WithNotes(t *testing.T) {
 	is.Equal(rel.Info.Notes, "note here")
 }
 
+func TestInstallRelease_WithOutputs(t *testing.T) {
+	is := assert.New(t)
+	instAction := installAction(t)
+	instAction.ReleaseName = "with-outputs"
+	vals := map[string]interface{}{}
+	res, err := instAction.Run(buildChart(withOutputs("host: {{ .Release.Name }}.{{ .Release.Namespace }}\nport: 5432")), vals)
+	if err != nil {
+		t.Fatalf("Failed install: %s", err)
+	}
+
+	rel, err := instAction.cfg.Releases.Get(res.Name, res.Version)
+	is.NoError(err)
+	is.Equal(map[string]interface{}{"host": "with-outputs.spaced", "port": float64(5432)}, rel.Info.Outputs)
+	is.NotContains(rel.Manifest, "OUTPUTS.yaml")
+}
+
+func TestInstallRelease_WithInvalidOutputs(t *testing.T) {
+	instAction := installAction(t)
+	_, err := instAction.Run(buildChart(withOutputs("- not\n- a map")), map[string]interface{}{})
+	assert.Error(t, err)
+}
+
 func TestInstallRelease_WithNotesRendered(t *testing.T) {
 	is := assert.New(t)
 	instAction := installAction(t)
@@ -184,7 +232,7 @@ func TestInstallRelease_WithChartAndDependencyParentNotes(t *testing.T) {
 	instAction := installAction(t)
 	instAction.ReleaseName = "with-notes"
 	vals := map[string]interface{}{}
-	res, err := instAction.Run(buildChart(withNotes("parent"), withDependency(withNotes("child"))), vals)
+	res, err := instAction.Run(buildChart(withNotes("parent"), withDependency(withTemplates(), withNotes("child"))), vals)
 	if err != nil {
 		t.Fatalf("Failed install: %s", err)
 	}
@@ -203,7 +251,7 @@ func TestInstallRelease_WithChartAndDependencyAllNotes(t *testing.T) {
 	instAction.ReleaseName = "with-notes"
 	instAction.SubNotes = true
 	vals := map[string]interface{}{}
-	res, err := instAction.Run(buildChart(withNotes("parent"), withDependency(withNotes("child"))), vals)
+	res, err := instAction.Run(buildChart(withNotes("parent"), withDependency(withTemplates(), withNotes("child"))), vals)
 	if err != nil {
 		t.Fatalf("Failed install: %s", err)
 	}
@@ -262,6 +310,32 @@ func TestInstallRelease_DryRun_Lookup(t *testing.T) {
 	is.Contains(res.Manifest, "goodbye: map[]")
 }
 
+func TestInstallRelease_DryRun_LookupFixtures(t *testing.T) {
+	is := assert.New(t)
+	instAction := installAction(t)
+	instAction.DryRun = true
+	fixtures, err := engine.NewFixtures(map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Namespace",
+		"metadata":   map[string]interface{}{"name": "spaced"},
+	})
+	is.NoError(err)
+	instAction.LookupFixtures = fixtures
+
+	mockChart := buildChart(withSampleTemplates())
+	mockChart.Templates = append(mockChart.Templates, &chart.File{
+		Name: "templates/lookup",
+		Data: []byte(`goodbye: {{ (lookup "v1" "Namespace" "" "spaced").metadata.name }}`),
+	})
+
+	res, err := instAction.Run(mockChart, map[string]interface{}{})
+	if err != nil {
+		t.Fatalf("Failed install: %s", err)
+	}
+
+	is.Contains(res.Manifest, "goodbye: spaced")
+}
+
 func TestInstallReleaseIncorrectTemplate_DryRun(t *testing.T) {
 	is := assert.New(t)
 	instAction := installAction(t)
@@ -346,6 +420,62 @@ func TestInstallRelease_KubeVersion(t *testing.T) {
 	is.Contains(err.Error(), "chart requires kubeVersion")
 }
 
+func TestInstallRelease_DuplicateObjects(t *testing.T) {
+	is := assert.New(t)
+	instAction := installAction(t)
+	vals := map[string]interface{}{}
+	_, err := instAction.Run(buildChart(withDependency()), vals)
+	is.Error(err)
+	is.Equal(`ConfigMap "test-cm" is rendered by both hello/charts/hello/templates/hooks and hello/templates/hooks`, err.Error())
+
+	instAction.ReleaseName = "duplicate-manifests"
+	configMap := "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: config\n  namespace: spaced\n"
+	_, err = instAction.Run(buildChart(withTemplates(
+		&chart.File{Name: "templates/config", Data: []byte(configMap)},
+		&chart.File{Name: "templates/more-config", Data: []byte("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: other\n---\n" + configMap)},
+	)), vals)
+	is.Error(err)
+	is.Equal(`ConfigMap "config" in namespace "spaced" is rendered by both hello/templates/config and hello/templates/more-config`, err.Error())
+}
+
+func TestInstallRelease_DryRun_Capabilities(t *testing.T) {
+	is := assert.New(t)
+	instAction := installAction(t)
+	instAction.DryRun = true
+	instAction.Capabilities = &chartutil.Capabilities{
+		KubeVersion: chartutil.KubeVersion{Version: "v1.16.2", Major: "1", Minor: "16"},
+		APIVersions: chartutil.VersionSet{"v1"},
+	}
+	vals := map[string]interface{}{}
+	_, err := instAction.Run(buildChart(withKube(">=1.18.0")), vals)
+	is.Error(err)
+	is.Contains(err.Error(), "incompatible with Kubernetes v1.16.2")
+
+	instAction.ReleaseName = "capabilities"
+	mockChart := buildChart(withKube(">=1.16.0"))
+	mockChart.Templates = append(mockChart.Templates, &chart.File{
+		Name: "templates/capabilities",
+		Data: []byte("kube: {{ .Capabilities.KubeVersion }}\napps: {{ .Capabilities.APIVersions.Has \"apps/v1\" }}"),
+	})
+	res, err := instAction.Run(mockChart, vals)
+	is.NoError(err)
+	is.Contains(res.Manifest, "kube: v1.16.2\napps: false")
+}
+
+func TestInstallRelease_DryRun_StrictValues(t *testing.T) {
+	is := assert.New(t)
+	instAction := installAction(t)
+	instAction.DryRun = true
+	instAction.StrictValues = true
+	vals := map[string]interface{}{}
+	_, err := instAction.Run(buildChart(withSampleIncludingIncorrectTemplates()), vals)
+	is.Error(err)
+	is.Contains(err.Error(), "hello/templates/incorrect:1: .Values.bad.doh")
+
+	_, err = instAction.Run(buildChart(withSampleTemplates()), vals)
+	is.NoError(err)
+}
+
 func TestInstallRelease_Wait(t *testing.T) {
 	is := assert.New(t)
 	instAction := installAction(t)
@@ -17,15 +17,21 @@ limitations under the License.
 package action
 
 import (
+	"fmt"
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 
+	"github.com/mitchellh/copystructure"
 	"github.com/pkg/errors"
 
+	"helm.sh/helm/v3/pkg/chart"
 	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/engine"
 	"helm.sh/helm/v3/pkg/lint"
+	"helm.sh/helm/v3/pkg/lint/rules"
 	"helm.sh/helm/v3/pkg/lint/support"
 )
 
@@ -34,8 +40,22 @@ import (
 // It provides the implementation of 'helm lint'.
 type Lint struct {
 	Strict        bool
-	Namespace     string
 	WithSubcharts bool
+	// Options are the options of the linters, such as the namespace and the
+	// capabilities the templates are rendered with. Unless Render is set, the
+	// charts are rendered as Install renders them.
+	lint.Options
+	// ValuesMatrix are patterns of values files, relative to the charts, e.g.
+	// "ci/*-values.yaml". Each chart is linted with each of its values files,
+	// merged below the given values.
+	ValuesMatrix []string
+	// KubeVersions are Kubernetes versions, e.g. "1.16". Each chart is linted
+	// with the capabilities of each version.
+	KubeVersions []string
+	// Subcharts are the paths of the charts linted as subcharts of others,
+	// with WithSubcharts. The values matrix patterns need not match their
+	// files.
+	Subcharts []string
 }
 
 // LintResult is the result of Lint
@@ -52,13 +72,15 @@ func NewLint() *Lint {
 
 // Run executes 'helm Lint' against the given chart.
 func (l *Lint) Run(paths []string, vals map[string]interface{}) *LintResult {
-	lowestTolerance := support.ErrorSev
-	if l.Strict {
-		lowestTolerance = support.WarningSev
-	}
+	lowestTolerance := l.FailureSeverity()
 	result := &LintResult{}
+	opts := l.Options
+	if opts.Render == nil {
+		opts.Render = lintRenderer(opts.LookupFixtures)
+	}
 	for _, path := range paths {
-		linter, err := lintChart(path, vals, l.Namespace, l.Strict)
+		matrix := lintMatrix{valuesPatterns: l.ValuesMatrix, kubeVersions: l.KubeVersions, subchart: l.isSubchart(path)}
+		linter, err := lintChart(path, vals, opts, matrix)
 		if err != nil {
 			result.Errors = append(result.Errors, err)
 			continue
@@ -75,7 +97,38 @@ func (l *Lint) Run(paths []string, vals map[string]interface{}) *LintResult {
 	return result
 }
 
-func lintChart(path string, vals map[string]interface{}, namespace string, strict bool) (support.Linter, error) {
+// lintRenderer renders charts for lint as Install renders them, in lint mode
+// and reproducibly, without talking to a cluster. The lookup function queries
+// fixtures, if set.
+func lintRenderer(fixtures *engine.Fixtures) rules.ReleaseRenderer {
+	return func(ch *chart.Chart, values chartutil.Values, caps *chartutil.Capabilities) (*rules.RenderedRelease, error) {
+		cfg := &Configuration{Capabilities: caps}
+		var seed int64
+		now := engine.DefaultRenderTime
+		r, err := cfg.renderManifests(ch, values, true, renderOptions{seed: &seed, now: &now, fixtures: fixtures, lintMode: true}, true)
+		return &rules.RenderedRelease{Files: r.rendered, Hooks: r.hooks, Manifests: r.manifests}, err
+	}
+}
+
+func (l *Lint) isSubchart(path string) bool {
+	for _, p := range l.Subcharts {
+		if p == path {
+			return true
+		}
+	}
+	return false
+}
+
+// FailureSeverity returns the lowest severity of the messages failing a
+// chart.
+func (l *Lint) FailureSeverity() int {
+	if l.Strict {
+		return support.WarningSev
+	}
+	return support.ErrorSev
+}
+
+func lintChart(path string, vals map[string]interface{}, opts lint.Options, matrix lintMatrix) (support.Linter, error) {
 	var chartPath string
 	linter := support.Linter{}
 
@@ -114,5 +167,147 @@ func lintChart(path string, vals map[string]interface{}, namespace string, stric
 		return linter, errors.Wrap(err, "unable to check Chart.yaml file in chart")
 	}
 
-	return lint.All(chartPath, vals, namespace, strict), nil
+	return matrix.lint(chartPath, vals, opts)
+}
+
+// lintMatrix is the values files and Kubernetes versions a chart is linted
+// with, in every combination.
+type lintMatrix struct {
+	valuesPatterns []string
+	kubeVersions   []string
+	// subchart is set for the subcharts of the linted charts, which need not
+	// have values files matching the patterns.
+	subchart bool
+}
+
+// lintCombination is a configuration a chart is linted with.
+type lintCombination struct {
+	name   string
+	values map[string]interface{}
+	caps   *chartutil.Capabilities
+}
+
+func (m lintMatrix) combinations(chartPath string, vals map[string]interface{}, caps *chartutil.Capabilities) ([]lintCombination, error) {
+	var files []string
+	seen := map[string]bool{}
+	for _, pattern := range m.valuesPatterns {
+		if !filepath.IsAbs(pattern) {
+			pattern = filepath.Join(chartPath, pattern)
+		}
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid values matrix pattern %q", pattern)
+		}
+		// A pattern matching nothing, e.g. because of a typo, would silently
+		// lint the chart with the given values only.
+		if len(matches) == 0 && !m.subchart {
+			return nil, errors.Errorf("values matrix pattern %q matches no files", pattern)
+		}
+		for _, f := range matches {
+			if !seen[f] {
+				seen[f] = true
+				files = append(files, f)
+			}
+		}
+	}
+	sort.Strings(files)
+
+	// Without values files, the chart is linted with the given values only,
+	// and without Kubernetes versions, with the given capabilities.
+	valuesCombinations := []lintCombination{{values: vals}}
+	if len(files) > 0 {
+		valuesCombinations = nil
+	}
+	for _, f := range files {
+		fileVals, err := chartutil.ReadValuesFile(f)
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to read values matrix")
+		}
+		copied, err := copystructure.Copy(vals)
+		if err != nil {
+			return nil, err
+		}
+		name, err := filepath.Rel(chartPath, f)
+		if err != nil {
+			name = f
+		}
+		valuesCombinations = append(valuesCombinations, lintCombination{
+			name:   "values " + filepath.ToSlash(name),
+			values: chartutil.CoalesceTables(copied.(map[string]interface{}), fileVals),
+		})
+	}
+
+	var combinations []lintCombination
+	for _, c := range valuesCombinations {
+		if len(m.kubeVersions) == 0 {
+			c.caps = caps
+			combinations = append(combinations, c)
+			continue
+		}
+		for _, v := range m.kubeVersions {
+			kubeVersion, err := chartutil.ParseKubeVersion(v)
+			if err != nil {
+				return nil, err
+			}
+			versionCaps := *chartutil.DefaultCapabilities
+			if caps != nil {
+				versionCaps = *caps
+			}
+			versionCaps.KubeVersion = kubeVersion
+
+			name := "Kubernetes " + kubeVersion.Version
+			if c.name != "" {
+				name = c.name + ", " + name
+			}
+			combinations = append(combinations, lintCombination{name: name, values: c.values, caps: &versionCaps})
+		}
+	}
+	return combinations, nil
+}
+
+// lint lints a chart with each combination of the matrix. The messages
+// produced by several combinations are reported once, with the combinations
+// producing them unless all of them do.
+func (m lintMatrix) lint(chartPath string, vals map[string]interface{}, opts lint.Options) (support.Linter, error) {
+	if len(m.valuesPatterns) == 0 && len(m.kubeVersions) == 0 {
+		return lint.AllWithOptions(chartPath, vals, opts), nil
+	}
+	combinations, err := m.combinations(chartPath, vals, opts.Capabilities)
+	if err != nil {
+		return support.Linter{}, err
+	}
+
+	var linter support.Linter
+	index := map[string]int{}
+	for _, c := range combinations {
+		// A combination may produce a message several times.
+		produced := map[int]bool{}
+		o := opts
+		o.Capabilities = c.caps
+		l := lint.AllWithOptions(chartPath, c.values, o)
+		linter.ChartDir = l.ChartDir
+		if l.HighestSeverity > linter.HighestSeverity {
+			linter.HighestSeverity = l.HighestSeverity
+		}
+		for _, msg := range l.Messages {
+			key := fmt.Sprintf("%d\x00%s\x00%s\x00%s", msg.Severity, msg.RuleID, msg.Path, msg.Err)
+			i, ok := index[key]
+			if !ok {
+				i = len(linter.Messages)
+				index[key] = i
+				linter.Messages = append(linter.Messages, msg)
+			}
+			if c.name != "" && !produced[i] {
+				produced[i] = true
+				linter.Messages[i].Contexts = append(linter.Messages[i].Contexts, c.name)
+			}
+		}
+	}
+	// Messages produced by every combination do not depend on it.
+	for i, msg := range linter.Messages {
+		if len(msg.Contexts) == len(combinations) {
+			linter.Messages[i].Contexts = nil
+		}
+	}
+	return linter, nil
 }
@@ -18,12 +18,19 @@ package action
 
 import (
 	"testing"
+
+	"github.com/pkg/errors"
+
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/lint"
+	"helm.sh/helm/v3/pkg/lint/rules"
+	"helm.sh/helm/v3/pkg/lint/support"
 )
 
 var (
 	values                  = make(map[string]interface{})
 	namespace               = "testNamespace"
-	strict                  = false
 	chart1MultipleChartLint = "testdata/charts/multiplecharts-lint-chart-1"
 	chart2MultipleChartLint = "testdata/charts/multiplecharts-lint-chart-2"
 	corruptedTgzChart       = "testdata/charts/corrupted-compressed-chart.tgz"
@@ -78,7 +85,7 @@ func TestLintChart(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			_, err := lintChart(tt.chartPath, map[string]interface{}{}, namespace, strict)
+			_, err := lintChart(tt.chartPath, map[string]interface{}{}, lint.Options{TemplateOptions: rules.TemplateOptions{Namespace: namespace}}, lintMatrix{})
 			switch {
 			case err != nil && !tt.err:
 				t.Errorf("%s", err)
@@ -158,3 +165,61 @@ func TestLint_ChartWithWarnings(t *testing.T) {
 		}
 	})
 }
+
+func TestLintRenderer(t *testing.T) {
+	rel, err := lintRenderer(nil)(buildChart(withSampleTemplates(), withNotes("notes")), nil, chartutil.DefaultCapabilities)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rel.Hooks) != 1 || len(rel.Manifests) != 3 {
+		t.Errorf("expected 1 hook and 3 manifests, got %d and %d", len(rel.Hooks), len(rel.Manifests))
+	}
+	if rel.Files["hello/templates/NOTES.txt"] != "notes" {
+		t.Errorf("expected the rendered files to include the notes, got %v", rel.Files)
+	}
+
+	ch := buildChart()
+	ch.Templates = append(ch.Templates, &chart.File{Name: "templates/list", Data: []byte("kind: ConfigMap\n---\n- not an object\n")})
+	rel, err = lintRenderer(nil)(ch, nil, chartutil.DefaultCapabilities)
+	if err == nil {
+		t.Error("expected an error sorting a manifest which is not an object")
+	}
+	if rel.Files["hello/templates/list"] == "" {
+		t.Error("expected the rendered files with the error sorting them")
+	}
+}
+
+// onceChecker reports the same message twice the first time it is run.
+type onceChecker struct {
+	runs int
+}
+
+func (c *onceChecker) Rules() []support.Rule {
+	return []support.Rule{{ID: "T001", Name: "once", Severity: support.WarningSev}}
+}
+
+func (c *onceChecker) Check(*support.RenderedChart) ([]support.Message, error) {
+	c.runs++
+	if c.runs > 1 {
+		return nil, nil
+	}
+	m := support.Message{RuleID: "T001", Path: "templates/", Err: errors.New("first run")}
+	return []support.Message{m, m}, nil
+}
+
+func TestLint_MatrixContexts(t *testing.T) {
+	testLint := NewLint()
+	testLint.KubeVersions = []string{"1.16", "1.17"}
+	testLint.Checkers = []support.Checker{&onceChecker{}}
+	result := testLint.Run([]string{chart1MultipleChartLint}, values)
+	for _, m := range result.Messages {
+		if m.RuleID != "T001" {
+			continue
+		}
+		if len(m.Contexts) != 1 || m.Contexts[0] != "Kubernetes v1.16.0" {
+			t.Errorf("expected the message of the first Kubernetes version only, got %v", m.Contexts)
+		}
+		return
+	}
+	t.Errorf("expected a message of the checker, got %v", result.Messages)
+}
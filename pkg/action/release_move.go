@@ -0,0 +1,210 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"bytes"
+	"sort"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/yaml"
+
+	"helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/releaseutil"
+)
+
+// ReleaseMove is the action for moving a release to another namespace.
+//
+// It provides the implementation of 'helm release move'.
+type ReleaseMove struct {
+	cfg *Configuration
+
+	// Target is the configuration for the destination namespace. Its storage
+	// driver receives the release history, and its Kubernetes client is used
+	// to recreate namespaced resources unless KeepResources is set.
+	Target *Configuration
+	// Namespace is the namespace the release is moved to.
+	Namespace string
+	// KeepResources leaves namespaced resources where they are and only
+	// transfers their ownership to the moved release.
+	KeepResources bool
+	DryRun        bool
+}
+
+// NewReleaseMove creates a new ReleaseMove object with the given configuration.
+func NewReleaseMove(cfg *Configuration) *ReleaseMove {
+	return &ReleaseMove{
+		cfg: cfg,
+	}
+}
+
+// Run executes 'helm release move' against the given release.
+func (m *ReleaseMove) Run(name string) (*release.Release, error) {
+	if m.Target == nil {
+		return nil, errors.New("release move: no target configuration provided")
+	}
+	if m.Namespace == "" {
+		return nil, errors.New("release move: no target namespace provided")
+	}
+	if err := m.cfg.KubeClient.IsReachable(); err != nil {
+		return nil, err
+	}
+	if err := validateReleaseName(name); err != nil {
+		return nil, errors.Errorf("release move: Release name is invalid: %s", name)
+	}
+
+	return relocateRelease(m.cfg, m.Target, name, name, m.Namespace, !m.KeepResources, m.DryRun)
+}
+
+// relocateRelease copies the history of release name from src to dst as
+// newName in newNamespace, transfers ownership of the deployed resources and
+// finally removes the original history from src. An empty newNamespace keeps
+// the namespace of the release.
+//
+// If relocate is true, namespaced resources are recreated in newNamespace using
+// the Kubernetes client of dst and removed from their original namespace.
+func relocateRelease(src, dst *Configuration, name, newName, newNamespace string, relocate, dryRun bool) (*release.Release, error) {
+	rels, err := src.Releases.History(name)
+	if err != nil {
+		return nil, errors.Wrapf(err, "release %q could not be loaded", name)
+	}
+	if len(rels) < 1 {
+		return nil, errMissingRelease
+	}
+	releaseutil.SortByRevision(rels)
+	last := rels[len(rels)-1]
+
+	switch last.Info.Status {
+	case release.StatusPendingInstall, release.StatusPendingUpgrade, release.StatusPendingRollback, release.StatusUninstalling:
+		return nil, errors.Errorf("release %q has an operation in progress (status %q)", name, last.Info.Status)
+	}
+
+	if newNamespace == "" {
+		newNamespace = last.Namespace
+	}
+
+	if h, err := dst.Releases.History(newName); err == nil && len(h) > 0 {
+		return nil, errors.Errorf("release %q already exists in namespace %q", newName, newNamespace)
+	}
+
+	if relocate && last.Info.Status != release.StatusUninstalled {
+		if err := checkRelocatable(last.Manifest, newNamespace); err != nil {
+			return nil, err
+		}
+	}
+
+	moved := make([]*release.Release, 0, len(rels))
+	for _, r := range rels {
+		rel := *r
+		rel.Name = newName
+		rel.Namespace = newNamespace
+		moved = append(moved, &rel)
+	}
+	result := moved[len(moved)-1]
+
+	if dryRun {
+		src.Log("dry run for moving %s to %s/%s", name, newNamespace, newName)
+		return result, nil
+	}
+
+	src.Log("copying %d revision(s) of %s to %s/%s", len(moved), name, newNamespace, newName)
+	var created []*release.Release
+	for _, rel := range moved {
+		if err := dst.Releases.Create(rel); err != nil {
+			removeReleases(dst, created)
+			return nil, errors.Wrapf(err, "could not store revision %d of release %q", rel.Version, newName)
+		}
+		created = append(created, rel)
+	}
+
+	if last.Info.Status != release.StatusUninstalled {
+		if err := transferResources(src, dst, last, newName, newNamespace, relocate); err != nil {
+			removeReleases(dst, created)
+			return nil, err
+		}
+	}
+
+	src.Log("removing original history of %s", name)
+	for _, r := range rels {
+		if _, err := src.Releases.Delete(r.Name, r.Version); err != nil {
+			return result, errors.Wrapf(err, "release was moved but revision %d of %q could not be removed", r.Version, name)
+		}
+	}
+
+	return result, nil
+}
+
+// transferResources relabels the resources of rel so that they are owned by
+// the release newName in newNamespace.
+func transferResources(src, dst *Configuration, rel *release.Release, newName, newNamespace string, relocate bool) error {
+	current, err := src.KubeClient.Build(bytes.NewBufferString(rel.Manifest), false)
+	if err != nil {
+		return errors.Wrap(err, "unable to build kubernetes objects from release manifest")
+	}
+
+	kc := src.KubeClient
+	if relocate {
+		kc = dst.KubeClient
+	}
+	target, err := kc.Build(bytes.NewBufferString(rel.Manifest), false)
+	if err != nil {
+		return errors.Wrap(err, "unable to build kubernetes objects from release manifest")
+	}
+	if err := target.Visit(setMetadataVisitor(newName, newNamespace, true)); err != nil {
+		return err
+	}
+
+	if _, err := src.KubeClient.Update(current, target, false); err != nil {
+		return errors.Wrapf(err, "unable to transfer resources of release %q", rel.Name)
+	}
+	return nil
+}
+
+// checkRelocatable returns an error if an object of manifest sets a namespace
+// other than newNamespace. Such an object would be left where it is while the
+// release is moved, as its namespace comes from the chart rather than from
+// the release.
+func checkRelocatable(manifest, newNamespace string) error {
+	manifests := releaseutil.SplitManifests(manifest)
+	keys := make([]string, 0, len(manifests))
+	for k := range manifests {
+		keys = append(keys, k)
+	}
+	sort.Sort(releaseutil.BySplitManifestsOrder(keys))
+
+	for _, k := range keys {
+		var head releaseutil.SimpleHead
+		if err := yaml.Unmarshal([]byte(manifests[k]), &head); err != nil {
+			return errors.Wrap(err, "unable to parse release manifest")
+		}
+		if head.Metadata == nil || head.Metadata.Namespace == "" || head.Metadata.Namespace == newNamespace {
+			continue
+		}
+		id := releaseutil.ObjectID{Kind: head.Kind, Namespace: head.Metadata.Namespace, Name: head.Metadata.Name}
+		return errors.Errorf("%s sets its namespace explicitly and cannot be moved to namespace %q; keep the resources in place to move the release", id, newNamespace)
+	}
+	return nil
+}
+
+// removeReleases deletes the given revisions from storage, logging any failure.
+func removeReleases(cfg *Configuration, rels []*release.Release) {
+	for _, rel := range rels {
+		if _, err := cfg.Releases.Delete(rel.Name, rel.Version); err != nil {
+			cfg.Log("warning: could not remove revision %d of %s: %s", rel.Version, rel.Name, err)
+		}
+	}
+}
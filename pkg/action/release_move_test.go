@@ -0,0 +1,160 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	kubefake "helm.sh/helm/v3/pkg/kube/fake"
+	"helm.sh/helm/v3/pkg/release"
+)
+
+func releaseHistoryFixture(t *testing.T, cfg *Configuration, name, namespace string) {
+	t.Helper()
+	for i, status := range []release.Status{release.StatusSuperseded, release.StatusDeployed} {
+		rel := namedReleaseStub(name, status)
+		rel.Namespace = namespace
+		rel.Version = i + 1
+		if err := cfg.Releases.Create(rel); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestReleaseRename(t *testing.T) {
+	is := assert.New(t)
+	req := require.New(t)
+
+	cfg := actionConfigFixture(t)
+	releaseHistoryFixture(t, cfg, "old-name", "spaced")
+
+	res, err := NewReleaseRename(cfg).Run("old-name", "new-name")
+	req.NoError(err)
+	is.Equal("new-name", res.Name)
+	is.Equal("spaced", res.Namespace)
+	is.Equal(2, res.Version)
+
+	h, err := cfg.Releases.History("new-name")
+	req.NoError(err)
+	is.Len(h, 2)
+
+	_, err = cfg.Releases.History("old-name")
+	is.Error(err)
+}
+
+func TestReleaseRename_Existing(t *testing.T) {
+	cfg := actionConfigFixture(t)
+	releaseHistoryFixture(t, cfg, "old-name", "spaced")
+	releaseHistoryFixture(t, cfg, "new-name", "spaced")
+
+	_, err := NewReleaseRename(cfg).Run("old-name", "new-name")
+	assert.Error(t, err)
+
+	h, err := cfg.Releases.History("old-name")
+	require.NoError(t, err)
+	assert.Len(t, h, 2)
+}
+
+func TestReleaseRename_DryRun(t *testing.T) {
+	cfg := actionConfigFixture(t)
+	releaseHistoryFixture(t, cfg, "old-name", "spaced")
+
+	client := NewReleaseRename(cfg)
+	client.DryRun = true
+	_, err := client.Run("old-name", "new-name")
+	require.NoError(t, err)
+
+	_, err = cfg.Releases.History("new-name")
+	assert.Error(t, err)
+}
+
+func TestReleaseMove(t *testing.T) {
+	is := assert.New(t)
+	req := require.New(t)
+
+	cfg := actionConfigFixture(t)
+	releaseHistoryFixture(t, cfg, "mover", "source")
+	target := actionConfigFixture(t)
+
+	client := NewReleaseMove(cfg)
+	client.Target = target
+	client.Namespace = "target"
+	res, err := client.Run("mover")
+	req.NoError(err)
+	is.Equal("mover", res.Name)
+	is.Equal("target", res.Namespace)
+
+	h, err := target.Releases.History("mover")
+	req.NoError(err)
+	is.Len(h, 2)
+	for _, rel := range h {
+		is.Equal("target", rel.Namespace)
+	}
+
+	_, err = cfg.Releases.History("mover")
+	is.Error(err)
+}
+
+func TestReleaseMove_UpdateFailure(t *testing.T) {
+	cfg := actionConfigFixture(t)
+	releaseHistoryFixture(t, cfg, "mover", "source")
+	target := actionConfigFixture(t)
+
+	failer := cfg.KubeClient.(*kubefake.FailingKubeClient)
+	failer.UpdateError = fmt.Errorf("I refuse to move")
+
+	client := NewReleaseMove(cfg)
+	client.Target = target
+	client.Namespace = "target"
+	_, err := client.Run("mover")
+	assert.Error(t, err)
+
+	// Neither side may be left half moved.
+	_, err = target.Releases.History("mover")
+	assert.Error(t, err)
+	h, err := cfg.Releases.History("mover")
+	require.NoError(t, err)
+	assert.Len(t, h, 2)
+}
+
+func TestReleaseMove_ExplicitNamespace(t *testing.T) {
+	cfg := actionConfigFixture(t)
+	releaseHistoryFixture(t, cfg, "mover", "source")
+	last, err := cfg.Releases.Last("mover")
+	require.NoError(t, err)
+	last.Manifest = "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: settings\n  namespace: source\n"
+	require.NoError(t, cfg.Releases.Update(last))
+	target := actionConfigFixture(t)
+
+	client := NewReleaseMove(cfg)
+	client.Target = target
+	client.Namespace = "target"
+	client.DryRun = true
+	_, err = client.Run("mover")
+	assert.EqualError(t, err, `ConfigMap "settings" in namespace "source" sets its namespace explicitly and cannot be moved to namespace "target"; keep the resources in place to move the release`)
+
+	// Objects left in place need not be in the namespace of the release.
+	client.DryRun = false
+	client.KeepResources = true
+	res, err := client.Run("mover")
+	require.NoError(t, err)
+	assert.Equal(t, "target", res.Namespace)
+}
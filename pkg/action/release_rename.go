@@ -0,0 +1,57 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"github.com/pkg/errors"
+
+	"helm.sh/helm/v3/pkg/release"
+)
+
+// ReleaseRename is the action for renaming a release.
+//
+// It provides the implementation of 'helm release rename'.
+type ReleaseRename struct {
+	cfg *Configuration
+
+	DryRun bool
+}
+
+// NewReleaseRename creates a new ReleaseRename object with the given configuration.
+func NewReleaseRename(cfg *Configuration) *ReleaseRename {
+	return &ReleaseRename{
+		cfg: cfg,
+	}
+}
+
+// Run executes 'helm release rename' against the given release.
+func (r *ReleaseRename) Run(name, newName string) (*release.Release, error) {
+	if err := r.cfg.KubeClient.IsReachable(); err != nil {
+		return nil, err
+	}
+	if err := validateReleaseName(name); err != nil {
+		return nil, errors.Errorf("release rename: Release name is invalid: %s", name)
+	}
+	if err := validateReleaseName(newName); err != nil {
+		return nil, errors.Errorf("release rename: Release name is invalid: %s", newName)
+	}
+	if name == newName {
+		return nil, errors.Errorf("release %q is already named %q", name, newName)
+	}
+
+	return relocateRelease(r.cfg, r.cfg, name, newName, "", false, r.DryRun)
+}
@@ -123,9 +123,11 @@ func (r *Rollback) prepareRollback(name string) (*release.Release, *release.Rele
 			LastDeployed:  helmtime.Now(),
 			Status:        release.StatusPendingRollback,
 			Notes:         previousRelease.Info.Notes,
+			Outputs:       previousRelease.Info.Outputs,
 			// Because we lose the reference to previous version elsewhere, we set the
 			// message here, and only override it later if we experience failure.
 			Description: fmt.Sprintf("Rollback to %d", previousVersion),
+			Audit:       r.cfg.audit(),
 		},
 		Version:  currentRelease.Version + 1,
 		Manifest: previousRelease.Manifest,
@@ -0,0 +1,87 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+
+	"github.com/pkg/errors"
+
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/chartutil"
+)
+
+// SchemaGenerate is the action for generating the values schema of a chart.
+//
+// It provides the implementation of 'helm schema generate'.
+type SchemaGenerate struct {
+	// Check compares the generated schema with the schema file of the chart
+	// instead of writing it, and fails if they differ.
+	Check bool
+}
+
+// NewSchemaGenerate creates a new SchemaGenerate object.
+func NewSchemaGenerate() *SchemaGenerate {
+	return &SchemaGenerate{}
+}
+
+// Run generates the values schema of the chart in the directory chartPath and
+// writes it to the values.schema.json file of the chart. It returns the path of
+// the schema file.
+func (s *SchemaGenerate) Run(chartPath string) (string, error) {
+	if fi, err := os.Stat(chartPath); err != nil {
+		return "", err
+	} else if !fi.IsDir() {
+		return "", errors.Errorf("%s is not a chart directory", chartPath)
+	}
+
+	chrt, err := loader.LoadDir(chartPath)
+	if err != nil {
+		return "", err
+	}
+	schema, err := chartutil.GenerateSchema(chrt)
+	if err != nil {
+		return "", errors.Wrap(err, "unable to generate schema")
+	}
+
+	schemaPath := filepath.Join(chartPath, chartutil.SchemafileName)
+	if !s.Check {
+		return schemaPath, ioutil.WriteFile(schemaPath, schema, 0644)
+	}
+
+	current, err := ioutil.ReadFile(schemaPath)
+	if os.IsNotExist(err) {
+		return schemaPath, errors.Errorf("%s does not exist", schemaPath)
+	} else if err != nil {
+		return schemaPath, err
+	}
+	var want, got interface{}
+	if err := json.Unmarshal(schema, &want); err != nil {
+		return schemaPath, err
+	}
+	if err := json.Unmarshal(current, &got); err != nil {
+		return schemaPath, errors.Wrapf(err, "invalid JSON in %s", schemaPath)
+	}
+	if !reflect.DeepEqual(want, got) {
+		return schemaPath, errors.Errorf("%s is out of date, run 'helm schema generate' to update it", schemaPath)
+	}
+	return schemaPath, nil
+}
@@ -107,6 +107,25 @@ func (s *Show) Run(chartpath string) (string, error) {
 	return out.String(), nil
 }
 
+// ExplainValues computes the values of the given chart together with the user
+// supplied values and reports where each of them came from. layers describe the
+// sources vals were merged from, see chartutil.ExplainValues.
+func (s *Show) ExplainValues(chartpath string, vals map[string]interface{}, layers []chartutil.ValuesLayer) ([]chartutil.ValueOrigin, error) {
+	if s.chart == nil {
+		chrt, err := loader.Load(chartpath)
+		if err != nil {
+			return nil, err
+		}
+		s.chart = chrt
+	}
+
+	if err := chartutil.ProcessDependencies(s.chart, vals); err != nil {
+		return nil, err
+	}
+	_, origins, err := chartutil.ExplainValues(s.chart, vals, layers)
+	return origins, err
+}
+
 func findReadme(files []*chart.File) (file *chart.File) {
 	for _, file := range files {
 		for _, n := range readmeFileNames {
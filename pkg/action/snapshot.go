@@ -0,0 +1,242 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/pmezard/go-difflib/difflib"
+	"sigs.k8s.io/yaml"
+
+	"helm.sh/helm/v3/pkg/releaseutil"
+)
+
+// snapshotSuffix is the file name suffix of snapshot files.
+const snapshotSuffix = ".yaml"
+
+var (
+	sourceComment       = regexp.MustCompile(`(?m)^# Source: (.+)$`)
+	unsafeFileNameChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+)
+
+// ManifestSnapshot compares rendered manifests with snapshot files in a
+// directory, one file per resource.
+//
+// It provides the implementation of 'helm template --snapshot-dir'.
+type ManifestSnapshot struct {
+	// Dir is the directory holding the snapshot files.
+	Dir string
+	// Check compares the manifests with the snapshots without writing any
+	// file. Missing snapshots are reported as differences.
+	Check bool
+	// Update writes the snapshots of changed manifests and removes snapshots
+	// of resources which are no longer rendered.
+	Update bool
+}
+
+// SnapshotResult is the result of ManifestSnapshot. File names are relative
+// to the snapshot directory.
+type SnapshotResult struct {
+	Unchanged []string
+	Written   []string
+	Updated   []string
+	Removed   []string
+	// Diffs are the differences between the snapshots and the manifests
+	// which were not written.
+	Diffs []SnapshotDiff
+}
+
+// SnapshotDiff is the difference between a snapshot file and a manifest.
+type SnapshotDiff struct {
+	File string
+	// Diff is a unified diff from the snapshot to the manifest.
+	Diff string
+}
+
+// NewManifestSnapshot creates a new ManifestSnapshot object for the given
+// snapshot directory.
+func NewManifestSnapshot(dir string) *ManifestSnapshot {
+	return &ManifestSnapshot{Dir: dir}
+}
+
+// Run compares the manifests, a stream of YAML documents, with the snapshots.
+// New snapshots are written unless checking, changed ones only when updating.
+func (s *ManifestSnapshot) Run(manifests string) (*SnapshotResult, error) {
+	if s.Check && s.Update {
+		return nil, errors.New("snapshots cannot be checked and updated at the same time")
+	}
+
+	files, err := SnapshotFiles(manifests)
+	if err != nil {
+		return nil, err
+	}
+	existing, err := s.existing()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &SnapshotResult{}
+	var names []string
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		got := files[name]
+		want, ok := existing[name]
+		switch {
+		case ok && want == got:
+			result.Unchanged = append(result.Unchanged, name)
+			continue
+		case ok && s.Update:
+			result.Updated = append(result.Updated, name)
+		case !ok && !s.Check:
+			result.Written = append(result.Written, name)
+		default:
+			result.Diffs = append(result.Diffs, s.diff(name, want, got))
+			continue
+		}
+		if err := s.write(name, got); err != nil {
+			return result, err
+		}
+	}
+
+	var obsolete []string
+	for name := range existing {
+		if _, ok := files[name]; !ok {
+			obsolete = append(obsolete, name)
+		}
+	}
+	sort.Strings(obsolete)
+	for _, name := range obsolete {
+		if !s.Update {
+			result.Diffs = append(result.Diffs, s.diff(name, existing[name], ""))
+			continue
+		}
+		if err := os.Remove(filepath.Join(s.Dir, name)); err != nil {
+			return result, err
+		}
+		result.Removed = append(result.Removed, name)
+	}
+	return result, nil
+}
+
+// existing reads the snapshot files in the snapshot directory.
+func (s *ManifestSnapshot) existing() (map[string]string, error) {
+	entries, err := ioutil.ReadDir(s.Dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	files := map[string]string{}
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), snapshotSuffix) {
+			continue
+		}
+		data, err := ioutil.ReadFile(filepath.Join(s.Dir, e.Name()))
+		if err != nil {
+			return nil, err
+		}
+		files[e.Name()] = string(data)
+	}
+	return files, nil
+}
+
+func (s *ManifestSnapshot) write(name, content string) error {
+	if err := os.MkdirAll(s.Dir, 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filepath.Join(s.Dir, name), []byte(content), 0644)
+}
+
+func (s *ManifestSnapshot) diff(name, want, got string) SnapshotDiff {
+	file := filepath.Join(s.Dir, name)
+	diff, _ := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        splitLines(want),
+		B:        splitLines(got),
+		FromFile: file + " (snapshot)",
+		ToFile:   file + " (rendered)",
+		Context:  3,
+	})
+	return SnapshotDiff{File: name, Diff: diff}
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return difflib.SplitLines(strings.TrimSuffix(s, "\n"))
+}
+
+// SnapshotFiles splits a stream of YAML documents into snapshot files, one
+// per document, by file name. A file is named after the kind and name of its
+// resource, e.g. "deployment-web.yaml", or after its template for documents
+// which are not resources. Documents are expected in a deterministic order,
+// as output by releaseutil.SortManifests, so that clashing names are
+// numbered the same way every time.
+func SnapshotFiles(manifests string) (map[string]string, error) {
+	docs := releaseutil.SplitManifests(manifests)
+	keys := make([]string, 0, len(docs))
+	for k := range docs {
+		keys = append(keys, k)
+	}
+	sort.Sort(releaseutil.BySplitManifestsOrder(keys))
+
+	files := map[string]string{}
+	for _, k := range keys {
+		doc := docs[k]
+		var head releaseutil.SimpleHead
+		if err := yaml.Unmarshal([]byte(doc), &head); err != nil {
+			return nil, errors.Wrapf(err, "YAML parse error on %s", snapshotSource(doc))
+		}
+
+		var base string
+		if head.Kind != "" && head.Metadata != nil && head.Metadata.Name != "" {
+			base = strings.ToLower(head.Kind) + "-" + head.Metadata.Name
+		} else if source := snapshotSource(doc); source != "" {
+			base = strings.TrimSuffix(path.Base(source), path.Ext(source))
+		} else {
+			base = "manifest"
+		}
+		base = unsafeFileNameChars.ReplaceAllString(base, "_")
+
+		name := base + snapshotSuffix
+		for i := 2; files[name] != ""; i++ {
+			name = fmt.Sprintf("%s-%d%s", base, i, snapshotSuffix)
+		}
+		files[name] = doc + "\n"
+	}
+	return files, nil
+}
+
+// snapshotSource returns the template a document was rendered from.
+func snapshotSource(doc string) string {
+	if m := sourceComment.FindStringSubmatch(doc); m != nil {
+		return strings.TrimSpace(m[1])
+	}
+	return ""
+}
@@ -0,0 +1,110 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const snapshotManifests = `---
+# Source: web/templates/service.yaml
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+---
+# Source: web/templates/service.yaml
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+---
+# Source: web/templates/raw.yaml
+answer: 42
+`
+
+func TestSnapshotFiles(t *testing.T) {
+	files, err := SnapshotFiles(snapshotManifests)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"service-web.yaml":   "# Source: web/templates/service.yaml\napiVersion: v1\nkind: Service\nmetadata:\n  name: web\n",
+		"service-web-2.yaml": "# Source: web/templates/service.yaml\napiVersion: v1\nkind: Service\nmetadata:\n  name: web\n",
+		"raw.yaml":           "# Source: web/templates/raw.yaml\nanswer: 42\n",
+	}, files)
+}
+
+func TestManifestSnapshot(t *testing.T) {
+	is := assert.New(t)
+	req := require.New(t)
+
+	tmp, err := ioutil.TempDir("", "helm-snapshot-")
+	req.NoError(err)
+	defer os.RemoveAll(tmp)
+	dir := filepath.Join(tmp, "snapshots")
+
+	// Checking does not write missing snapshots.
+	s := NewManifestSnapshot(dir)
+	s.Check = true
+	res, err := s.Run(snapshotManifests)
+	req.NoError(err)
+	is.Len(res.Diffs, 3)
+	_, err = os.Stat(dir)
+	is.True(os.IsNotExist(err))
+
+	// New snapshots are written.
+	s.Check = false
+	res, err = s.Run(snapshotManifests)
+	req.NoError(err)
+	is.Equal([]string{"raw.yaml", "service-web-2.yaml", "service-web.yaml"}, res.Written)
+	is.Empty(res.Diffs)
+
+	// Changed and obsolete snapshots are differences.
+	changed := "# Source: web/templates/raw.yaml\nanswer: 43\n"
+	res, err = s.Run(changed)
+	req.NoError(err)
+	is.Empty(res.Written)
+	req.Len(res.Diffs, 3)
+	is.Equal("raw.yaml", res.Diffs[0].File)
+	is.Contains(res.Diffs[0].Diff, "-answer: 42\n+answer: 43\n")
+	is.Equal("service-web-2.yaml", res.Diffs[1].File)
+	is.Equal("service-web.yaml", res.Diffs[2].File)
+
+	// Updating accepts the changes.
+	s.Update = true
+	res, err = s.Run(changed)
+	req.NoError(err)
+	is.Equal([]string{"raw.yaml"}, res.Updated)
+	is.Equal([]string{"service-web-2.yaml", "service-web.yaml"}, res.Removed)
+	is.Empty(res.Diffs)
+
+	entries, err := ioutil.ReadDir(dir)
+	req.NoError(err)
+	req.Len(entries, 1)
+	data, err := ioutil.ReadFile(filepath.Join(dir, "raw.yaml"))
+	req.NoError(err)
+	is.Equal(changed, string(data))
+
+	s.Check = true
+	_, err = s.Run(changed)
+	is.Error(err)
+}
@@ -0,0 +1,104 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/unittest"
+)
+
+// UnitTest is the action for running the unit tests of a chart.
+//
+// It provides the implementation of 'helm unittest'.
+type UnitTest struct {
+	// UpdateSnapshots records the results of snapshot assertions instead of
+	// comparing them.
+	UpdateSnapshots bool
+	// Strict fails rendering on references to missing values.
+	Strict bool
+}
+
+// UnitTestResult is the result of UnitTest.
+type UnitTestResult struct {
+	// Chart is the name of the tested chart.
+	Chart  string
+	Suites []*unittest.SuiteResult
+}
+
+// Failed returns the number of tests which did not pass.
+func (r *UnitTestResult) Failed() int {
+	n := 0
+	for _, s := range r.Suites {
+		n += s.Failed()
+	}
+	return n
+}
+
+// Total returns the number of tests which were run.
+func (r *UnitTestResult) Total() int {
+	n := 0
+	for _, s := range r.Suites {
+		n += len(s.Tests)
+	}
+	return n
+}
+
+// NewUnitTest creates a new UnitTest object.
+func NewUnitTest() *UnitTest {
+	return &UnitTest{}
+}
+
+// Run runs the test suites of the chart in the directory chartPath.
+func (u *UnitTest) Run(chartPath string) (*UnitTestResult, error) {
+	if fi, err := os.Stat(chartPath); err != nil {
+		return nil, err
+	} else if !fi.IsDir() {
+		return nil, errors.Errorf("%s is not a chart directory", chartPath)
+	}
+
+	chrt, err := loader.LoadDir(chartPath)
+	if err != nil {
+		return nil, err
+	}
+	suites, err := unittest.LoadSuites(chartPath)
+	if err != nil {
+		return nil, err
+	}
+	if len(suites) == 0 {
+		return nil, errors.Errorf("no test suites found in %s", filepath.Join(chartPath, unittest.TestsDir))
+	}
+
+	runner := &unittest.Runner{
+		ChartPath:       chartPath,
+		UpdateSnapshots: u.UpdateSnapshots,
+		Strict:          u.Strict,
+	}
+	result := &UnitTestResult{Chart: chrt.Name()}
+	for _, s := range suites {
+		r, err := runner.Run(s)
+		if err != nil {
+			return result, errors.Wrapf(err, "test suite %s", s.Path())
+		}
+		result.Suites = append(result.Suites, r)
+	}
+	return result, nil
+}
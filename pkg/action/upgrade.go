@@ -20,6 +20,7 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"io"
 	"strings"
 	"time"
 
@@ -87,6 +88,8 @@ type Upgrade struct {
 	CleanupOnFail bool
 	// SubNotes determines whether sub-notes are rendered in the chart.
 	SubNotes bool
+	// SchemaDefaults applies the defaults of the values schemas of the chart.
+	SchemaDefaults bool
 	// Description is the description of this operation
 	Description string
 	// PostRender is an optional post-renderer
@@ -96,6 +99,12 @@ type Upgrade struct {
 	PostRenderer postrender.PostRenderer
 	// DisableOpenAPIValidation controls whether OpenAPI validation is enforced.
 	DisableOpenAPIValidation bool
+	// DeprecatedAPIs, if set, replace chartutil.DefaultDeprecatedAPIs when
+	// warning about the APIs removed by the Kubernetes version of the cluster
+	// or its next minor version.
+	DeprecatedAPIs chartutil.DeprecatedAPIs
+	// Warnings, if set, receives the warnings about the rendered manifests.
+	Warnings io.Writer
 }
 
 // NewUpgrade creates a new Upgrade object with the given configuration.
@@ -202,10 +211,11 @@ func (u *Upgrade) prepareUpgrade(name string, chart *chart.Chart, vals map[strin
 	revision := lastRelease.Version + 1
 
 	options := chartutil.ReleaseOptions{
-		Name:      name,
-		Namespace: currentRelease.Namespace,
-		Revision:  revision,
-		IsUpgrade: true,
+		Name:           name,
+		Namespace:      currentRelease.Namespace,
+		Revision:       revision,
+		IsUpgrade:      true,
+		SchemaDefaults: u.SchemaDefaults,
 	}
 
 	caps, err := u.cfg.getCapabilities()
@@ -217,7 +227,7 @@ func (u *Upgrade) prepareUpgrade(name string, chart *chart.Chart, vals map[strin
 		return nil, nil, err
 	}
 
-	hooks, manifestDoc, notesTxt, err := u.cfg.renderResources(chart, valuesToRender, "", "", u.SubNotes, false, false, u.PostRenderer, u.DryRun)
+	hooks, manifestDoc, notesTxt, outputs, err := u.cfg.renderResources(chart, valuesToRender, "", "", u.SubNotes, false, false, renderOptions{}, u.PostRenderer, u.DryRun)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -233,6 +243,8 @@ func (u *Upgrade) prepareUpgrade(name string, chart *chart.Chart, vals map[strin
 			LastDeployed:  Timestamper(),
 			Status:        release.StatusPendingUpgrade,
 			Description:   "Preparing upgrade", // This should be overwritten later.
+			Outputs:       outputs,
+			Audit:         u.cfg.audit(),
 		},
 		Version:  revision,
 		Manifest: manifestDoc.String(),
@@ -242,6 +254,7 @@ func (u *Upgrade) prepareUpgrade(name string, chart *chart.Chart, vals map[strin
 	if len(notesTxt) > 0 {
 		upgradedRelease.Info.Notes = notesTxt
 	}
+	u.cfg.warnDeprecatedAPIs(upgradedRelease, u.DeprecatedAPIs, caps.KubeVersion, u.Warnings)
 	err = validateManifest(u.cfg.KubeClient, manifestDoc.Bytes(), !u.DisableOpenAPIValidation)
 	return currentRelease, upgradedRelease, err
 }
@@ -16,7 +16,14 @@ limitations under the License.
 package chartutil
 
 import (
+	"fmt"
+	"io/ioutil"
+	"sort"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/pkg/errors"
 	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/yaml"
 
 	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	apiextensionsv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
@@ -45,6 +52,57 @@ type Capabilities struct {
 	APIVersions VersionSet
 }
 
+// capabilitiesFile is the format of a capabilities file.
+type capabilitiesFile struct {
+	KubeVersion string     `json:"kubeVersion"`
+	APIVersions VersionSet `json:"apiVersions"`
+}
+
+// ReadCapabilitiesFile reads capabilities, e.g. those of a cluster exported by
+// 'helm capabilities export', from a file.
+func ReadCapabilitiesFile(filename string) (*Capabilities, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	caps, err := ParseCapabilities(data)
+	return caps, errors.Wrapf(err, "invalid capabilities file %s", filename)
+}
+
+// ParseCapabilities parses capabilities in the YAML format of a capabilities
+// file:
+//
+//	kubeVersion: v1.18.3
+//	apiVersions:
+//	  - v1
+//	  - apps/v1
+func ParseCapabilities(data []byte) (*Capabilities, error) {
+	var f capabilitiesFile
+	if err := yaml.UnmarshalStrict(data, &f); err != nil {
+		return nil, err
+	}
+	if f.KubeVersion == "" {
+		return nil, errors.New("kubeVersion is required")
+	}
+	kv, err := ParseKubeVersion(f.KubeVersion)
+	if err != nil {
+		return nil, err
+	}
+	return &Capabilities{KubeVersion: kv, APIVersions: f.APIVersions}, nil
+}
+
+// YAML encodes the capabilities in the format of a capabilities file, with
+// the API versions sorted.
+func (c *Capabilities) YAML() (string, error) {
+	f := capabilitiesFile{
+		KubeVersion: c.KubeVersion.Version,
+		APIVersions: append(VersionSet{}, c.APIVersions...),
+	}
+	sort.Strings(f.APIVersions)
+	b, err := yaml.Marshal(f)
+	return string(b), err
+}
+
 // KubeVersion is the Kubernetes version.
 type KubeVersion struct {
 	Version string // Kubernetes version
@@ -55,6 +113,20 @@ type KubeVersion struct {
 // String implements fmt.Stringer
 func (kv *KubeVersion) String() string { return kv.Version }
 
+// ParseKubeVersion parses a Kubernetes version, such as "v1.18.3" or
+// "1.18.3-gke.1".
+func ParseKubeVersion(version string) (KubeVersion, error) {
+	v, err := semver.NewVersion(version)
+	if err != nil {
+		return KubeVersion{}, errors.Wrapf(err, "invalid kubeVersion %q", version)
+	}
+	return KubeVersion{
+		Version: "v" + v.String(),
+		Major:   fmt.Sprint(v.Major()),
+		Minor:   fmt.Sprint(v.Minor()),
+	}, nil
+}
+
 // GitVersion returns the Kubernetes version string.
 //
 // Deprecated: use KubeVersion.Version.
@@ -58,3 +58,34 @@ func TestDefaultCapabilities(t *testing.T) {
 		t.Errorf("Expected default KubeVersion.Minor to be 16, got %q", kv.Minor)
 	}
 }
+
+func TestParseCapabilities(t *testing.T) {
+	caps, err := ParseCapabilities([]byte("kubeVersion: 1.16.2-gke.1\napiVersions: [v1, apps/v1]\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if caps.KubeVersion != (KubeVersion{Version: "v1.16.2-gke.1", Major: "1", Minor: "16"}) {
+		t.Errorf("Unexpected KubeVersion %+v", caps.KubeVersion)
+	}
+	if !caps.APIVersions.Has("apps/v1") {
+		t.Error("Expected to find apps/v1")
+	}
+
+	s, err := caps.YAML()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expect := "apiVersions:\n- apps/v1\n- v1\nkubeVersion: v1.16.2-gke.1\n"; s != expect {
+		t.Errorf("Expected %q, got %q", expect, s)
+	}
+
+	for data, expect := range map[string]string{
+		"apiVersions: [v1]\n":            "kubeVersion is required",
+		"kubeVersion: latest\n":          `invalid kubeVersion "latest": Invalid Semantic Version`,
+		"kubeVersion: v1.16.2\nfoo: 1\n": `error unmarshaling JSON: while decoding JSON: json: unknown field "foo"`,
+	} {
+		if _, err := ParseCapabilities([]byte(data)); err == nil || err.Error() != expect {
+			t.Errorf("Expected error %q, got %v", expect, err)
+		}
+	}
+}
@@ -0,0 +1,201 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chartutil
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/pkg/errors"
+	"sigs.k8s.io/yaml"
+)
+
+// DeprecatedAPI is a Kubernetes API which is deprecated in a version of
+// Kubernetes, and possibly removed in a later one.
+type DeprecatedAPI struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	// DeprecatedIn is the minor version of Kubernetes deprecating the API,
+	// e.g. "1.16".
+	DeprecatedIn string `json:"deprecatedIn"`
+	// RemovedIn, if set, is the minor version of Kubernetes which no longer
+	// serves the API.
+	RemovedIn string `json:"removedIn,omitempty"`
+	// Replacement, if set, is the API replacing it, e.g. "apps/v1 Deployment".
+	Replacement string `json:"replacement,omitempty"`
+}
+
+// String returns the API version and kind of the API, e.g.
+// "extensions/v1beta1 Deployment".
+func (a DeprecatedAPI) String() string {
+	return a.APIVersion + " " + a.Kind
+}
+
+// IsDeprecated returns whether the API is deprecated in a version of
+// Kubernetes.
+func (a DeprecatedAPI) IsDeprecated(kubeVersion KubeVersion) bool {
+	return minorAtLeast(kubeVersion, a.DeprecatedIn)
+}
+
+// IsRemoved returns whether the API is no longer served by a version of
+// Kubernetes.
+func (a DeprecatedAPI) IsRemoved(kubeVersion KubeVersion) bool {
+	return a.RemovedIn != "" && minorAtLeast(kubeVersion, a.RemovedIn)
+}
+
+// minorAtLeast returns whether the minor version of a version of Kubernetes
+// is at least the given one.
+func minorAtLeast(kubeVersion KubeVersion, minor string) bool {
+	v, err := semver.NewVersion(kubeVersion.Version)
+	if err != nil {
+		return false
+	}
+	m, err := semver.NewVersion(minor)
+	if err != nil {
+		return false
+	}
+	return v.Major() > m.Major() || v.Major() == m.Major() && v.Minor() >= m.Minor()
+}
+
+// NextMinor returns the minor version of Kubernetes following a version, e.g.
+// "1.19" for "v1.18.3".
+func NextMinor(kubeVersion KubeVersion) KubeVersion {
+	v, err := semver.NewVersion(kubeVersion.Version)
+	if err != nil {
+		return kubeVersion
+	}
+	next := v.IncMinor()
+	return KubeVersion{
+		Version: "v" + next.String(),
+		Major:   fmt.Sprint(next.Major()),
+		Minor:   fmt.Sprint(next.Minor()),
+	}
+}
+
+// DeprecatedAPIs is a table of deprecated Kubernetes APIs.
+type DeprecatedAPIs []DeprecatedAPI
+
+// DefaultDeprecatedAPIs are the APIs deprecated by the versions of Kubernetes
+// up to the version of DefaultCapabilities.
+var DefaultDeprecatedAPIs = DeprecatedAPIs{
+	{APIVersion: "extensions/v1beta1", Kind: "Deployment", DeprecatedIn: "1.9", RemovedIn: "1.16", Replacement: "apps/v1 Deployment"},
+	{APIVersion: "extensions/v1beta1", Kind: "DaemonSet", DeprecatedIn: "1.9", RemovedIn: "1.16", Replacement: "apps/v1 DaemonSet"},
+	{APIVersion: "extensions/v1beta1", Kind: "ReplicaSet", DeprecatedIn: "1.9", RemovedIn: "1.16", Replacement: "apps/v1 ReplicaSet"},
+	{APIVersion: "extensions/v1beta1", Kind: "PodSecurityPolicy", DeprecatedIn: "1.10", RemovedIn: "1.16", Replacement: "policy/v1beta1 PodSecurityPolicy"},
+	{APIVersion: "extensions/v1beta1", Kind: "NetworkPolicy", DeprecatedIn: "1.9", RemovedIn: "1.16", Replacement: "networking.k8s.io/v1 NetworkPolicy"},
+	{APIVersion: "extensions/v1beta1", Kind: "Ingress", DeprecatedIn: "1.14", RemovedIn: "1.22", Replacement: "networking.k8s.io/v1 Ingress"},
+	{APIVersion: "networking.k8s.io/v1beta1", Kind: "Ingress", DeprecatedIn: "1.19", RemovedIn: "1.22", Replacement: "networking.k8s.io/v1 Ingress"},
+	{APIVersion: "apps/v1beta1", Kind: "Deployment", DeprecatedIn: "1.9", RemovedIn: "1.16", Replacement: "apps/v1 Deployment"},
+	{APIVersion: "apps/v1beta1", Kind: "StatefulSet", DeprecatedIn: "1.9", RemovedIn: "1.16", Replacement: "apps/v1 StatefulSet"},
+	{APIVersion: "apps/v1beta1", Kind: "ReplicaSet", DeprecatedIn: "1.9", RemovedIn: "1.16", Replacement: "apps/v1 ReplicaSet"},
+	{APIVersion: "apps/v1beta2", Kind: "Deployment", DeprecatedIn: "1.9", RemovedIn: "1.16", Replacement: "apps/v1 Deployment"},
+	{APIVersion: "apps/v1beta2", Kind: "StatefulSet", DeprecatedIn: "1.9", RemovedIn: "1.16", Replacement: "apps/v1 StatefulSet"},
+	{APIVersion: "apps/v1beta2", Kind: "DaemonSet", DeprecatedIn: "1.9", RemovedIn: "1.16", Replacement: "apps/v1 DaemonSet"},
+	{APIVersion: "apps/v1beta2", Kind: "ReplicaSet", DeprecatedIn: "1.9", RemovedIn: "1.16", Replacement: "apps/v1 ReplicaSet"},
+	{APIVersion: "apiextensions.k8s.io/v1beta1", Kind: "CustomResourceDefinition", DeprecatedIn: "1.16", RemovedIn: "1.22", Replacement: "apiextensions.k8s.io/v1 CustomResourceDefinition"},
+	{APIVersion: "rbac.authorization.k8s.io/v1alpha1", Kind: "ClusterRole", DeprecatedIn: "1.8", RemovedIn: "1.22", Replacement: "rbac.authorization.k8s.io/v1 ClusterRole"},
+	{APIVersion: "rbac.authorization.k8s.io/v1alpha1", Kind: "ClusterRoleList", DeprecatedIn: "1.8", RemovedIn: "1.22", Replacement: "rbac.authorization.k8s.io/v1 ClusterRoleList"},
+	{APIVersion: "rbac.authorization.k8s.io/v1alpha1", Kind: "ClusterRoleBinding", DeprecatedIn: "1.8", RemovedIn: "1.22", Replacement: "rbac.authorization.k8s.io/v1 ClusterRoleBinding"},
+	{APIVersion: "rbac.authorization.k8s.io/v1alpha1", Kind: "ClusterRoleBindingList", DeprecatedIn: "1.8", RemovedIn: "1.22", Replacement: "rbac.authorization.k8s.io/v1 ClusterRoleBindingList"},
+	{APIVersion: "rbac.authorization.k8s.io/v1alpha1", Kind: "Role", DeprecatedIn: "1.8", RemovedIn: "1.22", Replacement: "rbac.authorization.k8s.io/v1 Role"},
+	{APIVersion: "rbac.authorization.k8s.io/v1alpha1", Kind: "RoleList", DeprecatedIn: "1.8", RemovedIn: "1.22", Replacement: "rbac.authorization.k8s.io/v1 RoleList"},
+	{APIVersion: "rbac.authorization.k8s.io/v1alpha1", Kind: "RoleBinding", DeprecatedIn: "1.8", RemovedIn: "1.22", Replacement: "rbac.authorization.k8s.io/v1 RoleBinding"},
+	{APIVersion: "rbac.authorization.k8s.io/v1alpha1", Kind: "RoleBindingList", DeprecatedIn: "1.8", RemovedIn: "1.22", Replacement: "rbac.authorization.k8s.io/v1 RoleBindingList"},
+	{APIVersion: "rbac.authorization.k8s.io/v1beta1", Kind: "ClusterRole", DeprecatedIn: "1.17", RemovedIn: "1.22", Replacement: "rbac.authorization.k8s.io/v1 ClusterRole"},
+	{APIVersion: "rbac.authorization.k8s.io/v1beta1", Kind: "ClusterRoleList", DeprecatedIn: "1.17", RemovedIn: "1.22", Replacement: "rbac.authorization.k8s.io/v1 ClusterRoleList"},
+	{APIVersion: "rbac.authorization.k8s.io/v1beta1", Kind: "ClusterRoleBinding", DeprecatedIn: "1.17", RemovedIn: "1.22", Replacement: "rbac.authorization.k8s.io/v1 ClusterRoleBinding"},
+	{APIVersion: "rbac.authorization.k8s.io/v1beta1", Kind: "ClusterRoleBindingList", DeprecatedIn: "1.17", RemovedIn: "1.22", Replacement: "rbac.authorization.k8s.io/v1 ClusterRoleBindingList"},
+	{APIVersion: "rbac.authorization.k8s.io/v1beta1", Kind: "Role", DeprecatedIn: "1.17", RemovedIn: "1.22", Replacement: "rbac.authorization.k8s.io/v1 Role"},
+	{APIVersion: "rbac.authorization.k8s.io/v1beta1", Kind: "RoleList", DeprecatedIn: "1.17", RemovedIn: "1.22", Replacement: "rbac.authorization.k8s.io/v1 RoleList"},
+	{APIVersion: "rbac.authorization.k8s.io/v1beta1", Kind: "RoleBinding", DeprecatedIn: "1.17", RemovedIn: "1.22", Replacement: "rbac.authorization.k8s.io/v1 RoleBinding"},
+	{APIVersion: "rbac.authorization.k8s.io/v1beta1", Kind: "RoleBindingList", DeprecatedIn: "1.17", RemovedIn: "1.22", Replacement: "rbac.authorization.k8s.io/v1 RoleBindingList"},
+}
+
+// Lookup returns the deprecation of an API, or nil if it is not deprecated.
+func (d DeprecatedAPIs) Lookup(apiVersion, kind string) *DeprecatedAPI {
+	for i := range d {
+		if d[i].APIVersion == apiVersion && d[i].Kind == kind {
+			return &d[i]
+		}
+	}
+	return nil
+}
+
+// Merge returns the table overridden by the APIs of other: those of other
+// replace the ones of the table with the same API version and kind.
+func (d DeprecatedAPIs) Merge(other DeprecatedAPIs) DeprecatedAPIs {
+	merged := append(DeprecatedAPIs{}, d...)
+	for _, api := range other {
+		if existing := merged.Lookup(api.APIVersion, api.Kind); existing != nil {
+			*existing = api
+		} else {
+			merged = append(merged, api)
+		}
+	}
+	return merged
+}
+
+// deprecationsFile is the format of a file of deprecated APIs.
+type deprecationsFile struct {
+	APIs DeprecatedAPIs `json:"apis"`
+}
+
+// ReadDeprecatedAPIsFile reads a file of deprecated APIs, such as:
+//
+//	apis:
+//	  - apiVersion: extensions/v1beta1
+//	    kind: Ingress
+//	    deprecatedIn: "1.14"
+//	    removedIn: "1.22"
+//	    replacement: networking.k8s.io/v1 Ingress
+//
+// and returns DefaultDeprecatedAPIs overridden by them.
+func ReadDeprecatedAPIsFile(filename string) (DeprecatedAPIs, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	apis, err := ParseDeprecatedAPIs(data)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid deprecated APIs file %s", filename)
+	}
+	return DefaultDeprecatedAPIs.Merge(apis), nil
+}
+
+// ParseDeprecatedAPIs parses a table of deprecated APIs.
+func ParseDeprecatedAPIs(data []byte) (DeprecatedAPIs, error) {
+	f := deprecationsFile{}
+	if err := yaml.UnmarshalStrict(data, &f); err != nil {
+		return nil, err
+	}
+	for _, api := range f.APIs {
+		if api.APIVersion == "" || api.Kind == "" {
+			return nil, errors.Errorf("deprecated API %q requires an apiVersion and a kind", strings.TrimSpace(api.String()))
+		}
+		for _, v := range []string{api.DeprecatedIn, api.RemovedIn} {
+			if _, err := semver.NewVersion(v); err != nil && v != "" {
+				return nil, errors.Wrapf(err, "deprecated API %s: invalid version %q", api, v)
+			}
+		}
+		if api.DeprecatedIn == "" {
+			return nil, errors.Errorf("deprecated API %s requires deprecatedIn", api)
+		}
+	}
+	return f.APIs, nil
+}
@@ -0,0 +1,126 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chartutil
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDeprecatedAPIVersions(t *testing.T) {
+	api := DeprecatedAPI{APIVersion: "networking.k8s.io/v1beta1", Kind: "Ingress", DeprecatedIn: "1.19", RemovedIn: "1.22"}
+	tests := []struct {
+		version    string
+		deprecated bool
+		removed    bool
+	}{
+		{"v1.18.0", false, false},
+		{"v1.19.0", true, false},
+		{"v1.21.3", true, false},
+		{"v1.22.0", true, true},
+		{"v1.22.1-gke.1", true, true},
+		{"v2.0.0", true, true},
+	}
+	for _, tt := range tests {
+		v, err := ParseKubeVersion(tt.version)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := api.IsDeprecated(v); got != tt.deprecated {
+			t.Errorf("IsDeprecated(%s) = %t, expected %t", tt.version, got, tt.deprecated)
+		}
+		if got := api.IsRemoved(v); got != tt.removed {
+			t.Errorf("IsRemoved(%s) = %t, expected %t", tt.version, got, tt.removed)
+		}
+	}
+
+	api.RemovedIn = ""
+	if api.IsRemoved(KubeVersion{Version: "v9.0.0"}) {
+		t.Error("expected an API without removedIn to never be removed")
+	}
+}
+
+func TestNextMinor(t *testing.T) {
+	next := NextMinor(KubeVersion{Version: "v1.18.3", Major: "1", Minor: "18"})
+	if next.Version != "v1.19.0" || next.Major != "1" || next.Minor != "19" {
+		t.Errorf("unexpected next minor version %+v", next)
+	}
+}
+
+func TestDeprecatedAPIsLookup(t *testing.T) {
+	api := DefaultDeprecatedAPIs.Lookup("extensions/v1beta1", "Deployment")
+	if api == nil || api.Replacement != "apps/v1 Deployment" {
+		t.Errorf("unexpected deprecation of extensions/v1beta1 Deployment: %+v", api)
+	}
+	if api := DefaultDeprecatedAPIs.Lookup("apps/v1", "Deployment"); api != nil {
+		t.Errorf("expected apps/v1 Deployment not to be deprecated, got %+v", api)
+	}
+}
+
+func TestReadDeprecatedAPIsFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "helm-deprecations")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	filename := filepath.Join(dir, "deprecations.yaml")
+	data := `apis:
+  - apiVersion: extensions/v1beta1
+    kind: Ingress
+    deprecatedIn: "1.10"
+  - apiVersion: example.com/v1alpha1
+    kind: Widget
+    deprecatedIn: "1.0"
+    removedIn: "1.2"
+    replacement: example.com/v1 Widget
+`
+	if err := ioutil.WriteFile(filename, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	apis, err := ReadDeprecatedAPIsFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(apis) != len(DefaultDeprecatedAPIs)+1 {
+		t.Errorf("expected %d APIs, got %d", len(DefaultDeprecatedAPIs)+1, len(apis))
+	}
+	if api := apis.Lookup("extensions/v1beta1", "Ingress"); api.DeprecatedIn != "1.10" || api.RemovedIn != "" {
+		t.Errorf("expected the file to replace extensions/v1beta1 Ingress, got %+v", api)
+	}
+	if api := apis.Lookup("example.com/v1alpha1", "Widget"); api == nil || api.Replacement != "example.com/v1 Widget" {
+		t.Errorf("expected the file to add example.com/v1alpha1 Widget, got %+v", api)
+	}
+	if api := DefaultDeprecatedAPIs.Lookup("extensions/v1beta1", "Ingress"); api.DeprecatedIn != "1.14" {
+		t.Errorf("expected the default APIs to be left unchanged, got %+v", api)
+	}
+}
+
+func TestParseDeprecatedAPIsErrors(t *testing.T) {
+	for _, data := range []string{
+		"apis:\n  - kind: Ingress\n    deprecatedIn: \"1.14\"\n",
+		"apis:\n  - apiVersion: extensions/v1beta1\n    kind: Ingress\n",
+		"apis:\n  - apiVersion: extensions/v1beta1\n    kind: Ingress\n    deprecatedIn: soon\n",
+		"apis:\n  - apiVersion: extensions/v1beta1\n    kind: Ingress\n    deprecatedIn: \"1.14\"\n    removed: \"1.22\"\n",
+	} {
+		if _, err := ParseDeprecatedAPIs([]byte(data)); err == nil {
+			t.Errorf("expected an error parsing %q", data)
+		}
+	}
+}
@@ -0,0 +1,308 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chartutil
+
+import (
+	"path"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/mitchellh/copystructure"
+
+	"helm.sh/helm/v3/pkg/chart"
+)
+
+// ValuesLayer is a set of user supplied values together with a description of
+// where they came from, such as "-f prod.yaml".
+type ValuesLayer struct {
+	// Origin describes the source of the values.
+	Origin string
+	// Keyed layers are described by the key of each value in addition to the
+	// origin, e.g. "--set image.tag".
+	Keyed bool
+	// Values are the values supplied by this source alone.
+	Values map[string]interface{}
+}
+
+// ValueOrigin describes where a computed value came from.
+type ValueOrigin struct {
+	// Path is the location of the value, one element per nested key.
+	Path []string `json:"path"`
+	// Value is the computed value. It is nil for removed values.
+	Value interface{} `json:"value"`
+	// Origin is the source that supplied the value, or removed it.
+	Origin string `json:"origin"`
+	// Overrides lists the sources whose different value for the same key lost
+	// to Origin, by decreasing precedence.
+	Overrides []string `json:"overrides,omitempty"`
+	// Removed is set if the key was nulled out by Origin.
+	Removed bool `json:"removed,omitempty"`
+}
+
+// Key returns the dotted path of the value, e.g. "image.tag".
+func (o ValueOrigin) Key() string {
+	return strings.Join(o.Path, ".")
+}
+
+// ExplainValues computes the values of a chart exactly like CoalesceValues and
+// reports the origin of every leaf of the result, as well as keys that were
+// removed by setting them to null.
+//
+// vals are the merged user supplied values, layers describe the individual
+// sources they were merged from, in increasing order of precedence. Values of
+// the chart itself are attributed to its values.yaml file and those of its
+// subcharts to e.g. charts/redis/values.yaml.
+func ExplainValues(chrt *chart.Chart, vals map[string]interface{}, layers []ValuesLayer) (Values, []ValueOrigin, error) {
+	// Coalescing may write to the values of the charts, so the original
+	// values are kept aside to look up where a value came from.
+	e := &explainer{chart: chrt, layers: layers, values: map[*chart.Chart]map[string]interface{}{}}
+	if err := e.snapshot(chrt); err != nil {
+		return nil, nil, err
+	}
+
+	final, err := CoalesceValues(chrt, vals)
+	if err != nil {
+		return final, nil, err
+	}
+
+	var origins []ValueOrigin
+	walkValues(final, nil, func(p []string, v interface{}) {
+		o := ValueOrigin{Path: p, Value: v}
+		if defs := e.definitions(p); len(defs) > 0 {
+			o.Origin = defs[0].origin
+			o.Overrides = overridden(defs)
+		}
+		origins = append(origins, o)
+	})
+
+	// A key set to null by a source with a higher precedence than the sources
+	// supplying a value for it is removed from the computed values.
+	seen := map[string]bool{}
+	e.walkNulls(func(p []string) {
+		key := strings.Join(p, "\x00")
+		if seen[key] {
+			return
+		}
+		seen[key] = true
+		if _, ok := lookupPath(final, p); ok {
+			return
+		}
+		defs := e.definitions(p)
+		if len(defs) < 2 || defs[0].value != nil {
+			return
+		}
+		origins = append(origins, ValueOrigin{
+			Path:      p,
+			Origin:    defs[0].origin,
+			Overrides: overridden(defs),
+			Removed:   true,
+		})
+	})
+
+	sort.SliceStable(origins, func(i, j int) bool {
+		return lessPath(origins[i].Path, origins[j].Path)
+	})
+	return final, origins, nil
+}
+
+// definition is a value a source supplies for a key.
+type definition struct {
+	origin string
+	value  interface{}
+}
+
+type explainer struct {
+	chart  *chart.Chart
+	layers []ValuesLayer
+	// values holds a copy of the values of each chart.
+	values map[*chart.Chart]map[string]interface{}
+}
+
+func (e *explainer) snapshot(ch *chart.Chart) error {
+	v, err := copystructure.Copy(ch.Values)
+	if err != nil {
+		return err
+	}
+	e.values[ch], _ = v.(map[string]interface{})
+	for _, sub := range ch.Dependencies() {
+		if err := e.snapshot(sub); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// definitions returns the values supplied for the key at p, by decreasing
+// precedence.
+func (e *explainer) definitions(p []string) []definition {
+	var defs []definition
+
+	// Globals of the parent chart are copied over those of a subchart.
+	if i := e.subchartGlobal(p); i > 0 {
+		for _, d := range e.definitions(append([]string{GlobalKey}, p[i+1:]...)) {
+			defs = append(defs, definition{"global from parent (" + d.origin + ")", d.value})
+		}
+	}
+
+	for i := len(e.layers) - 1; i >= 0; i-- {
+		l := e.layers[i]
+		if v, ok := lookupPath(l.Values, p); ok {
+			origin := l.Origin
+			if l.Keyed {
+				origin += " " + strings.Join(p, ".")
+			}
+			defs = append(defs, definition{origin, v})
+		}
+	}
+
+	return append(defs, e.chartDefinitions(e.chart, "", p)...)
+}
+
+// subchartGlobal returns the index of the global key in p if p refers to a
+// global value of a subchart, or -1.
+func (e *explainer) subchartGlobal(p []string) int {
+	ch := e.chart
+	for i, k := range p {
+		if k == GlobalKey {
+			if i > 0 {
+				return i
+			}
+			return -1
+		}
+		if ch = dependency(ch, k); ch == nil {
+			return -1
+		}
+	}
+	return -1
+}
+
+// chartDefinitions returns the values the chart ch, located in dir, and its
+// subcharts supply for the key at p.
+func (e *explainer) chartDefinitions(ch *chart.Chart, dir string, p []string) []definition {
+	var subDefs []definition
+	if len(p) > 1 {
+		if sub := dependency(ch, p[0]); sub != nil {
+			subDefs = e.chartDefinitions(sub, path.Join(dir, "charts", p[0]), p[1:])
+		}
+	}
+
+	v, ok := lookupPath(e.values[ch], p)
+	// Processing the dependencies of a chart copies the defaults of its
+	// subcharts into its values, so an identical value is attributed to the
+	// subchart.
+	if !ok || (len(subDefs) > 0 && reflect.DeepEqual(v, subDefs[0].value)) {
+		return subDefs
+	}
+	return append([]definition{{path.Join(dir, ValuesfileName), v}}, subDefs...)
+}
+
+// walkNulls calls fn with the path of every key set to null by any source.
+func (e *explainer) walkNulls(fn func([]string)) {
+	for _, l := range e.layers {
+		walkValues(l.Values, nil, func(p []string, v interface{}) {
+			if v == nil {
+				fn(p)
+			}
+		})
+	}
+	var walkChart func(ch *chart.Chart, prefix []string)
+	walkChart = func(ch *chart.Chart, prefix []string) {
+		walkValues(e.values[ch], prefix, func(p []string, v interface{}) {
+			if v == nil {
+				fn(p)
+			}
+		})
+		for _, sub := range ch.Dependencies() {
+			walkChart(sub, append(append([]string{}, prefix...), sub.Name()))
+		}
+	}
+	walkChart(e.chart, nil)
+}
+
+func dependency(ch *chart.Chart, name string) *chart.Chart {
+	for _, sub := range ch.Dependencies() {
+		if sub.Name() == name {
+			return sub
+		}
+	}
+	return nil
+}
+
+// walkValues calls fn for every leaf of v, that is every value which is not a
+// non-empty map, in key order.
+func walkValues(v map[string]interface{}, prefix []string, fn func([]string, interface{})) {
+	keys := make([]string, 0, len(v))
+	for k := range v {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		p := append(append(make([]string, 0, len(prefix)+1), prefix...), k)
+		if m, ok := asMap(v[k]); ok && len(m) > 0 {
+			walkValues(m, p, fn)
+			continue
+		}
+		fn(p, v[k])
+	}
+}
+
+// lookupPath returns the value at p in v and whether the key exists.
+func lookupPath(v map[string]interface{}, p []string) (interface{}, bool) {
+	var cur interface{} = v
+	for _, k := range p {
+		m, ok := asMap(cur)
+		if !ok {
+			return nil, false
+		}
+		if cur, ok = m[k]; !ok {
+			return nil, false
+		}
+	}
+	return cur, true
+}
+
+func asMap(v interface{}) (map[string]interface{}, bool) {
+	switch m := v.(type) {
+	case map[string]interface{}:
+		return m, true
+	case Values:
+		return m, true
+	}
+	return nil, false
+}
+
+// overridden returns the origins of the definitions that lost to the first one,
+// skipping those which supply the same value.
+func overridden(defs []definition) []string {
+	var origins []string
+	for _, d := range defs[1:] {
+		if !reflect.DeepEqual(d.value, defs[0].value) {
+			origins = append(origins, d.origin)
+		}
+	}
+	return origins
+}
+
+func lessPath(a, b []string) bool {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i] != b[i] {
+			return a[i] < b[i]
+		}
+	}
+	return len(a) < len(b)
+}
@@ -0,0 +1,76 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chartutil
+
+import (
+	"reflect"
+	"testing"
+
+	"helm.sh/helm/v3/pkg/chart"
+)
+
+func TestExplainValues(t *testing.T) {
+	sub := &chart.Chart{
+		Metadata: &chart.Metadata{Name: "redis"},
+		Values: map[string]interface{}{
+			"port":  6379,
+			"image": "redis:5",
+		},
+	}
+	parent := &chart.Chart{
+		Metadata: &chart.Metadata{Name: "app"},
+		Values: map[string]interface{}{
+			"image":  map[string]interface{}{"repository": "app", "tag": "1.0"},
+			"debug":  true,
+			"global": map[string]interface{}{"env": "dev"},
+			"redis":  map[string]interface{}{"image": "redis:6"},
+		},
+	}
+	parent.AddDependency(sub)
+
+	layers := []ValuesLayer{
+		{Origin: "-f prod.yaml", Values: map[string]interface{}{
+			"image": map[string]interface{}{"tag": "2.0"},
+			"debug": nil,
+		}},
+		{Origin: "--set", Keyed: true, Values: map[string]interface{}{
+			"image": map[string]interface{}{"tag": "2.1"},
+		}},
+	}
+	vals := map[string]interface{}{
+		"image": map[string]interface{}{"tag": "2.1"},
+		"debug": nil,
+	}
+
+	_, origins, err := ExplainValues(parent, vals, layers)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expect := []ValueOrigin{
+		{Path: []string{"debug"}, Origin: "-f prod.yaml", Overrides: []string{"values.yaml"}, Removed: true},
+		{Path: []string{"global", "env"}, Value: "dev", Origin: "values.yaml"},
+		{Path: []string{"image", "repository"}, Value: "app", Origin: "values.yaml"},
+		{Path: []string{"image", "tag"}, Value: "2.1", Origin: "--set image.tag", Overrides: []string{"-f prod.yaml", "values.yaml"}},
+		{Path: []string{"redis", "global", "env"}, Value: "dev", Origin: "global from parent (values.yaml)"},
+		{Path: []string{"redis", "image"}, Value: "redis:6", Origin: "values.yaml", Overrides: []string{"charts/redis/values.yaml"}},
+		{Path: []string{"redis", "port"}, Value: 6379, Origin: "charts/redis/values.yaml"},
+	}
+	if !reflect.DeepEqual(origins, expect) {
+		t.Errorf("Expected origins\n%v\ngot\n%v", expect, origins)
+	}
+}
@@ -18,9 +18,15 @@ package chartutil
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
+	"net/url"
+	"path"
+	"strconv"
 	"strings"
+	"unicode"
 
+	"github.com/mitchellh/copystructure"
 	"github.com/pkg/errors"
 	"github.com/xeipuuv/gojsonschema"
 	"sigs.k8s.io/yaml"
@@ -28,60 +34,400 @@ import (
 	"helm.sh/helm/v3/pkg/chart"
 )
 
-// ValidateAgainstSchema checks that values does not violate the structure laid out in schema
-func ValidateAgainstSchema(chrt *chart.Chart, values map[string]interface{}) error {
+// SchemaError describes a value which violates the schema of a chart.
+type SchemaError struct {
+	// Chart is the name of the chart whose schema is violated.
+	Chart string `json:"chart,omitempty"`
+	// Path is the JSON path of the value within the values of the chart, e.g.
+	// $.image.tag.
+	Path string `json:"path"`
+	// Keys are the elements of the path, where list items are referred to by
+	// their index.
+	Keys []string `json:"-"`
+	// Message describes the violation.
+	Message string `json:"message"`
+}
+
+// Field returns the dotted path of the value, or "(root)" for the values as a
+// whole.
+func (e SchemaError) Field() string {
+	if len(e.Keys) == 0 {
+		return "(root)"
+	}
+	return strings.Join(e.Keys, ".")
+}
+
+func (e SchemaError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Field(), e.Message)
+}
+
+// SchemaErrors are the violations of the schemas of a chart and its subcharts.
+type SchemaErrors []SchemaError
+
+func (e SchemaErrors) Error() string {
 	var sb strings.Builder
-	if chrt.Schema != nil {
-		err := ValidateAgainstSingleSchema(values, chrt.Schema)
-		if err != nil {
-			sb.WriteString(fmt.Sprintf("%s:\n", chrt.Name()))
-			sb.WriteString(err.Error())
+	chrt := ""
+	for _, err := range e {
+		if err.Chart != "" && err.Chart != chrt {
+			sb.WriteString(fmt.Sprintf("%s:\n", err.Chart))
+			chrt = err.Chart
 		}
+		sb.WriteString(fmt.Sprintf("- %s\n", err))
+	}
+	return sb.String()
+}
+
+// ValidateAgainstSchema checks that values does not violate the structure laid
+// out in the schemas of the chart and its subcharts.
+//
+// A schema may refer to other JSON files of the chart with $ref, relative to
+// its own location. The files of subcharts are located in charts/NAME/, where
+// NAME is the name or alias of the subchart, and the files of the parent chart
+// in ../../. Violations are returned as SchemaErrors.
+func ValidateAgainstSchema(chrt *chart.Chart, values map[string]interface{}) error {
+	var errs SchemaErrors
+	if err := validateAgainstSchemas(chrt, values, &errs); err != nil {
+		return err
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+func validateAgainstSchemas(chrt *chart.Chart, values map[string]interface{}, errs *SchemaErrors) error {
+	if err := validateAgainstChartSchema(chrt, values, errs); err != nil {
+		return err
 	}
 
 	// For each dependency, recursively call this function with the coalesced values
 	for _, subchart := range chrt.Dependencies() {
 		subchartValues := values[subchart.Name()].(map[string]interface{})
-		if err := ValidateAgainstSchema(subchart, subchartValues); err != nil {
-			sb.WriteString(err.Error())
+		if err := validateAgainstSchemas(subchart, subchartValues, errs); err != nil {
+			return err
 		}
 	}
+	return nil
+}
 
-	if sb.Len() > 0 {
-		return errors.New(sb.String())
+// ValidateAgainstChartSchema checks that values does not violate the schema of
+// the chart itself, ignoring its subcharts. References are resolved like in
+// ValidateAgainstSchema.
+func ValidateAgainstChartSchema(chrt *chart.Chart, values map[string]interface{}) error {
+	var errs SchemaErrors
+	if err := validateAgainstChartSchema(chrt, values, &errs); err != nil {
+		return err
 	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
 
+func validateAgainstChartSchema(chrt *chart.Chart, values map[string]interface{}, errs *SchemaErrors) error {
+	if chrt.Schema == nil {
+		return nil
+	}
+	schema, err := compileChartSchema(chrt)
+	if err != nil {
+		return errors.Wrapf(err, "%s", chrt.Name())
+	}
+	chartErrs, err := validate(schema, values)
+	if err != nil {
+		return errors.Wrapf(err, "%s", chrt.Name())
+	}
+	for _, e := range chartErrs {
+		e.Chart = chrt.Name()
+		*errs = append(*errs, e)
+	}
 	return nil
 }
 
 // ValidateAgainstSingleSchema checks that values does not violate the structure laid out in this schema
 func ValidateAgainstSingleSchema(values Values, schemaJSON []byte) error {
-	valuesData, err := yaml.Marshal(values)
+	schema, err := gojsonschema.NewSchema(gojsonschema.NewBytesLoader(schemaJSON))
 	if err != nil {
 		return err
 	}
-	valuesJSON, err := yaml.YAMLToJSON(valuesData)
+	errs, err := validate(schema, values)
 	if err != nil {
 		return err
 	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+func validate(schema *gojsonschema.Schema, values map[string]interface{}) (SchemaErrors, error) {
+	valuesData, err := yaml.Marshal(values)
+	if err != nil {
+		return nil, err
+	}
+	valuesJSON, err := yaml.YAMLToJSON(valuesData)
+	if err != nil {
+		return nil, err
+	}
 	if bytes.Equal(valuesJSON, []byte("null")) {
 		valuesJSON = []byte("{}")
 	}
-	schemaLoader := gojsonschema.NewBytesLoader(schemaJSON)
-	valuesLoader := gojsonschema.NewBytesLoader(valuesJSON)
 
-	result, err := gojsonschema.Validate(schemaLoader, valuesLoader)
+	result, err := schema.Validate(gojsonschema.NewBytesLoader(valuesJSON))
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	var errs SchemaErrors
+	for _, desc := range result.Errors() {
+		keys := contextKeys(desc.Context())
+		errs = append(errs, SchemaError{
+			Path:    jsonPath(keys),
+			Keys:    keys,
+			Message: desc.Description(),
+		})
+	}
+	return errs, nil
+}
+
+// contextKeys returns the keys of the path of a value from the context in
+// which it was validated, without the root. The elements of the context are
+// joined with NUL, which cannot be part of a key given on the command line or
+// in a YAML file, so that keys containing dots are kept whole.
+func contextKeys(ctx *gojsonschema.JsonContext) []string {
+	if ctx == nil {
+		return nil
+	}
+	keys := strings.Split(ctx.String("\x00"), "\x00")
+	return keys[1:]
+}
+
+// jsonPath formats the path of a value as a JSON path, e.g. $.list[0].name.
+// Keys that are not identifiers are quoted, e.g. $.annotations['example.com/a'].
+func jsonPath(keys []string) string {
+	var sb strings.Builder
+	sb.WriteString("$")
+	for _, k := range keys {
+		switch {
+		case isIndex(k):
+			sb.WriteString("[" + k + "]")
+		case isIdentifier(k):
+			sb.WriteString("." + k)
+		default:
+			sb.WriteString("['" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(k) + "']")
+		}
+	}
+	return sb.String()
+}
+
+func isIndex(k string) bool {
+	_, err := strconv.Atoi(k)
+	return err == nil
+}
+
+func isIdentifier(k string) bool {
+	if k == "" {
+		return false
+	}
+	for i, r := range k {
+		if r != '_' && r != '-' && !unicode.IsLetter(r) && (i == 0 || !unicode.IsDigit(r)) {
+			return false
+		}
+	}
+	return true
+}
+
+// schemaBaseURL is the location of the root chart from which the references
+// between the schema files of a chart and its parents and subcharts are
+// resolved.
+const schemaBaseURL = "chart:///"
+
+// chartDir returns the path of chrt relative to its root chart.
+func chartDir(chrt *chart.Chart) string {
+	if chrt.IsRoot() {
+		return ""
+	}
+	return path.Join(chartDir(chrt.Parent()), "charts", chrt.Name()) + "/"
+}
+
+// schemaDocuments returns the JSON files of the chart tree chrt belongs to by
+// their URL.
+func schemaDocuments(chrt *chart.Chart) map[string][]byte {
+	docs := map[string][]byte{}
+	var add func(*chart.Chart)
+	add = func(ch *chart.Chart) {
+		dir := schemaBaseURL + chartDir(ch)
+		for _, f := range ch.Files {
+			if strings.HasSuffix(f.Name, ".json") {
+				docs[dir+f.Name] = f.Data
+			}
+		}
+		if ch.Schema != nil {
+			docs[dir+SchemafileName] = ch.Schema
+		}
+		for _, sub := range ch.Dependencies() {
+			add(sub)
+		}
+	}
+	add(chrt.Root())
+	return docs
+}
+
+// compileChartSchema compiles the schema of chrt with the other JSON files of
+// the chart tree available as references.
+func compileChartSchema(chrt *chart.Chart) (*gojsonschema.Schema, error) {
+	var root map[string]interface{}
+	if err := json.Unmarshal(chrt.Schema, &root); err != nil {
+		return nil, err
+	}
+	schemaURL := schemaBaseURL + chartDir(chrt) + SchemafileName
+	_, hasID := root["$id"]
+	if _, ok := root["id"]; !ok && !hasID {
+		root["$id"] = schemaURL
 	}
 
-	if !result.Valid() {
-		var sb strings.Builder
-		for _, desc := range result.Errors() {
-			sb.WriteString(fmt.Sprintf("- %s\n", desc))
+	sl := gojsonschema.NewSchemaLoader()
+	for u, doc := range schemaDocuments(chrt) {
+		if u == schemaURL {
+			continue
 		}
-		return errors.New(sb.String())
+		// Not every JSON file of a chart is a schema, so files which cannot be
+		// referenced are skipped.
+		var obj map[string]interface{}
+		if err := json.Unmarshal(doc, &obj); err != nil {
+			continue
+		}
+		_ = sl.AddSchema(u, gojsonschema.NewGoLoader(obj))
 	}
+	return sl.Compile(gojsonschema.NewGoLoader(root))
+}
 
+// ApplySchemaDefaults sets values missing in vals to the default given for them
+// by the schemas of the chart and its subcharts. Defaults of nested properties
+// only apply within maps which exist in vals, possibly because of a default of
+// their own. References are resolved like in ValidateAgainstSchema.
+func ApplySchemaDefaults(chrt *chart.Chart, vals map[string]interface{}) error {
+	if chrt.Schema != nil {
+		docs := map[string]interface{}{}
+		for u, doc := range schemaDocuments(chrt) {
+			var v interface{}
+			if err := json.Unmarshal(doc, &v); err == nil {
+				docs[u] = v
+			}
+		}
+		schemaURL := schemaBaseURL + chartDir(chrt) + SchemafileName
+		r := &schemaResolver{docs: docs}
+		if err := r.applyDefaults(docs[schemaURL], schemaURL, vals, 0); err != nil {
+			return errors.Wrapf(err, "%s", chrt.Name())
+		}
+	}
+
+	for _, sub := range chrt.Dependencies() {
+		if subVals, ok := vals[sub.Name()].(map[string]interface{}); ok {
+			if err := ApplySchemaDefaults(sub, subVals); err != nil {
+				return err
+			}
+		}
+	}
 	return nil
 }
+
+// maxSchemaDepth limits the nesting of schemas followed when applying defaults,
+// guarding against recursive references.
+const maxSchemaDepth = 64
+
+type schemaResolver struct {
+	docs map[string]interface{}
+}
+
+func (r *schemaResolver) applyDefaults(schema interface{}, base string, vals map[string]interface{}, depth int) error {
+	if depth > maxSchemaDepth {
+		return errors.New("schema nested too deeply")
+	}
+	s, base, err := r.deref(schema, base)
+	if err != nil {
+		return err
+	}
+	props, _ := s["properties"].(map[string]interface{})
+	for k, p := range props {
+		ps, pbase, err := r.deref(p, base)
+		if err != nil {
+			return err
+		}
+		if _, ok := vals[k]; !ok {
+			if d, ok := ps["default"]; ok {
+				c, err := copystructure.Copy(d)
+				if err != nil {
+					return err
+				}
+				vals[k] = c
+			}
+		}
+		if m, ok := vals[k].(map[string]interface{}); ok {
+			if err := r.applyDefaults(ps, pbase, m, depth+1); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// deref follows the $ref of a schema, if any, and returns the referenced schema
+// together with the URL it was found at.
+func (r *schemaResolver) deref(schema interface{}, base string) (map[string]interface{}, string, error) {
+	for i := 0; i < maxSchemaDepth; i++ {
+		s, _ := schema.(map[string]interface{})
+		ref, ok := s["$ref"].(string)
+		if !ok {
+			return s, base, nil
+		}
+		b, err := url.Parse(base)
+		if err != nil {
+			return nil, base, err
+		}
+		u, err := b.Parse(ref)
+		if err != nil {
+			return nil, base, errors.Wrapf(err, "invalid reference %q", ref)
+		}
+		fragment := u.Fragment
+		u.Fragment = ""
+		base = u.String()
+		doc, ok := r.docs[base]
+		if !ok {
+			return nil, base, errors.Errorf("unable to resolve reference %q", ref)
+		}
+		if schema, err = jsonPointer(doc, fragment); err != nil {
+			return nil, base, errors.Wrapf(err, "unable to resolve reference %q", ref)
+		}
+	}
+	return nil, base, errors.New("too many nested references")
+}
+
+// jsonPointer returns the value at an RFC 6901 JSON pointer within doc.
+func jsonPointer(doc interface{}, pointer string) (interface{}, error) {
+	if pointer == "" {
+		return doc, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, errors.Errorf("invalid JSON pointer %q", pointer)
+	}
+	cur := doc
+	for _, tok := range strings.Split(pointer[1:], "/") {
+		tok = strings.NewReplacer("~1", "/", "~0", "~").Replace(tok)
+		switch c := cur.(type) {
+		case map[string]interface{}:
+			var ok bool
+			if cur, ok = c[tok]; !ok {
+				return nil, errors.Errorf("%q not found", tok)
+			}
+		case []interface{}:
+			i, err := strconv.Atoi(tok)
+			if err != nil || i < 0 || i >= len(c) {
+				return nil, errors.Errorf("invalid index %q", tok)
+			}
+			cur = c[i]
+		default:
+			return nil, errors.Errorf("%q not found", tok)
+		}
+	}
+	return cur, nil
+}
@@ -18,6 +18,8 @@ package chartutil
 
 import (
 	"io/ioutil"
+	"reflect"
+	"strings"
 	"testing"
 
 	"helm.sh/helm/v3/pkg/chart"
@@ -141,3 +143,126 @@ func TestValidateAgainstSchemaNegative(t *testing.T) {
 		t.Errorf("Error string :\n`%s`\ndoes not match expected\n`%s`", errString, expectedErrString)
 	}
 }
+
+func refsChart() *chart.Chart {
+	sub := &chart.Chart{
+		Metadata: &chart.Metadata{Name: "redis"},
+		Schema: []byte(`{
+  "type": "object",
+  "properties": {
+    "port": {"$ref": "../../schemas/common.json#/definitions/port"},
+    "mode": {"type": "string", "default": "standalone"}
+  }
+}`),
+	}
+	parent := &chart.Chart{
+		Metadata: &chart.Metadata{Name: "app"},
+		Schema: []byte(`{
+  "type": "object",
+  "properties": {
+    "port": {"$ref": "schemas/common.json#/definitions/port"},
+    "image": {"$ref": "schemas/image.json"},
+    "redis": {"$ref": "charts/redis/values.schema.json"}
+  }
+}`),
+		Files: []*chart.File{
+			{Name: "schemas/common.json", Data: []byte(`{"definitions": {"port": {"type": "integer", "maximum": 65535, "default": 8080}}}`)},
+			{Name: "schemas/image.json", Data: []byte(`{"type": "object", "default": {}, "properties": {"pullPolicy": {"type": "string", "default": "IfNotPresent"}}}`)},
+			{Name: "data.json", Data: []byte(`[1, 2, 3]`)},
+		},
+	}
+	parent.AddDependency(sub)
+	return parent
+}
+
+func TestValidateAgainstSchemaWithRefs(t *testing.T) {
+	chrt := refsChart()
+
+	valid := map[string]interface{}{
+		"port":  80,
+		"redis": map[string]interface{}{"port": 6379},
+	}
+	if err := ValidateAgainstSchema(chrt, valid); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	invalid := map[string]interface{}{
+		"port":  100000,
+		"redis": map[string]interface{}{"port": "6379"},
+	}
+	err := ValidateAgainstSchema(chrt, invalid)
+	errs, ok := err.(SchemaErrors)
+	if !ok {
+		t.Fatalf("Expected SchemaErrors, got %v", err)
+	}
+	if len(errs) != 3 {
+		t.Fatalf("Expected 3 errors, got %d: %s", len(errs), errs)
+	}
+	paths := map[string]string{}
+	for _, e := range errs {
+		paths[e.Chart+" "+e.Path] = e.Message
+	}
+	for _, p := range []string{"app $.port", "app $.redis.port", "redis $.port"} {
+		if _, ok := paths[p]; !ok {
+			t.Errorf("Expected an error for %s, got %v", p, paths)
+		}
+	}
+	if !strings.HasPrefix(err.Error(), "app:\n- ") {
+		t.Errorf("Unexpected error format:\n%s", err)
+	}
+}
+
+func TestApplySchemaDefaults(t *testing.T) {
+	chrt := refsChart()
+	vals := map[string]interface{}{
+		"redis": map[string]interface{}{"port": 6379},
+	}
+	if err := ApplySchemaDefaults(chrt, vals); err != nil {
+		t.Fatal(err)
+	}
+
+	expect := map[string]interface{}{
+		"port":  float64(8080),
+		"image": map[string]interface{}{"pullPolicy": "IfNotPresent"},
+		"redis": map[string]interface{}{"port": 6379, "mode": "standalone"},
+	}
+	if !reflect.DeepEqual(vals, expect) {
+		t.Errorf("Expected %v, got %v", expect, vals)
+	}
+}
+
+func TestSchemaErrorPath(t *testing.T) {
+	schema := []byte(`{"properties": {"list": {"items": {"properties": {"name": {"type": "string"}}}}}}`)
+	vals := map[string]interface{}{
+		"list": []interface{}{map[string]interface{}{"name": "a"}, map[string]interface{}{"name": 1}},
+	}
+	err := ValidateAgainstSingleSchema(vals, schema)
+	errs, ok := err.(SchemaErrors)
+	if !ok || len(errs) != 1 {
+		t.Fatalf("Expected a single schema error, got %v", err)
+	}
+	if errs[0].Path != "$.list[1].name" {
+		t.Errorf("Expected path $.list[1].name, got %s", errs[0].Path)
+	}
+	if !reflect.DeepEqual(errs[0].Keys, []string{"list", "1", "name"}) {
+		t.Errorf("Unexpected keys %v", errs[0].Keys)
+	}
+}
+
+func TestSchemaErrorPathDottedKeys(t *testing.T) {
+	schema := []byte(`{"properties": {"annotations": {"properties": {"prometheus.io/scrape": {"type": "string"}}}}}`)
+	vals := map[string]interface{}{
+		"annotations": map[string]interface{}{"prometheus.io/scrape": true},
+	}
+	err := ValidateAgainstSingleSchema(vals, schema)
+	errs, ok := err.(SchemaErrors)
+	if !ok || len(errs) != 1 {
+		t.Fatalf("Expected a single schema error, got %v", err)
+	}
+	if errs[0].Path != "$.annotations['prometheus.io/scrape']" {
+		t.Errorf("Expected path $.annotations['prometheus.io/scrape'], got %s", errs[0].Path)
+	}
+	if !reflect.DeepEqual(errs[0].Keys, []string{"annotations", "prometheus.io/scrape"}) {
+		t.Errorf("Unexpected keys %v", errs[0].Keys)
+	}
+}
@@ -0,0 +1,348 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chartutil
+
+import (
+	"encoding/json"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/yaml"
+
+	"helm.sh/helm/v3/pkg/chart"
+)
+
+// schemaDraft is the JSON Schema version of generated schemas.
+const schemaDraft = "http://json-schema.org/draft-07/schema#"
+
+// schemaAnnotation introduces a comment describing the schema of a value.
+const schemaAnnotation = "@schema"
+
+// schemaStringKeywords are the annotation keywords whose values are always
+// strings, even if they look like another type.
+var schemaStringKeywords = map[string]bool{
+	"description": true,
+	"format":      true,
+	"pattern":     true,
+	"title":       true,
+}
+
+// GenerateSchema infers a JSON Schema for the values of a chart from the types
+// of the values in its values.yaml file.
+//
+// A value can be described in more detail by a comment on the line above it, or
+// at the end of its line, of the form
+//
+//	# @schema type=integer minimum=1 description="Number of replicas"
+//
+// Each keyword=value pair is added to the schema of the value, where values are
+// parsed as YAML, so lists are written as enum=[a,b]. The bare keyword required
+// marks the value as required in its parent object. Annotations of list items
+// apply to the schema of the items.
+//
+// The schemas of subcharts are nested under their name, or alias if they have
+// one. A subchart's own values.schema.json takes precedence over a generated
+// schema.
+func GenerateSchema(chrt *chart.Chart) ([]byte, error) {
+	schema, err := generateSchema(chrt)
+	if err != nil {
+		return nil, err
+	}
+	schema["$schema"] = schemaDraft
+	b, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(b, '\n'), nil
+}
+
+func generateSchema(chrt *chart.Chart) (map[string]interface{}, error) {
+	var raw []byte
+	for _, f := range chrt.Raw {
+		if f.Name == ValuesfileName {
+			raw = f.Data
+		}
+	}
+	annotations, err := parseSchemaAnnotations(raw)
+	if err != nil {
+		return nil, errors.Wrapf(err, "chart %q", chrt.Name())
+	}
+
+	schema := inferSchema(map[string]interface{}(chrt.Values), annotations, nil)
+
+	for key, sub := range subchartsByKey(chrt) {
+		var subSchema map[string]interface{}
+		if sub.Schema != nil {
+			if err := json.Unmarshal(sub.Schema, &subSchema); err != nil {
+				return nil, errors.Wrapf(err, "invalid %s of chart %q", SchemafileName, sub.Name())
+			}
+			delete(subSchema, "$schema")
+		} else if subSchema, err = generateSchema(sub); err != nil {
+			return nil, err
+		}
+
+		props := schemaProperties(schema)
+		if parent, ok := props[key].(map[string]interface{}); ok {
+			subSchema = mergeSchemas(subSchema, parent)
+		}
+		props[key] = subSchema
+	}
+	return schema, nil
+}
+
+// subchartsByKey returns the subcharts of chrt by the key of their values,
+// which is their alias if they have one.
+func subchartsByKey(chrt *chart.Chart) map[string]*chart.Chart {
+	subs := map[string]*chart.Chart{}
+	for _, sub := range chrt.Dependencies() {
+		subs[sub.Name()] = sub
+	}
+	if chrt.Metadata == nil {
+		return subs
+	}
+	for _, dep := range chrt.Metadata.Dependencies {
+		if dep.Alias == "" || dep.Alias == dep.Name {
+			continue
+		}
+		if sub, ok := subs[dep.Name]; ok {
+			subs[dep.Alias] = sub
+			delete(subs, dep.Name)
+		}
+	}
+	return subs
+}
+
+// inferSchema returns the schema of v, enriched with the annotations of the
+// value at path p and its children.
+func inferSchema(v interface{}, annotations map[string]map[string]interface{}, p []string) map[string]interface{} {
+	schema := map[string]interface{}{}
+	switch val := v.(type) {
+	case map[string]interface{}:
+		schema["type"] = "object"
+		props := map[string]interface{}{}
+		var required []string
+		for k, child := range val {
+			cp := append(append([]string{}, p...), k)
+			props[k] = inferSchema(child, annotations, cp)
+			if a := annotations[strings.Join(cp, "\x00")]; a != nil && a["required"] == true {
+				required = append(required, k)
+			}
+		}
+		if len(props) > 0 {
+			schema["properties"] = props
+		}
+		if len(required) > 0 {
+			sort.Strings(required)
+			schema["required"] = required
+		}
+	case []interface{}:
+		schema["type"] = "array"
+		ip := append(append([]string{}, p...), "[]")
+		if len(val) > 0 {
+			schema["items"] = inferSchema(val[0], annotations, ip)
+		} else if a := annotations[strings.Join(ip, "\x00")]; a != nil {
+			schema["items"] = inferSchema(nil, annotations, ip)
+		}
+	case bool:
+		schema["type"] = "boolean"
+	case float64:
+		if val == math.Trunc(val) {
+			schema["type"] = "integer"
+		} else {
+			schema["type"] = "number"
+		}
+	case int, int64:
+		schema["type"] = "integer"
+	case string:
+		schema["type"] = "string"
+	}
+
+	for k, a := range annotations[strings.Join(p, "\x00")] {
+		if k != "required" {
+			schema[k] = a
+		}
+	}
+	return schema
+}
+
+func schemaProperties(schema map[string]interface{}) map[string]interface{} {
+	props, ok := schema["properties"].(map[string]interface{})
+	if !ok {
+		props = map[string]interface{}{}
+		schema["properties"] = props
+	}
+	return props
+}
+
+// mergeSchemas adds the keywords and properties of b missing in a to a.
+func mergeSchemas(a, b map[string]interface{}) map[string]interface{} {
+	for k, v := range b {
+		if k == "properties" {
+			continue
+		}
+		if _, ok := a[k]; !ok {
+			a[k] = v
+		}
+	}
+	if bp, ok := b["properties"].(map[string]interface{}); ok {
+		ap := schemaProperties(a)
+		for k, v := range bp {
+			if av, ok := ap[k].(map[string]interface{}); ok {
+				if bv, ok := v.(map[string]interface{}); ok {
+					ap[k] = mergeSchemas(av, bv)
+				}
+				continue
+			}
+			ap[k] = v
+		}
+	}
+	return a
+}
+
+// parseSchemaAnnotations reads the @schema comments of a values file. They are
+// returned by the path of the value they describe, joined by NUL. List items
+// are referred to by the path element "[]".
+func parseSchemaAnnotations(data []byte) (map[string]map[string]interface{}, error) {
+	var pending map[string]interface{}
+	annotations := map[string]map[string]interface{}{}
+
+	err := scanValuesLines(data, func(p []valuesKey, n int, comment string) error {
+		if comment != "" {
+			a, err := parseSchemaComment(comment)
+			if err != nil {
+				return errors.Wrapf(err, "line %d", n)
+			}
+			if a != nil {
+				pending = mergeAnnotations(pending, a)
+			}
+		}
+		if p == nil {
+			// A blank line separates annotations from the next value.
+			if comment == "" {
+				pending = nil
+			}
+			return nil
+		}
+
+		if pending != nil {
+			var keys []string
+			for _, k := range p {
+				if k.index >= 0 {
+					keys = append(keys, "[]")
+				} else {
+					keys = append(keys, k.key)
+				}
+			}
+			k := strings.Join(keys, "\x00")
+			annotations[k] = mergeAnnotations(annotations[k], pending)
+		}
+		pending = nil
+		return nil
+	})
+	return annotations, err
+}
+
+// parseSchemaComment parses an annotation comment such as
+// "# @schema type=integer minimum=1". Other comments yield nil.
+func parseSchemaComment(comment string) (map[string]interface{}, error) {
+	comment = strings.TrimSpace(strings.TrimLeft(comment, "#"))
+	if !strings.HasPrefix(comment, schemaAnnotation) {
+		return nil, nil
+	}
+	rest := strings.TrimPrefix(comment, schemaAnnotation)
+	if rest != "" && rest[0] != ' ' {
+		return nil, nil
+	}
+
+	a := map[string]interface{}{}
+	for _, field := range splitSchemaFields(rest) {
+		i := strings.Index(field, "=")
+		if i < 0 {
+			a[field] = true
+			continue
+		}
+		k, raw := field[:i], field[i+1:]
+		if k == "" {
+			return nil, errors.Errorf("invalid schema annotation %q", field)
+		}
+		if schemaStringKeywords[k] {
+			a[k] = unquote(raw)
+			continue
+		}
+		var v interface{}
+		if err := yaml.Unmarshal([]byte(raw), &v); err != nil {
+			return nil, errors.Wrapf(err, "invalid value of schema annotation %q", k)
+		}
+		a[k] = v
+	}
+	return a, nil
+}
+
+// splitSchemaFields splits an annotation at spaces outside of quotes and
+// brackets.
+func splitSchemaFields(s string) []string {
+	var (
+		fields []string
+		cur    strings.Builder
+		quote  rune
+		depth  int
+	)
+	for _, r := range s {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '"' || r == '\'':
+			quote = r
+		case r == '[' || r == '{':
+			depth++
+		case r == ']' || r == '}':
+			depth--
+		case r == ' ' && depth == 0:
+			if cur.Len() > 0 {
+				fields = append(fields, cur.String())
+				cur.Reset()
+			}
+			continue
+		}
+		cur.WriteRune(r)
+	}
+	if cur.Len() > 0 {
+		fields = append(fields, cur.String())
+	}
+	return fields
+}
+
+func unquote(s string) string {
+	if len(s) >= 2 && (s[0] == '"' || s[0] == '\'') && s[len(s)-1] == s[0] {
+		return s[1 : len(s)-1]
+	}
+	return s
+}
+
+func mergeAnnotations(a, b map[string]interface{}) map[string]interface{} {
+	if a == nil {
+		a = map[string]interface{}{}
+	}
+	for k, v := range b {
+		a[k] = v
+	}
+	return a
+}
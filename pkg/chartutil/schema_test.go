@@ -0,0 +1,115 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chartutil
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"helm.sh/helm/v3/pkg/chart"
+)
+
+func TestParseSchemaAnnotations(t *testing.T) {
+	values := `
+# A plain comment.
+# @schema minimum=1 required
+replicas: 1
+image:
+  tag: "1.0"  # @schema pattern="^[0-9.]+$" description="The image tag"
+list:
+- name: a
+  # @schema enum=[a, b]
+  kind: a
+- name: b
+other: x # not an annotation
+`
+	got, err := parseSchemaAnnotations([]byte(values))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := map[string]map[string]interface{}{
+		"replicas":           {"minimum": float64(1), "required": true},
+		"image\x00tag":       {"pattern": "^[0-9.]+$", "description": "The image tag"},
+		"list\x00[]\x00kind": {"enum": []interface{}{"a", "b"}},
+	}
+	if !reflect.DeepEqual(got, expect) {
+		t.Errorf("Expected annotations %v, got %v", expect, got)
+	}
+
+	if _, err := parseSchemaAnnotations([]byte("# @schema enum=[a\nkey: 1\n")); err == nil {
+		t.Error("Expected an error for an invalid annotation")
+	}
+}
+
+func TestGenerateSchema(t *testing.T) {
+	sub := &chart.Chart{
+		Metadata: &chart.Metadata{Name: "redis"},
+		Values:   map[string]interface{}{"port": float64(6379)},
+		Raw:      []*chart.File{{Name: ValuesfileName, Data: []byte("port: 6379  # @schema maximum=65535\n")}},
+	}
+	withSchema := &chart.Chart{
+		Metadata: &chart.Metadata{Name: "db"},
+		Schema:   []byte(`{"$schema": "http://json-schema.org/draft-07/schema#", "type": "object", "required": ["password"]}`),
+	}
+	parent := &chart.Chart{
+		Metadata: &chart.Metadata{
+			Name: "app",
+			Dependencies: []*chart.Dependency{
+				{Name: "redis", Alias: "cache"},
+				{Name: "db"},
+			},
+		},
+		Values: map[string]interface{}{
+			"name":  "app",
+			"cache": map[string]interface{}{"port": float64(6380)},
+		},
+		Raw: []*chart.File{{Name: ValuesfileName, Data: []byte("# @schema required\nname: app\ncache:\n  port: 6380\n")}},
+	}
+	parent.AddDependency(sub, withSchema)
+
+	b, err := GenerateSchema(parent)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got map[string]interface{}
+	if err := json.Unmarshal(b, &got); err != nil {
+		t.Fatal(err)
+	}
+
+	expect := map[string]interface{}{
+		"$schema":  schemaDraft,
+		"type":     "object",
+		"required": []interface{}{"name"},
+		"properties": map[string]interface{}{
+			"name": map[string]interface{}{"type": "string"},
+			"cache": map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"port": map[string]interface{}{"type": "integer", "maximum": float64(65535)},
+				},
+			},
+			"db": map[string]interface{}{
+				"type":     "object",
+				"required": []interface{}{"password"},
+			},
+		},
+	}
+	if !reflect.DeepEqual(got, expect) {
+		t.Errorf("Expected schema %v, got %v", expect, got)
+	}
+}
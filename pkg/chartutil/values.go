@@ -129,6 +129,9 @@ type ReleaseOptions struct {
 	Revision  int
 	IsUpgrade bool
 	IsInstall bool
+	// SchemaDefaults applies the defaults of the values schemas of the chart
+	// to the values missing after coalescing.
+	SchemaDefaults bool
 }
 
 // ToRenderValues composes the struct from the data coming from the Releases, Charts and Values files
@@ -156,6 +159,12 @@ func ToRenderValues(chrt *chart.Chart, chrtVals map[string]interface{}, options
 		return top, err
 	}
 
+	if options.SchemaDefaults {
+		if err := ApplySchemaDefaults(chrt, vals); err != nil {
+			return top, errors.Wrap(err, "unable to apply schema defaults")
+		}
+	}
+
 	if err := ValidateAgainstSchema(chrt, vals); err != nil {
 		errFmt := "values don't meet the specifications of the schema(s) in the following chart(s):\n%s"
 		return top, fmt.Errorf(errFmt, err.Error())
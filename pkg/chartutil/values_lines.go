@@ -0,0 +1,146 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chartutil
+
+import (
+	"bufio"
+	"bytes"
+	"strconv"
+	"strings"
+)
+
+// valuesKey is an element of the path of a value in a values file: either a
+// map key or, if index is not negative, a list item.
+type valuesKey struct {
+	key   string
+	index int
+}
+
+// scanValuesLines calls fn for every line of a values file with the path of the
+// value defined on the line and the comment of the line, if any. The path is
+// nil for lines which do not define a value, such as comments and blank lines.
+//
+// Only block style YAML, as used by values files, is understood; flow style
+// maps and lists are treated as a single value.
+func scanValuesLines(data []byte, fn func(p []valuesKey, line int, comment string) error) error {
+	type level struct {
+		indent int
+		valuesKey
+	}
+	var stack []level
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for n := 1; scanner.Scan(); n++ {
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || trimmed == "---" || strings.HasPrefix(trimmed, "#") {
+			if err := fn(nil, n, trimmed); err != nil {
+				return err
+			}
+			continue
+		}
+
+		indent := len(line) - len(strings.TrimLeft(line, " "))
+		content := trimmed
+
+		// A list item is a level of its own, nested in the key above it even
+		// if both are indented alike. Any key following the dash is nested in
+		// the item.
+		for strings.HasPrefix(content, "- ") || content == "-" {
+			index := 0
+			for len(stack) > 0 {
+				top := stack[len(stack)-1]
+				if top.indent < indent || (top.indent == indent && top.index < 0) {
+					break
+				}
+				if top.indent == indent {
+					index = top.index + 1
+				}
+				stack = stack[:len(stack)-1]
+			}
+			stack = append(stack, level{indent, valuesKey{index: index}})
+			rest := strings.TrimLeft(strings.TrimPrefix(content, "-"), " ")
+			indent += len(content) - len(rest)
+			content = rest
+		}
+
+		key, comment := splitValuesLine(content)
+		if key != "" {
+			for len(stack) > 0 && stack[len(stack)-1].indent >= indent {
+				stack = stack[:len(stack)-1]
+			}
+			stack = append(stack, level{indent, valuesKey{key: key, index: -1}})
+		}
+		if len(stack) == 0 {
+			continue
+		}
+
+		p := make([]valuesKey, len(stack))
+		for i, l := range stack {
+			p[i] = l.valuesKey
+		}
+		if err := fn(p, n, comment); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+// splitValuesLine returns the key of a line of a values file, if any, and its
+// trailing comment.
+func splitValuesLine(content string) (key, comment string) {
+	if i := strings.Index(content, " #"); i >= 0 {
+		content, comment = content[:i], strings.TrimSpace(content[i+1:])
+	}
+	if strings.HasPrefix(content, "\"") || strings.HasPrefix(content, "'") {
+		quote := content[:1]
+		if end := strings.Index(content[1:], quote); end >= 0 && strings.HasPrefix(content[end+2:], ":") {
+			return content[1 : end+1], comment
+		}
+		return "", comment
+	}
+	if i := strings.Index(content, ":"); i > 0 && (i == len(content)-1 || content[i+1] == ' ') {
+		return content[:i], comment
+	}
+	return "", comment
+}
+
+// ValuesFileLine returns the line of a values file on which the value at the
+// given path is defined. List items are referred to by their index. If the
+// value itself is not found, the line of its closest parent is returned, or 0
+// if there is none.
+func ValuesFileLine(data []byte, path []string) int {
+	best, bestLen := 0, 0
+	scanValuesLines(data, func(p []valuesKey, n int, _ string) error {
+		matched := 0
+		for matched < len(p) && matched < len(path) && p[matched].matches(path[matched]) {
+			matched++
+		}
+		if matched > bestLen {
+			best, bestLen = n, matched
+		}
+		return nil
+	})
+	return best
+}
+
+func (k valuesKey) matches(s string) bool {
+	if k.index >= 0 {
+		return strconv.Itoa(k.index) == s
+	}
+	return k.key == s
+}
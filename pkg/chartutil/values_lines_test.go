@@ -0,0 +1,54 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chartutil
+
+import "testing"
+
+func TestValuesFileLine(t *testing.T) {
+	data := []byte(`# comment
+image:
+  repository: nginx
+  tag: "1.0"  # the tag
+
+list:
+- name: a
+  port: 80
+- name: b
+  nested:
+    - x
+    - y
+"quoted.key": 1
+`)
+	tests := []struct {
+		path []string
+		line int
+	}{
+		{[]string{"image"}, 2},
+		{[]string{"image", "tag"}, 4},
+		{[]string{"image", "missing"}, 2},
+		{[]string{"list", "0", "port"}, 8},
+		{[]string{"list", "1", "name"}, 9},
+		{[]string{"list", "1", "nested", "1"}, 12},
+		{[]string{"quoted.key"}, 13},
+		{[]string{"missing"}, 0},
+	}
+	for _, tt := range tests {
+		if got := ValuesFileLine(data, tt.path); got != tt.line {
+			t.Errorf("%v: expected line %d, got %d", tt.path, tt.line, got)
+		}
+	}
+}
@@ -18,6 +18,7 @@ package output
 
 import (
 	"encoding/json"
+	"encoding/xml"
 	"fmt"
 	"io"
 
@@ -33,6 +34,8 @@ const (
 	Table Format = "table"
 	JSON  Format = "json"
 	YAML  Format = "yaml"
+	SARIF Format = "sarif"
+	JUnit Format = "junit"
 )
 
 // Formats returns a list of the string representation of the supported formats
@@ -40,6 +43,12 @@ func Formats() []string {
 	return []string{Table.String(), JSON.String(), YAML.String()}
 }
 
+// ReportFormats returns a list of the string representation of the formats
+// supported by a ReportWriter
+func ReportFormats() []string {
+	return append(Formats(), SARIF.String(), JUnit.String())
+}
+
 // ErrInvalidFormatType is returned when an unsupported format type is used
 var ErrInvalidFormatType = fmt.Errorf("invalid format type")
 
@@ -58,6 +67,14 @@ func (o Format) Write(out io.Writer, w Writer) error {
 		return w.WriteJSON(out)
 	case YAML:
 		return w.WriteYAML(out)
+	case SARIF:
+		if rw, ok := w.(ReportWriter); ok {
+			return rw.WriteSARIF(out)
+		}
+	case JUnit:
+		if rw, ok := w.(ReportWriter); ok {
+			return rw.WriteJUnit(out)
+		}
 	}
 	return ErrInvalidFormatType
 }
@@ -78,6 +95,19 @@ func ParseFormat(s string) (out Format, err error) {
 	return
 }
 
+// ParseReportFormat takes a raw string and returns the matching Format,
+// including the formats only supported by a ReportWriter.
+// If the format does not exists, ErrInvalidFormatType is returned
+func ParseReportFormat(s string) (Format, error) {
+	switch s {
+	case SARIF.String():
+		return SARIF, nil
+	case JUnit.String():
+		return JUnit, nil
+	}
+	return ParseFormat(s)
+}
+
 // Writer is an interface that any type can implement to write supported formats
 type Writer interface {
 	// WriteTable will write tabular output into the given io.Writer, returning
@@ -91,6 +121,18 @@ type Writer interface {
 	WriteYAML(out io.Writer) error
 }
 
+// ReportWriter is a Writer of reports of problems, such as lint messages,
+// which can also be written in the formats of code scanning and CI tools
+type ReportWriter interface {
+	Writer
+	// WriteSARIF will write SARIF 2.1.0 formatted output into the given
+	// io.Writer, returning an error if any occur
+	WriteSARIF(out io.Writer) error
+	// WriteJUnit will write JUnit XML formatted output into the given
+	// io.Writer, returning an error if any occur
+	WriteJUnit(out io.Writer) error
+}
+
 // EncodeJSON is a helper function to decorate any error message with a bit more
 // context and avoid writing the same code over and over for printers.
 func EncodeJSON(out io.Writer, obj interface{}) error {
@@ -117,6 +159,24 @@ func EncodeYAML(out io.Writer, obj interface{}) error {
 	return nil
 }
 
+// EncodeXML is a helper function to decorate any error message with a bit more
+// context and avoid writing the same code over and over for printers
+func EncodeXML(out io.Writer, obj interface{}) error {
+	if _, err := io.WriteString(out, xml.Header); err != nil {
+		return errors.Wrap(err, "unable to write XML output")
+	}
+	enc := xml.NewEncoder(out)
+	enc.Indent("", "  ")
+	if err := enc.Encode(obj); err != nil {
+		return errors.Wrap(err, "unable to write XML output")
+	}
+	_, err := io.WriteString(out, "\n")
+	if err != nil {
+		return errors.Wrap(err, "unable to write XML output")
+	}
+	return nil
+}
+
 // EncodeTable is a helper function to decorate any error message with a bit
 // more context and avoid writing the same code over and over for printers
 func EncodeTable(out io.Writer, table *uitable.Table) error {
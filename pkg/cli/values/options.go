@@ -22,24 +22,66 @@ import (
 	"os"
 	"strings"
 
+	"github.com/mitchellh/copystructure"
 	"github.com/pkg/errors"
 	"sigs.k8s.io/yaml"
 
+	"helm.sh/helm/v3/pkg/chartutil"
 	"helm.sh/helm/v3/pkg/getter"
 	"helm.sh/helm/v3/pkg/strvals"
 )
 
 type Options struct {
-	ValueFiles   []string
-	StringValues []string
-	Values       []string
-	FileValues   []string
+	ValueFiles    []string
+	PatchFiles    []string
+	StringValues  []string
+	Values        []string
+	FileValues    []string
+	JSONValues    []string
+	ReleaseValues []string
+
+	// ReleaseStorage gives access to the releases referenced by ReleaseValues.
+	// It is required when ReleaseValues is set.
+	ReleaseStorage ReleaseStorage
 }
 
-// MergeValues merges values from files specified via -f/--values and directly
-// via --set, --set-string, or --set-file, marshaling them to YAML
+// MergeValues merges values from files specified via -f/--values, applies the
+// patches specified via --values-patch, and merges the values from other
+// releases via --set-from-release and directly via --set-json, --set,
+// --set-string, or --set-file, marshaling them to YAML
 func (opts *Options) MergeValues(p getter.Providers) (map[string]interface{}, error) {
+	vals, _, err := opts.merge(p, false)
+	return vals, err
+}
+
+// MergeLayeredValues merges values like MergeValues, and also returns the
+// values of every source separately, in increasing order of precedence. They
+// describe where the merged values came from, see chartutil.ExplainValues.
+// Every file and release is read once.
+func (opts *Options) MergeLayeredValues(p getter.Providers) (map[string]interface{}, []chartutil.ValuesLayer, error) {
+	return opts.merge(p, true)
+}
+
+// merge merges the values, and returns the layers of the sources if withLayers
+// is set. Building them costs a copy of every source and a second parse of
+// every keyed value.
+func (opts *Options) merge(p getter.Providers, withLayers bool) (map[string]interface{}, []chartutil.ValuesLayer, error) {
 	base := map[string]interface{}{}
+	var layers []chartutil.ValuesLayer
+
+	// The values of a layer are copied, as later sources modify base, which
+	// shares them.
+	addLayer := func(origin string, keyed bool, values map[string]interface{}) error {
+		if !withLayers {
+			return nil
+		}
+		v, err := copystructure.Copy(values)
+		if err != nil {
+			return err
+		}
+		layers = append(layers, chartutil.ValuesLayer{Origin: origin, Keyed: keyed, Values: v.(map[string]interface{})})
+		return nil
+	}
 
 	// User specified a values files via -f/--values
 	for _, filePath := range opts.ValueFiles {
@@ -47,45 +89,122 @@ func (opts *Options) MergeValues(p getter.Providers) (map[string]interface{}, er
 
 		bytes, err := readFile(filePath, p)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 
 		if err := yaml.Unmarshal(bytes, &currentMap); err != nil {
-			return nil, errors.Wrapf(err, "failed to parse %s", filePath)
+			return nil, nil, errors.Wrapf(err, "failed to parse %s", filePath)
+		}
+		if err := addLayer("-f "+filePath, false, currentMap); err != nil {
+			return nil, nil, err
 		}
 		// Merge with the previous map
-		base = mergeMaps(base, currentMap)
+		base = MergeMaps(base, currentMap)
 	}
 
-	// User specified a value via --set
-	for _, value := range opts.Values {
-		if err := strvals.ParseInto(value, base); err != nil {
-			return nil, errors.Wrap(err, "failed parsing --set data")
+	// User specified a patch via --values-patch. A patch is described by the
+	// values it changes.
+	for _, filePath := range opts.PatchFiles {
+		bytes, err := readFile(filePath, p)
+		if err != nil {
+			return nil, nil, err
+		}
+		patched, err := applyPatch(base, bytes)
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "failed to apply patch %s", filePath)
+		}
+		if withLayers {
+			if err := addLayer("--values-patch "+filePath, false, diffValues(base, patched)); err != nil {
+				return nil, nil, err
+			}
 		}
+		base = patched
 	}
 
-	// User specified a value via --set-string
-	for _, value := range opts.StringValues {
-		if err := strvals.ParseIntoString(value, base); err != nil {
-			return nil, errors.Wrap(err, "failed parsing --set-string data")
+	// Each value is parsed into base, which it may modify in place, e.g. to
+	// set an element of a list, and into its own layer if layers are built.
+	keyed := func(flag string, values []string, parse func(string, map[string]interface{}) error) error {
+		for _, value := range values {
+			if withLayers {
+				currentMap := map[string]interface{}{}
+				if err := parse(value, currentMap); err != nil {
+					return errors.Wrapf(err, "failed parsing %s data", flag)
+				}
+				if err := addLayer(flag, true, currentMap); err != nil {
+					return err
+				}
+			}
+			if err := parse(value, base); err != nil {
+				return errors.Wrapf(err, "failed parsing %s data", flag)
+			}
 		}
+		return nil
 	}
 
-	// User specified a value via --set-file
-	for _, value := range opts.FileValues {
-		reader := func(rs []rune) (interface{}, error) {
-			bytes, err := readFile(string(rs), p)
-			return string(bytes), err
-		}
-		if err := strvals.ParseIntoFile(value, base, reader); err != nil {
-			return nil, errors.Wrap(err, "failed parsing --set-file data")
+	if len(opts.ReleaseValues) > 0 && opts.ReleaseStorage == nil {
+		return nil, nil, errors.New("--set-from-release is not supported without access to release storage")
+	}
+	releaseReader := readOnce(func(rs []rune) (interface{}, error) {
+		ref, err := parseReleaseRef(string(rs))
+		if err != nil {
+			return nil, err
 		}
+		return ref.resolve(opts.ReleaseStorage)
+	})
+	fileReader := readOnce(func(rs []rune) (interface{}, error) {
+		bytes, err := readFile(string(rs), p)
+		return string(bytes), err
+	})
+
+	// User specified a value of another release via --set-from-release
+	if err := keyed("--set-from-release", opts.ReleaseValues, func(s string, m map[string]interface{}) error {
+		return strvals.ParseIntoFile(s, m, releaseReader)
+	}); err != nil {
+		return nil, nil, err
+	}
+	// User specified a JSON value via --set-json
+	if err := keyed("--set-json", opts.JSONValues, strvals.ParseJSON); err != nil {
+		return nil, nil, err
+	}
+	// User specified a value via --set
+	if err := keyed("--set", opts.Values, strvals.ParseInto); err != nil {
+		return nil, nil, err
+	}
+	// User specified a value via --set-string
+	if err := keyed("--set-string", opts.StringValues, strvals.ParseIntoString); err != nil {
+		return nil, nil, err
+	}
+	// User specified a value via --set-file
+	if err := keyed("--set-file", opts.FileValues, func(s string, m map[string]interface{}) error {
+		return strvals.ParseIntoFile(s, m, fileReader)
+	}); err != nil {
+		return nil, nil, err
 	}
 
-	return base, nil
+	return base, layers, nil
+}
+
+// readOnce returns a reader remembering what reader read, so that a value
+// parsed twice for its layer reads its file or release once.
+func readOnce(reader strvals.RunesValueReader) strvals.RunesValueReader {
+	type result struct {
+		value interface{}
+		err   error
+	}
+	read := map[string]result{}
+	return func(rs []rune) (interface{}, error) {
+		r, ok := read[string(rs)]
+		if !ok {
+			r.value, r.err = reader(rs)
+			read[string(rs)] = r
+		}
+		return r.value, r.err
+	}
 }
 
-func mergeMaps(a, b map[string]interface{}) map[string]interface{} {
+// MergeMaps merges b into a, with the values of b taking precedence. Nested
+// maps are merged recursively.
+func MergeMaps(a, b map[string]interface{}) map[string]interface{} {
 	out := make(map[string]interface{}, len(a))
 	for k, v := range a {
 		out[k] = v
@@ -94,7 +213,7 @@ func mergeMaps(a, b map[string]interface{}) map[string]interface{} {
 		if v, ok := v.(map[string]interface{}); ok {
 			if bv, ok := out[k]; ok {
 				if bv, ok := bv.(map[string]interface{}); ok {
-					out[k] = mergeMaps(bv, v)
+					out[k] = MergeMaps(bv, v)
 					continue
 				}
 			}
@@ -17,8 +17,19 @@ limitations under the License.
 package values
 
 import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"reflect"
 	"testing"
+
+	"github.com/pkg/errors"
+
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/storage"
+	"helm.sh/helm/v3/pkg/storage/driver"
 )
 
 func TestMergeValues(t *testing.T) {
@@ -43,25 +54,25 @@ func TestMergeValues(t *testing.T) {
 		"testing": "fun",
 	}
 
-	testMap := mergeMaps(flatMap, nestedMap)
+	testMap := MergeMaps(flatMap, nestedMap)
 	equal := reflect.DeepEqual(testMap, nestedMap)
 	if !equal {
 		t.Errorf("Expected a nested map to overwrite a flat value. Expected: %v, got %v", nestedMap, testMap)
 	}
 
-	testMap = mergeMaps(nestedMap, flatMap)
+	testMap = MergeMaps(nestedMap, flatMap)
 	equal = reflect.DeepEqual(testMap, flatMap)
 	if !equal {
 		t.Errorf("Expected a flat value to overwrite a map. Expected: %v, got %v", flatMap, testMap)
 	}
 
-	testMap = mergeMaps(nestedMap, anotherNestedMap)
+	testMap = MergeMaps(nestedMap, anotherNestedMap)
 	equal = reflect.DeepEqual(testMap, anotherNestedMap)
 	if !equal {
 		t.Errorf("Expected a nested map to overwrite another nested map. Expected: %v, got %v", anotherNestedMap, testMap)
 	}
 
-	testMap = mergeMaps(anotherFlatMap, anotherNestedMap)
+	testMap = MergeMaps(anotherFlatMap, anotherNestedMap)
 	expectedMap := map[string]interface{}{
 		"testing": "fun",
 		"foo":     "bar",
@@ -75,3 +86,312 @@ func TestMergeValues(t *testing.T) {
 		t.Errorf("Expected a map with different keys to merge properly with another map. Expected: %v, got %v", expectedMap, testMap)
 	}
 }
+
+func TestMergeValuesFromRelease(t *testing.T) {
+	store := storage.Init(driver.NewMemory())
+	for _, rel := range []*release.Release{
+		{
+			Name:      "postgres-prod",
+			Namespace: "default",
+			Version:   1,
+			Info: &release.Info{
+				Status:  release.StatusDeployed,
+				Outputs: map[string]interface{}{"host": "postgres-prod.default.svc"},
+			},
+			Chart: &chart.Chart{
+				Metadata: &chart.Metadata{Name: "postgres", Version: "0.1.0"},
+				Values:   map[string]interface{}{"service": map[string]interface{}{"port": 5432}},
+			},
+		},
+		{
+			Name:      "redis-dev",
+			Namespace: "default",
+			Version:   1,
+			Info:      &release.Info{Status: release.StatusFailed},
+		},
+	} {
+		if err := store.Create(rel); err != nil {
+			t.Fatal(err)
+		}
+	}
+	stores := func(namespace string) (*storage.Storage, error) {
+		if namespace != "" {
+			return nil, errors.Errorf("namespace %q not allowed", namespace)
+		}
+		return store, nil
+	}
+
+	opts := &Options{
+		ReleaseValues:  []string{"db.port=postgres-prod:.Values.service.port,db.host=postgres-prod:.Outputs.host"},
+		Values:         []string{"db.user=app"},
+		ReleaseStorage: stores,
+	}
+	vals, err := opts.MergeValues(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]interface{}{
+		"db": map[string]interface{}{
+			"port": 5432,
+			"host": "postgres-prod.default.svc",
+			"user": "app",
+		},
+	}
+	if !reflect.DeepEqual(vals, expected) {
+		t.Errorf("Expected %v, got %v", expected, vals)
+	}
+
+	for _, value := range []string{
+		"db=redis-dev:.Values",
+		"db=missing:.Values",
+		"db=postgres-prod:.Values.service.name",
+		"db=postgres-prod:.Chart.Name",
+		"db=postgres-prod",
+		"db=other/postgres-prod:.Values",
+	} {
+		opts := &Options{ReleaseValues: []string{value}, ReleaseStorage: stores}
+		if _, err := opts.MergeValues(nil); err == nil {
+			t.Errorf("Expected an error for %q", value)
+		}
+	}
+
+	opts = &Options{ReleaseValues: []string{"db=postgres-prod:.Values"}}
+	if _, err := opts.MergeValues(nil); err == nil {
+		t.Error("Expected an error without release storage")
+	}
+}
+
+func TestMergeJSONValues(t *testing.T) {
+	opts := &Options{
+		JSONValues: []string{`sidecars=[{"name":"proxy","ports":[80]}]`},
+		Values:     []string{"sidecars[0].name=envoy", "pointer:/podAnnotations/prometheus.io~1scrape=true"},
+	}
+	vals, err := opts.MergeValues(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]interface{}{
+		"sidecars": []interface{}{
+			map[string]interface{}{"name": "envoy", "ports": []interface{}{float64(80)}},
+		},
+		"podAnnotations": map[string]interface{}{"prometheus.io/scrape": true},
+	}
+	if !reflect.DeepEqual(vals, expected) {
+		t.Errorf("Expected %v, got %v", expected, vals)
+	}
+}
+
+func TestMergeLayeredValues(t *testing.T) {
+	opts := &Options{
+		Values:       []string{"image.tag=2.1,replicas=3", "debug=null"},
+		StringValues: []string{"image.tag=2.2"},
+	}
+	vals, layers, err := opts.MergeLayeredValues(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedVals := map[string]interface{}{
+		"image":    map[string]interface{}{"tag": "2.2"},
+		"replicas": int64(3),
+		"debug":    nil,
+	}
+	if !reflect.DeepEqual(vals, expectedVals) {
+		t.Errorf("Expected %v, got %v", expectedVals, vals)
+	}
+
+	expected := []chartutil.ValuesLayer{
+		{Origin: "--set", Keyed: true, Values: map[string]interface{}{
+			"image":    map[string]interface{}{"tag": "2.1"},
+			"replicas": int64(3),
+		}},
+		{Origin: "--set", Keyed: true, Values: map[string]interface{}{"debug": nil}},
+		{Origin: "--set-string", Keyed: true, Values: map[string]interface{}{
+			"image": map[string]interface{}{"tag": "2.2"},
+		}},
+	}
+	if !reflect.DeepEqual(layers, expected) {
+		t.Errorf("Expected %v, got %v", expected, layers)
+	}
+
+	merged, err := opts.MergeValues(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(merged, vals) {
+		t.Errorf("Expected MergeValues to merge %v, got %v", vals, merged)
+	}
+}
+
+func TestMergeValuesPatch(t *testing.T) {
+	dir, err := ioutil.TempDir("", "helm-values-patch")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	files := map[string]string{
+		"values.yaml": `
+image:
+  repository: nginx
+  tag: "1.0"
+annotations:
+  kubernetes.io/ingress.class: nginx
+sidecars:
+- name: logger
+- name: metrics
+`,
+		"json-patch.yaml": `
+- op: remove
+  path: /annotations/kubernetes.io~1ingress.class
+- op: add
+  path: /sidecars/1
+  value: {name: proxy}
+- op: test
+  path: /sidecars/2/name
+  value: metrics
+- op: replace
+  path: /sidecars/2/name
+  value: monitor
+`,
+		"merge-patch.json": `{"image": {"tag": "2.0", "repository": null}}`,
+		"bad-patch.yaml": `- op: test
+  path: /missing
+  value: 1`,
+		"scalar-patch.yaml": `just a string`,
+	}
+	for name, content := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	opts := &Options{
+		ValueFiles: []string{filepath.Join(dir, "values.yaml")},
+		PatchFiles: []string{filepath.Join(dir, "json-patch.yaml"), filepath.Join(dir, "merge-patch.json")},
+		Values:     []string{"image.pullPolicy=Always"},
+	}
+	vals, err := opts.MergeValues(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]interface{}{
+		"image":       map[string]interface{}{"tag": "2.0", "repository": nil, "pullPolicy": "Always"},
+		"annotations": map[string]interface{}{"kubernetes.io/ingress.class": nil},
+		"sidecars": []interface{}{
+			map[string]interface{}{"name": "logger"},
+			map[string]interface{}{"name": "proxy"},
+			map[string]interface{}{"name": "monitor"},
+		},
+	}
+	if !reflect.DeepEqual(vals, expected) {
+		t.Errorf("Expected %v, got %v", expected, vals)
+	}
+
+	_, layers, err := opts.MergeLayeredValues(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(layers) != 4 {
+		t.Fatalf("Expected 4 layers, got %d", len(layers))
+	}
+	expectedMerge := map[string]interface{}{
+		"image": map[string]interface{}{"tag": "2.0", "repository": nil},
+	}
+	if !reflect.DeepEqual(layers[2].Values, expectedMerge) {
+		t.Errorf("Expected merge patch layer %v, got %v", expectedMerge, layers[2].Values)
+	}
+
+	for _, name := range []string{"bad-patch.yaml", "scalar-patch.yaml"} {
+		opts := &Options{PatchFiles: []string{filepath.Join(dir, name)}}
+		if _, err := opts.MergeValues(nil); err == nil {
+			t.Errorf("Expected an error for %s", name)
+		}
+	}
+}
+
+func TestMergeValuesPatchChartDefaults(t *testing.T) {
+	dir, err := ioutil.TempDir("", "helm-values-patch")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	files := map[string]string{
+		"merge-patch.yaml": "resources: null\nimage:\n  pullPolicy: null\n",
+		"json-patch.yaml": `
+- op: remove
+  path: /nodeSelector
+- op: replace
+  path: /image/tag
+  value: "2.0"
+`,
+	}
+	for name, content := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ch := &chart.Chart{
+		Metadata: &chart.Metadata{Name: "web"},
+		Values: map[string]interface{}{
+			"image":        map[string]interface{}{"repository": "nginx", "tag": "1.0", "pullPolicy": "IfNotPresent"},
+			"resources":    map[string]interface{}{"limits": map[string]interface{}{"cpu": "100m"}},
+			"nodeSelector": map[string]interface{}{"disk": "ssd"},
+		},
+	}
+	opts := &Options{PatchFiles: []string{filepath.Join(dir, "merge-patch.yaml"), filepath.Join(dir, "json-patch.yaml")}}
+	vals, err := opts.MergeValues(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	coalesced, err := chartutil.CoalesceValues(ch, vals)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The values the patches remove are removed from the defaults of the
+	// chart, and those they replace are replaced.
+	expected := chartutil.Values{
+		"image": map[string]interface{}{"repository": "nginx", "tag": "2.0"},
+	}
+	if !reflect.DeepEqual(coalesced, expected) {
+		t.Errorf("Expected %v, got %v", expected, coalesced)
+	}
+}
+
+func TestMergeLayeredValuesFromStdin(t *testing.T) {
+	in, err := ioutil.TempFile("", "helm-values-stdin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(in.Name())
+	if _, err := in.WriteString("image:\n  tag: \"1.0\"\n"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := in.Seek(0, 0); err != nil {
+		t.Fatal(err)
+	}
+	oldStdin := os.Stdin
+	os.Stdin = in
+	defer func() { os.Stdin = oldStdin }()
+
+	opts := &Options{ValueFiles: []string{"-"}, Values: []string{"image.tag=2.0"}}
+	vals, layers, err := opts.MergeLayeredValues(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedVals := map[string]interface{}{"image": map[string]interface{}{"tag": "2.0"}}
+	if !reflect.DeepEqual(vals, expectedVals) {
+		t.Errorf("Expected %v, got %v", expectedVals, vals)
+	}
+	// The values of the file are not those set later with --set.
+	expectedFile := chartutil.ValuesLayer{Origin: "-f -", Values: map[string]interface{}{"image": map[string]interface{}{"tag": "1.0"}}}
+	if len(layers) != 2 || !reflect.DeepEqual(layers[0], expectedFile) {
+		t.Errorf("Expected the layer %v first, got %v", expectedFile, layers)
+	}
+}
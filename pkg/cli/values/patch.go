@@ -0,0 +1,202 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package values
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+
+	jsonpatch "github.com/evanphx/json-patch"
+	"github.com/pkg/errors"
+	"sigs.k8s.io/yaml"
+)
+
+// applyPatch applies a patch document, in YAML or JSON, to the values. A list
+// of operations is an RFC 6902 JSON Patch, a map is an RFC 7386 JSON Merge
+// Patch.
+//
+// The values are those given by the user, which are later coalesced with the
+// values of the chart. A value removed by a patch is therefore set to null, as
+// a null value removes the default of the chart, and a value which only the
+// chart may define can be removed or replaced.
+func applyPatch(vals map[string]interface{}, patch []byte) (map[string]interface{}, error) {
+	patchJSON, err := yaml.YAMLToJSON(patch)
+	if err != nil {
+		return nil, err
+	}
+
+	patchJSON = bytes.TrimSpace(patchJSON)
+	switch {
+	case bytes.HasPrefix(patchJSON, []byte("[")):
+		var ops []map[string]interface{}
+		if err := json.Unmarshal(patchJSON, &ops); err != nil {
+			return nil, errors.Wrap(err, "invalid JSON patch")
+		}
+		for _, op := range ops {
+			if vals, err = applyOperation(vals, op); err != nil {
+				return nil, err
+			}
+		}
+		return vals, nil
+	case bytes.HasPrefix(patchJSON, []byte("{")):
+		var merge map[string]interface{}
+		if err := json.Unmarshal(patchJSON, &merge); err != nil {
+			return nil, errors.Wrap(err, "invalid merge patch")
+		}
+		return mergePatch(vals, merge), nil
+	default:
+		return nil, errors.New("a patch must be either a list of JSON patch operations or a merge patch map")
+	}
+}
+
+// applyOperation applies a JSON Patch operation to the values. Removing a
+// value of a map sets it to null, and replacing a value which is not set sets
+// it, so that the default of the chart is removed or replaced.
+func applyOperation(vals map[string]interface{}, op map[string]interface{}) (map[string]interface{}, error) {
+	kind, _ := op["op"].(string)
+	pointer, _ := op["path"].(string)
+	if keys, ok := mapPointer(vals, pointer); ok {
+		switch {
+		case kind == "remove":
+			return setPath(vals, keys, nil), nil
+		case kind == "replace" && !isSet(vals, keys):
+			return setPath(vals, keys, op["value"]), nil
+		}
+	}
+
+	opJSON, err := json.Marshal([]interface{}{op})
+	if err != nil {
+		return nil, err
+	}
+	patch, err := jsonpatch.DecodePatch(opJSON)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid JSON patch")
+	}
+	doc, err := json.Marshal(vals)
+	if err != nil {
+		return nil, err
+	}
+	if doc, err = patch.Apply(doc); err != nil {
+		return nil, err
+	}
+	out := map[string]interface{}{}
+	if err := yaml.Unmarshal(doc, &out); err != nil {
+		return nil, errors.Wrap(err, "patch did not result in a map of values")
+	}
+	return out, nil
+}
+
+// mapPointer returns the keys of a JSON pointer to a value of a map, unless
+// it leads through a value of the values which is not a map, such as a list.
+func mapPointer(vals map[string]interface{}, pointer string) ([]string, bool) {
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, false
+	}
+	keys := strings.Split(pointer[1:], "/")
+	var v interface{} = vals
+	for i, k := range keys {
+		keys[i] = strings.NewReplacer("~1", "/", "~0", "~").Replace(k)
+		if v == nil {
+			continue
+		}
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		v = m[keys[i]]
+	}
+	return keys, true
+}
+
+// isSet returns whether the values set the value at the keys to something else
+// than null.
+func isSet(vals map[string]interface{}, keys []string) bool {
+	var v interface{} = vals
+	for _, k := range keys {
+		m, _ := v.(map[string]interface{})
+		if v = m[k]; v == nil {
+			return false
+		}
+	}
+	return true
+}
+
+// setPath sets the value at the keys, creating the maps on the way.
+func setPath(vals map[string]interface{}, keys []string, value interface{}) map[string]interface{} {
+	out := make(map[string]interface{}, len(vals)+1)
+	for k, v := range vals {
+		out[k] = v
+	}
+	if len(keys) == 1 {
+		out[keys[0]] = value
+		return out
+	}
+	child, _ := out[keys[0]].(map[string]interface{})
+	out[keys[0]] = setPath(child, keys[1:], value)
+	return out
+}
+
+// mergePatch applies an RFC 7386 JSON Merge Patch to the values, except that
+// the values removed by null are set to null.
+func mergePatch(vals, patch map[string]interface{}) map[string]interface{} {
+	out := make(map[string]interface{}, len(vals)+len(patch))
+	for k, v := range vals {
+		out[k] = v
+	}
+	for k, pv := range patch {
+		pm, ok := pv.(map[string]interface{})
+		if !ok {
+			out[k] = pv
+			continue
+		}
+		vm, _ := out[k].(map[string]interface{})
+		out[k] = mergePatch(vm, pm)
+	}
+	return out
+}
+
+// diffValues returns the values of b which differ from a. Keys of a missing in
+// b are set to nil, as if they were removed with a null value.
+func diffValues(a, b map[string]interface{}) map[string]interface{} {
+	out := map[string]interface{}{}
+	for k, bv := range b {
+		av, ok := a[k]
+		if !ok {
+			out[k] = bv
+			continue
+		}
+		am, aok := av.(map[string]interface{})
+		bm, bok := bv.(map[string]interface{})
+		if aok && bok {
+			if d := diffValues(am, bm); len(d) > 0 {
+				out[k] = d
+			}
+			continue
+		}
+		if !reflect.DeepEqual(av, bv) {
+			out[k] = bv
+		}
+	}
+	for k := range a {
+		if _, ok := b[k]; !ok {
+			out[k] = nil
+		}
+	}
+	return out
+}
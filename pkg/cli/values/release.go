@@ -0,0 +1,128 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package values
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/storage"
+)
+
+// ReleaseStorage returns the release storage of the given namespace. An empty
+// namespace refers to the namespace of the release being installed.
+type ReleaseStorage func(namespace string) (*storage.Storage, error)
+
+// releaseRef is a reference to a value of a deployed release, as given to
+// --set-from-release in the form [NAMESPACE/]RELEASE:PATH.
+type releaseRef struct {
+	namespace string
+	name      string
+	// source is either "Values" or "Outputs".
+	source string
+	path   []string
+}
+
+func parseReleaseRef(ref string) (*releaseRef, error) {
+	i := strings.Index(ref, ":")
+	if i < 0 {
+		return nil, errors.Errorf("invalid release reference %q: must be of the form [NAMESPACE/]RELEASE:.Values.PATH or [NAMESPACE/]RELEASE:.Outputs.PATH", ref)
+	}
+
+	r := &releaseRef{name: ref[:i]}
+	if j := strings.Index(r.name, "/"); j >= 0 {
+		r.namespace, r.name = r.name[:j], r.name[j+1:]
+		if r.namespace == "" {
+			return nil, errors.Errorf("invalid release reference %q: namespace must not be empty", ref)
+		}
+	}
+	if r.name == "" {
+		return nil, errors.Errorf("invalid release reference %q: release name must not be empty", ref)
+	}
+
+	path := strings.Split(strings.TrimPrefix(ref[i+1:], "."), ".")
+	switch path[0] {
+	case "Values", "Outputs":
+		r.source, r.path = path[0], path[1:]
+	default:
+		return nil, errors.Errorf("invalid release reference %q: path must start with .Values or .Outputs", ref)
+	}
+	for _, p := range r.path {
+		if p == "" {
+			return nil, errors.Errorf("invalid release reference %q: empty path element", ref)
+		}
+	}
+	return r, nil
+}
+
+func (r *releaseRef) String() string {
+	name := r.name
+	if r.namespace != "" {
+		name = r.namespace + "/" + name
+	}
+	return name + ":." + strings.Join(append([]string{r.source}, r.path...), ".")
+}
+
+// resolve looks up the referenced value in the last deployed revision of the
+// release. It fails if the release has no deployed revision or if the value
+// does not exist.
+func (r *releaseRef) resolve(stores ReleaseStorage) (interface{}, error) {
+	store, err := stores(r.namespace)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to access releases for %s", r)
+	}
+	rel, err := store.Deployed(r.name)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to resolve %s", r)
+	}
+
+	var v interface{}
+	switch r.source {
+	case "Values":
+		v = rel.Config
+		if rel.Chart != nil {
+			vals, err := chartutil.CoalesceValues(rel.Chart, rel.Config)
+			if err != nil {
+				return nil, errors.Wrapf(err, "unable to compute the values of release %q", rel.Name)
+			}
+			v = vals.AsMap()
+		}
+	case "Outputs":
+		v = releaseOutputs(rel)
+	}
+
+	for _, p := range r.path {
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, errors.Errorf("unable to resolve %s: %q is not a map", r, p)
+		}
+		if v, ok = m[p]; !ok {
+			return nil, errors.Errorf("unable to resolve %s: no value for %q", r, p)
+		}
+	}
+	return v, nil
+}
+
+func releaseOutputs(rel *release.Release) map[string]interface{} {
+	if rel.Info == nil || rel.Info.Outputs == nil {
+		return map[string]interface{}{}
+	}
+	return rel.Info.Outputs
+}
@@ -0,0 +1,167 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"fmt"
+	"math/rand"
+	"text/template"
+	"time"
+
+	"github.com/Masterminds/sprig/v3"
+)
+
+// Placeholder is returned by template functions which cannot be made
+// deterministic, such as genPrivateKey, when rendering with a seed. The
+// certificates of genCA, genSelfSignedCert and genSignedCert have it as their
+// certificate and key.
+const Placeholder = "REDACTED"
+
+// certificate is a certificate of genCA, genSelfSignedCert or genSignedCert,
+// whose fields templates use, e.g. '{{ $ca.Cert | b64enc }}'.
+type certificate struct {
+	Cert string
+	Key  string
+}
+
+// DefaultRenderTime is the time of reproducible renderings which are not
+// given one, such as those of 'helm lint'.
+var DefaultRenderTime = time.Unix(0, 0).UTC()
+
+const (
+	alphaChars   = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	numericChars = "0123456789"
+)
+
+// seededFuncMap returns variants of the random template functions which draw
+// from r, a seeded random source, so they return the same values every time
+// the same templates are rendered.
+func seededFuncMap(r *rand.Rand) template.FuncMap {
+	randString := func(chars string) func(int) string {
+		return func(n int) string {
+			b := make([]byte, n)
+			for i := range b {
+				b[i] = chars[r.Intn(len(chars))]
+			}
+			return string(b)
+		}
+	}
+	placeholder := func(...interface{}) string { return Placeholder }
+	placeholderCert := func(...interface{}) certificate { return certificate{Cert: Placeholder, Key: Placeholder} }
+
+	return template.FuncMap{
+		"randAlphaNum": randString(alphaChars + numericChars),
+		"randAlpha":    randString(alphaChars),
+		"randNumeric":  randString(numericChars),
+		"randAscii": func(n int) string {
+			b := make([]byte, n)
+			for i := range b {
+				// Printable ASCII characters, as sprig's randAscii.
+				b[i] = byte(32 + r.Intn(95))
+			}
+			return string(b)
+		},
+		"shuffle": func(s string) string {
+			runes := []rune(s)
+			r.Shuffle(len(runes), func(i, j int) { runes[i], runes[j] = runes[j], runes[i] })
+			return string(runes)
+		},
+		"uuidv4": func() string {
+			var u [16]byte
+			r.Read(u[:])
+			u[6] = u[6]&0x0f | 0x40
+			u[8] = u[8]&0x3f | 0x80
+			return fmt.Sprintf("%x-%x-%x-%x-%x", u[0:4], u[4:6], u[6:8], u[8:10], u[10:])
+		},
+
+		// Keys, serial numbers, salts and initialization vectors are drawn
+		// from a secure source, which cannot be seeded.
+		"genPrivateKey":     placeholder,
+		"htpasswd":          placeholder,
+		"encryptAES":        placeholder,
+		"genCA":             placeholderCert,
+		"genSelfSignedCert": placeholderCert,
+		"genSignedCert":     placeholderCert,
+	}
+}
+
+// fixedTimeFuncMap returns variants of the date template functions which use
+// now as the current time, also for the dates they cannot read, and use UTC
+// rather than the local time zone.
+func fixedTimeFuncMap(now time.Time) template.FuncMap {
+	dateInZone := sprig.TxtFuncMap()["dateInZone"].(func(string, interface{}, string) string)
+	now = now.UTC()
+
+	// toTime returns the time of the dates sprig reads, and now otherwise.
+	toTime := func(date interface{}) time.Time {
+		switch date := date.(type) {
+		case time.Time:
+			return date
+		case *time.Time:
+			return *date
+		case int64:
+			return time.Unix(date, 0)
+		case int:
+			return time.Unix(int64(date), 0)
+		case int32:
+			return time.Unix(int64(date), 0)
+		}
+		return now
+	}
+	inZone := func(layout string, date interface{}, zone string) string {
+		return dateInZone(layout, toTime(date), zone)
+	}
+	toDate := func(layout, str string) (time.Time, error) {
+		return time.ParseInLocation(layout, str, time.UTC)
+	}
+	dateModify := func(modify string, date time.Time) (time.Time, error) {
+		d, err := time.ParseDuration(modify)
+		if err != nil {
+			return time.Time{}, err
+		}
+		return date.Add(d).UTC(), nil
+	}
+	// Dates which cannot be modified are kept, as sprig does.
+	dateModifyOrKeep := func(modify string, date time.Time) time.Time {
+		t, err := dateModify(modify, date)
+		if err != nil {
+			return date.UTC()
+		}
+		return t
+	}
+
+	return template.FuncMap{
+		"now":            func() time.Time { return now },
+		"date":           func(layout string, date interface{}) string { return inZone(layout, date, "UTC") },
+		"htmlDate":       func(date interface{}) string { return inZone("2006-01-02", date, "UTC") },
+		"dateInZone":     inZone,
+		"date_in_zone":   inZone,
+		"htmlDateInZone": func(date interface{}, zone string) string { return inZone("2006-01-02", date, zone) },
+		"toDate": func(layout, str string) time.Time {
+			t, _ := toDate(layout, str)
+			return t
+		},
+		"mustToDate":       toDate,
+		"dateModify":       dateModifyOrKeep,
+		"date_modify":      dateModifyOrKeep,
+		"mustDateModify":   dateModify,
+		"must_date_modify": dateModify,
+		"ago": func(date interface{}) string {
+			return now.Sub(toTime(date)).Round(time.Second).String()
+		},
+	}
+}
@@ -19,12 +19,14 @@ package engine
 import (
 	"fmt"
 	"log"
+	"math/rand"
 	"path"
 	"path/filepath"
 	"regexp"
 	"sort"
 	"strings"
 	"text/template"
+	"time"
 
 	"github.com/pkg/errors"
 	"k8s.io/client-go/rest"
@@ -40,8 +42,36 @@ type Engine struct {
 	Strict bool
 	// In LintMode, some 'required' template values may be missing, so don't fail
 	LintMode bool
+	// RenderSeed, if set, seeds the random template functions, such as
+	// randAlphaNum or uuidv4, so they return the same values on every
+	// rendering. Functions which cannot be seeded, such as genPrivateKey,
+	// return Placeholder instead.
+	RenderSeed *int64
+	// RenderTime, if set, is the time returned by now. Dates are then
+	// formatted in UTC, so the output does not depend on the local time zone.
+	RenderTime *time.Time
+	// StrictValues fails rendering with a MissingValuesError listing every
+	// reference of the templates to a value which is not set, see
+	// Engine.MissingValues.
+	StrictValues bool
+	// OptionalValues are the paths of the values which may be missing in
+	// StrictValues mode, e.g. "ingress.annotations". A path allows the values
+	// below it as well, and '*' matches any key.
+	OptionalValues []string
+	// LookupFixtures, if set, are queried by the lookup function instead of
+	// the Kubernetes API.
+	LookupFixtures *Fixtures
 	// the rest config to connect to te kubernetes api
 	config *rest.Config
+	// rand is the source of the seeded random functions, shared by all
+	// templates of a rendering.
+	rand *rand.Rand
+}
+
+// New creates an engine which connects to the Kubernetes API described by
+// config, e.g. for the lookup function.
+func New(config *rest.Config) Engine {
+	return Engine{config: config}
 }
 
 // Render takes a chart, optional values, and value overrides, and attempts to render the Go templates.
@@ -65,6 +95,18 @@ type Engine struct {
 // bar chart during render time.
 func (e Engine) Render(chrt *chart.Chart, values chartutil.Values) (map[string]string, error) {
 	tmap := allTemplates(chrt, values)
+	if e.StrictValues {
+		missing, err := e.missingValues(tmap)
+		if err != nil {
+			return map[string]string{}, err
+		}
+		if len(missing) > 0 {
+			return map[string]string{}, MissingValuesError(missing)
+		}
+	}
+	if e.RenderSeed != nil {
+		e.rand = rand.New(rand.NewSource(*e.RenderSeed))
+	}
 	return e.render(tmap)
 }
 
@@ -78,9 +120,7 @@ func Render(chrt *chart.Chart, values chartutil.Values) (map[string]string, erro
 // render the Go templates using the default options. This engine is client aware and so can have template
 // functions that interact with the client
 func RenderWithClient(chrt *chart.Chart, values chartutil.Values, config *rest.Config) (map[string]string, error) {
-	return Engine{
-		config: config,
-	}.Render(chrt, values)
+	return New(config).Render(chrt, values)
 }
 
 // renderable is an object that can be rendered.
@@ -173,9 +213,25 @@ func (e Engine) initFunMap(t *template.Template, referenceTpls map[string]render
 		return val, nil
 	}
 
-	// If we are not linting and have a cluster connection, provide a Kubernetes-backed
+	// Swap the functions whose results differ from one rendering to the
+	// next for reproducible variants.
+	if e.rand != nil {
+		for k, v := range seededFuncMap(e.rand) {
+			funcMap[k] = v
+		}
+	}
+	if e.RenderTime != nil {
+		for k, v := range fixedTimeFuncMap(*e.RenderTime) {
+			funcMap[k] = v
+		}
+	}
+
+	// If we are given fixtures, look up objects in them. Otherwise, if we are not
+	// linting and have a cluster connection, provide a Kubernetes-backed
 	// implementation.
-	if !e.LintMode && e.config != nil {
+	if e.LookupFixtures != nil {
+		funcMap["lookup"] = e.LookupFixtures.Lookup
+	} else if !e.LintMode && e.config != nil {
 		funcMap["lookup"] = NewLookupFunction(e.config)
 	}
 
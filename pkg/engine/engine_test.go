@@ -18,9 +18,12 @@ package engine
 
 import (
 	"fmt"
+	"reflect"
+	"regexp"
 	"strings"
 	"sync"
 	"testing"
+	"time"
 
 	"helm.sh/helm/v3/pkg/chart"
 	"helm.sh/helm/v3/pkg/chartutil"
@@ -126,6 +129,61 @@ func TestRender(t *testing.T) {
 	}
 }
 
+func TestRenderDeterministic(t *testing.T) {
+	c := &chart.Chart{
+		Metadata: &chart.Metadata{Name: "moby", Version: "1.2.3"},
+		Templates: []*chart.File{
+			{Name: "templates/rand", Data: []byte(`{{ randAlphaNum 8 }} {{ randNumeric 4 }} {{ uuidv4 }} {{ tpl "{{ randAlpha 8 }}" . }}`)},
+			{Name: "templates/now", Data: []byte(`{{ now | date "2006-01-02 15:04" }} {{ now | htmlDate }} {{ ago now }} {{ date "2006" "not a date" }}`)},
+			{Name: "templates/dates", Data: []byte(`{{ toDate "2006-01-02 15:04" "2020-05-04 12:30" | dateModify "1h" | date "15:04 MST" }} {{ "2020-05-04" | toDate "2006-01-02" | unixEpoch }}`)},
+			{Name: "templates/crypto", Data: []byte(`{{ genPrivateKey "rsa" }} {{ htpasswd "user" "pass" }}`)},
+			{Name: "templates/certs", Data: []byte(`{{ $ca := genCA "ca" 365 }}{{ $cert := genSignedCert "moby" nil (list "moby.local") 365 $ca }}{{ $ca.Cert }} {{ $cert.Key }} {{ (genSelfSignedCert "moby" nil nil 365).Cert }}`)},
+		},
+	}
+	v, err := chartutil.CoalesceValues(c, map[string]interface{}{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	seed := int64(42)
+	now := time.Date(2020, 5, 4, 12, 30, 0, 0, time.FixedZone("CEST", 2*60*60))
+	render := func(seed int64) map[string]string {
+		out, err := Engine{RenderSeed: &seed, RenderTime: &now}.Render(c, v)
+		if err != nil {
+			t.Fatalf("Failed to render templates: %s", err)
+		}
+		return out
+	}
+
+	out := render(seed)
+	if again := render(seed); !reflect.DeepEqual(out, again) {
+		t.Errorf("Expected the same output for the same seed, got %v and %v", out, again)
+	}
+	if other := render(seed + 1); other["moby/templates/rand"] == out["moby/templates/rand"] {
+		t.Errorf("Expected different random values for another seed, got %q", other["moby/templates/rand"])
+	}
+
+	values := strings.Fields(out["moby/templates/rand"])
+	if len(values) != 4 || len(values[0]) != 8 || len(values[1]) != 4 || len(values[3]) != 8 {
+		t.Fatalf("Unexpected random values %q", values)
+	}
+	if !regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`).MatchString(values[2]) {
+		t.Errorf("Expected a version 4 UUID, got %q", values[2])
+	}
+
+	expect := map[string]string{
+		"moby/templates/now":    "2020-05-04 10:30 2020-05-04 0s 2020",
+		"moby/templates/dates":  "13:30 UTC 1588550400",
+		"moby/templates/crypto": "REDACTED REDACTED",
+		"moby/templates/certs":  "REDACTED REDACTED REDACTED",
+	}
+	for name, data := range expect {
+		if out[name] != data {
+			t.Errorf("Expected %q, got %q", data, out[name])
+		}
+	}
+}
+
 func TestRenderRefsOrdering(t *testing.T) {
 	parentChart := &chart.Chart{
 		Metadata: &chart.Metadata{
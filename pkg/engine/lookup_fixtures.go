@@ -0,0 +1,202 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	utiljson "k8s.io/apimachinery/pkg/util/json"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+)
+
+// Fixtures are Kubernetes objects which the lookup function queries instead
+// of a cluster, so that templates using lookup can be rendered offline.
+type Fixtures struct {
+	objects []map[string]interface{}
+}
+
+// NewFixtures creates fixtures holding the given objects. Lists, such as the
+// output of 'kubectl get -o yaml', are replaced by their items. Objects are
+// converted to JSON values, as those of unstructured.Unstructured, and must
+// be marshalable to JSON.
+func NewFixtures(objects ...map[string]interface{}) (*Fixtures, error) {
+	f := &Fixtures{}
+	for i, obj := range objects {
+		if err := f.add(obj); err != nil {
+			return nil, errors.Wrapf(err, "object %d", i+1)
+		}
+	}
+	return f, nil
+}
+
+// LoadFixtures loads fixtures from a file of YAML or JSON documents, or from
+// all the '.yaml', '.yml' and '.json' files of a directory.
+func LoadFixtures(path string) (*Fixtures, error) {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to load lookup fixtures")
+	}
+	files := []string{path}
+	if fi.IsDir() {
+		files = nil
+		err := filepath.Walk(path, func(p string, fi os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			switch filepath.Ext(p) {
+			case ".yaml", ".yml", ".json":
+				if !fi.IsDir() {
+					files = append(files, p)
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	f := &Fixtures{}
+	for _, file := range files {
+		if err := f.load(file); err != nil {
+			return nil, errors.Wrapf(err, "unable to load lookup fixtures from %s", file)
+		}
+	}
+	return f, nil
+}
+
+func (f *Fixtures) load(file string) error {
+	r, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	d := utilyaml.NewYAMLOrJSONDecoder(r, 4096)
+	for i := 1; ; i++ {
+		var obj map[string]interface{}
+		if err := d.Decode(&obj); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return errors.Wrapf(err, "document %d", i)
+		}
+		if len(obj) == 0 {
+			continue
+		}
+		if err := f.add(obj); err != nil {
+			return errors.Wrapf(err, "document %d", i)
+		}
+	}
+}
+
+func (f *Fixtures) add(obj map[string]interface{}) error {
+	obj, err := toJSONObject(obj)
+	if err != nil {
+		return err
+	}
+	return f.addJSON(obj)
+}
+
+// addJSON adds an object holding JSON values.
+func (f *Fixtures) addJSON(obj map[string]interface{}) error {
+	apiVersion, _ := obj["apiVersion"].(string)
+	kind, _ := obj["kind"].(string)
+	if items, ok := obj["items"].([]interface{}); ok && strings.HasSuffix(kind, "List") {
+		for i, item := range items {
+			o, ok := item.(map[string]interface{})
+			if !ok {
+				return errors.Errorf("item %d is not an object", i+1)
+			}
+			if err := f.addJSON(o); err != nil {
+				return errors.Wrapf(err, "item %d", i+1)
+			}
+		}
+		return nil
+	}
+
+	if apiVersion == "" || kind == "" {
+		return errors.New("apiVersion and kind are required")
+	}
+	if name, _ := fixtureMeta(obj); name == "" {
+		return errors.New("metadata.name is required")
+	}
+	f.objects = append(f.objects, obj)
+	return nil
+}
+
+// toJSONObject returns a copy of obj holding JSON values only, with numbers
+// as int64 or float64, which runtime.DeepCopyJSON can copy.
+func toJSONObject(obj map[string]interface{}) (map[string]interface{}, error) {
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return nil, errors.Wrap(err, "object is not valid JSON")
+	}
+	var out map[string]interface{}
+	if err := utiljson.Unmarshal(data, &out); err != nil {
+		return nil, errors.Wrap(err, "object is not valid JSON")
+	}
+	return out, nil
+}
+
+// Lookup returns the object of the given apiVersion, kind, namespace and
+// name, like the lookup template function. If name is empty, it returns a
+// list of the objects of the given apiVersion and kind in the namespace, or
+// in all namespaces if namespace is empty as well. A missing object is an
+// empty map rather than an error, as it is for a cluster.
+func (f *Fixtures) Lookup(apiVersion, kind, namespace, name string) (map[string]interface{}, error) {
+	items := []interface{}{}
+	for _, obj := range f.objects {
+		if obj["apiVersion"] != apiVersion || obj["kind"] != kind {
+			continue
+		}
+		n, ns := fixtureMeta(obj)
+		if name != "" {
+			if n == name && ns == namespace {
+				// Copy objects so templates cannot modify the fixtures.
+				return runtime.DeepCopyJSON(obj), nil
+			}
+			continue
+		}
+		if namespace == "" || ns == namespace {
+			items = append(items, runtime.DeepCopyJSONValue(obj))
+		}
+	}
+	if name != "" {
+		return map[string]interface{}{}, nil
+	}
+	return map[string]interface{}{
+		"apiVersion": apiVersion,
+		"kind":       kind + "List",
+		"metadata":   map[string]interface{}{"resourceVersion": ""},
+		"items":      items,
+	}, nil
+}
+
+// fixtureMeta returns the name and namespace of an object.
+func fixtureMeta(obj map[string]interface{}) (name, namespace string) {
+	if m, ok := obj["metadata"].(map[string]interface{}); ok {
+		name, _ = m["name"].(string)
+		namespace, _ = m["namespace"].(string)
+	}
+	return name, namespace
+}
@@ -0,0 +1,183 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chartutil"
+)
+
+const fixturesYAML = `apiVersion: v1
+kind: Secret
+metadata:
+  name: db
+  namespace: default
+data:
+  password: c2VjcmV0
+---
+apiVersion: v1
+kind: List
+items:
+  - apiVersion: v1
+    kind: Secret
+    metadata:
+      name: db
+      namespace: other
+  - apiVersion: v1
+    kind: Namespace
+    metadata:
+      name: other
+`
+
+func loadTestFixtures(t *testing.T) *Fixtures {
+	dir, err := ioutil.TempDir("", "helm-fixtures-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	if err := ioutil.WriteFile(filepath.Join(dir, "objects.yaml"), []byte(fixturesYAML), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "README.md"), []byte("not objects"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := LoadFixtures(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return f
+}
+
+func TestFixturesLookup(t *testing.T) {
+	f := loadTestFixtures(t)
+
+	obj, err := f.Lookup("v1", "Secret", "default", "db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := obj["data"].(map[string]interface{})["password"]; got != "c2VjcmV0" {
+		t.Errorf("expected the secret in namespace default, got password %v", got)
+	}
+
+	// Changes to the returned objects do not change the fixtures.
+	obj["data"] = nil
+	if obj, _ = f.Lookup("v1", "Secret", "default", "db"); obj["data"] == nil {
+		t.Error("expected the fixtures to be unchanged")
+	}
+
+	if obj, _ := f.Lookup("v1", "Secret", "default", "missing"); len(obj) != 0 {
+		t.Errorf("expected an empty object, got %v", obj)
+	}
+	if obj, _ := f.Lookup("v1", "Namespace", "", "other"); len(obj) == 0 {
+		t.Error("expected the cluster-scoped namespace")
+	}
+
+	tests := []struct {
+		namespace string
+		count     int
+	}{
+		{"", 2},
+		{"other", 1},
+		{"missing", 0},
+	}
+	for _, tt := range tests {
+		list, err := f.Lookup("v1", "Secret", tt.namespace, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if list["kind"] != "SecretList" {
+			t.Errorf("expected a SecretList, got %v", list["kind"])
+		}
+		if items := list["items"].([]interface{}); len(items) != tt.count {
+			t.Errorf("namespace %q: expected %d items, got %d", tt.namespace, tt.count, len(items))
+		}
+	}
+}
+
+func TestNewFixturesInvalid(t *testing.T) {
+	tests := []struct {
+		obj map[string]interface{}
+		err string
+	}{
+		{map[string]interface{}{"kind": "Secret"}, "object 1: apiVersion and kind are required"},
+		{map[string]interface{}{"apiVersion": "v1", "kind": "Secret"}, "object 1: metadata.name is required"},
+		{map[string]interface{}{"apiVersion": "v1", "kind": "List", "items": []interface{}{"a"}}, "object 1: item 1 is not an object"},
+		{map[string]interface{}{"apiVersion": "v1", "kind": "Secret", "data": make(chan int)}, "object 1: object is not valid JSON: json: unsupported type: chan int"},
+	}
+	for _, tt := range tests {
+		if _, err := NewFixtures(tt.obj); err == nil || err.Error() != tt.err {
+			t.Errorf("expected error %q, got %v", tt.err, err)
+		}
+	}
+}
+
+func TestNewFixturesGoValues(t *testing.T) {
+	f, err := NewFixtures(map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata":   map[string]interface{}{"name": "web", "namespace": "default", "labels": map[string]string{"app": "web"}},
+		"spec":       map[string]interface{}{"replicas": 3, "paused": false},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	obj, err := f.Lookup("apps/v1", "Deployment", "default", "web")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := obj["spec"].(map[string]interface{})["replicas"]; got != int64(3) {
+		t.Errorf("expected replicas int64(3), got %#v", got)
+	}
+	if got := obj["metadata"].(map[string]interface{})["labels"]; !reflect.DeepEqual(got, map[string]interface{}{"app": "web"}) {
+		t.Errorf("expected the labels as a JSON object, got %#v", got)
+	}
+}
+
+func TestRenderLookupFixtures(t *testing.T) {
+	c := &chart.Chart{
+		Metadata: &chart.Metadata{Name: "lookup"},
+		Templates: []*chart.File{
+			{Name: "templates/secret", Data: []byte(`{{ (lookup "v1" "Secret" "default" "db").data.password }}`)},
+			{Name: "templates/list", Data: []byte(`{{ range (lookup "v1" "Secret" "" "").items }}{{ .metadata.namespace }} {{ end }}`)},
+		},
+	}
+	v, err := chartutil.CoalesceValues(c, map[string]interface{}{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	e := Engine{LookupFixtures: loadTestFixtures(t)}
+	out, err := e.Render(c, v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := out["lookup/templates/secret"]; got != "c2VjcmV0" {
+		t.Errorf("expected the password of the fixture, got %q", got)
+	}
+	if got := strings.TrimSpace(out["lookup/templates/list"]); got != "default other" {
+		t.Errorf("expected the namespaces of the fixtures, got %q", got)
+	}
+}
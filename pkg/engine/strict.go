@@ -0,0 +1,543 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"fmt"
+	"path"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"text/template/parse"
+
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chartutil"
+)
+
+// MissingValue is a reference of a template to a value which is not set.
+type MissingValue struct {
+	// Template is the file holding the reference, e.g.
+	// "mychart/templates/deployment.yaml".
+	Template string
+	// Line is the line of the reference in the file.
+	Line int
+	// Path is the referenced value, e.g. ".Values.image.tag".
+	Path string
+}
+
+func (m MissingValue) String() string {
+	return fmt.Sprintf("%s:%d: %s", m.Template, m.Line, m.Path)
+}
+
+// MissingValuesError is returned by Engine.Render in StrictValues mode when
+// the templates reference values which are not set.
+type MissingValuesError []MissingValue
+
+func (e MissingValuesError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "templates reference %d missing value(s):", len(e))
+	for _, m := range e {
+		b.WriteString("\n  ")
+		b.WriteString(m.String())
+	}
+	return b.String()
+}
+
+// ValueReference is a reference of a template to a value.
+type ValueReference struct {
+	// Template is the file holding the reference, e.g.
+	// "mychart/templates/deployment.yaml".
+	Template string
+	// Line is the line of the reference in the file.
+	Line int
+	// Path is the referenced value, e.g. ".Values.image.tag", or "." for
+	// the render values themselves.
+	Path string
+	// Whole is whether the template reads the value as a whole, e.g. by
+	// printing it or giving it to a function, so that any value below it may
+	// be read as well. Values given to 'include', 'template' or 'with' are
+	// not read as a whole, the references below them are followed instead.
+	Whole bool
+}
+
+// tolerantFuncs are the template functions which handle missing values given
+// to them, so references to missing values are intended as arguments.
+var tolerantFuncs = map[string]bool{
+	"coalesce": true,
+	"default":  true,
+	"empty":    true,
+	"required": true,
+}
+
+// MissingValues returns the references of the templates of the chart to
+// values which are not set, except for the ones allowed by OptionalValues.
+//
+// References are found without rendering the templates, by following what
+// dot and variables hold through 'with', 'range', 'include' and 'template'.
+// References whose target cannot be followed, such as those to the elements
+// of a range, are not checked. References which are intended to be missing
+// are not reported either: those in the conditions of 'if', 'with' and
+// 'range', those below a value tested by the enclosing 'if' or 'with', and
+// those given to 'default', 'coalesce', 'empty' or 'required'.
+func (e Engine) MissingValues(chrt *chart.Chart, values chartutil.Values) ([]MissingValue, error) {
+	return e.missingValues(allTemplates(chrt, values))
+}
+
+// ValueReferences returns the references of the templates of the chart to
+// the values, found by following the templates as MissingValues does. The
+// references whose target cannot be followed are not returned, but the
+// values they are taken from are read as a whole.
+func (e Engine) ValueReferences(chrt *chart.Chart, values chartutil.Values) ([]ValueReference, error) {
+	_, refs, err := e.CheckValues(chrt, values)
+	return refs, err
+}
+
+// CheckValues returns both what MissingValues and ValueReferences return,
+// following the templates once.
+func (e Engine) CheckValues(chrt *chart.Chart, values chartutil.Values) ([]MissingValue, []ValueReference, error) {
+	found := map[MissingValue]bool{}
+	refs := map[ValueReference]bool{}
+	if err := e.checkValues(allTemplates(chrt, values), found, refs); err != nil {
+		return nil, nil, err
+	}
+	return sortedMissingValues(found), sortedValueReferences(refs), nil
+}
+
+func sortedValueReferences(found map[ValueReference]bool) []ValueReference {
+	refs := make([]ValueReference, 0, len(found))
+	for r := range found {
+		refs = append(refs, r)
+	}
+	sort.Slice(refs, func(i, j int) bool {
+		a, b := refs[i], refs[j]
+		if a.Template != b.Template {
+			return a.Template < b.Template
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Path < b.Path
+	})
+	return refs
+}
+
+// checkValues follows the templates, recording the references to missing
+// values in found and, if it is not nil, every reference to a value in refs.
+func (e Engine) checkValues(tpls map[string]renderable, found map[MissingValue]bool, refs map[ValueReference]bool) error {
+	t := template.New("gotpl").Funcs(funcMap())
+	keys := sortTemplates(tpls)
+	for _, filename := range keys {
+		if _, err := t.New(filename).Parse(tpls[filename].tpl); err != nil {
+			return cleanupParseError(filename, err)
+		}
+	}
+
+	if found == nil {
+		found = map[MissingValue]bool{}
+	}
+	for _, filename := range keys {
+		// Partials are checked where they are included, with what they are
+		// given.
+		if strings.HasPrefix(path.Base(filename), "_") {
+			continue
+		}
+		c := &valuesChecker{
+			tmpl:     t,
+			vals:     tpls[filename].vals,
+			optional: e.OptionalValues,
+			found:    found,
+			refs:     refs,
+			checked:  map[string]bool{},
+		}
+		c.template(filename, scope{known: true})
+	}
+	return nil
+}
+
+func (e Engine) missingValues(tpls map[string]renderable) ([]MissingValue, error) {
+	found := map[MissingValue]bool{}
+	if err := e.checkValues(tpls, found, nil); err != nil {
+		return nil, err
+	}
+	return sortedMissingValues(found), nil
+}
+
+func sortedMissingValues(found map[MissingValue]bool) []MissingValue {
+	missing := make([]MissingValue, 0, len(found))
+	for m := range found {
+		missing = append(missing, m)
+	}
+	sort.Slice(missing, func(i, j int) bool {
+		a, b := missing[i], missing[j]
+		if a.Template != b.Template {
+			return a.Template < b.Template
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Path < b.Path
+	})
+	return missing
+}
+
+// scope is what dot or a variable holds while checking a template: the path
+// of a value of the render values, such as ["Values", "image"], or unknown.
+type scope struct {
+	path  []string
+	known bool
+}
+
+func (s scope) field(fields ...string) scope {
+	if !s.known {
+		return s
+	}
+	p := make([]string, 0, len(s.path)+len(fields))
+	return scope{path: append(append(p, s.path...), fields...), known: true}
+}
+
+// frame is the state of the check of a block of a template.
+type frame struct {
+	tree *parse.Tree
+	dot  scope
+	vars map[string]scope
+	// guards are the paths tested by the enclosing 'if' and 'with' actions.
+	guards [][]string
+}
+
+func (f *frame) block(dot scope, guards [][]string) *frame {
+	vars := make(map[string]scope, len(f.vars))
+	for k, v := range f.vars {
+		vars[k] = v
+	}
+	return &frame{
+		tree:   f.tree,
+		dot:    dot,
+		vars:   vars,
+		guards: append(append([][]string{}, f.guards...), guards...),
+	}
+}
+
+// valuesChecker finds the references of a template to missing values.
+type valuesChecker struct {
+	tmpl     *template.Template
+	vals     chartutil.Values
+	optional []string
+	found    map[MissingValue]bool
+	// refs, if not nil, records every reference to a value.
+	refs map[ValueReference]bool
+	// checked are the templates already checked with a given dot.
+	checked map[string]bool
+}
+
+func (c *valuesChecker) template(name string, dot scope) {
+	key := name + "\x00" + strconv.FormatBool(dot.known) + "\x00" + strings.Join(dot.path, ".")
+	if c.checked[key] {
+		return
+	}
+	c.checked[key] = true
+
+	t := c.tmpl.Lookup(name)
+	if t == nil || t.Tree == nil {
+		return
+	}
+	f := &frame{tree: t.Tree, dot: dot, vars: map[string]scope{"$": dot}}
+	c.walk(f, t.Tree.Root)
+}
+
+func (c *valuesChecker) walk(f *frame, node parse.Node) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, child := range n.Nodes {
+			c.walk(f, child)
+		}
+	case *parse.ActionNode:
+		// The value of an action is printed, unless it is assigned.
+		s, _ := c.pipe(f, n.Pipe, false)
+		if len(n.Pipe.Decl) == 0 {
+			c.read(f, s, n)
+		}
+	case *parse.IfNode:
+		body := f.block(f.dot, nil)
+		_, refs := c.pipe(body, n.Pipe, true)
+		body.guards = append(body.guards, refs...)
+		c.walk(body, n.List)
+		c.walk(f.block(f.dot, nil), n.ElseList)
+	case *parse.WithNode:
+		body := f.block(f.dot, nil)
+		dot, refs := c.pipe(body, n.Pipe, true)
+		body.dot, body.guards = dot, append(body.guards, refs...)
+		c.walk(body, n.List)
+		c.walk(f.block(f.dot, nil), n.ElseList)
+	case *parse.RangeNode:
+		body := f.block(f.dot, nil)
+		s, _ := c.pipe(body, n.Pipe, true)
+		c.read(f, s, n)
+		// Dot and the variables of a range hold its elements and index.
+		body.dot = scope{}
+		for _, v := range n.Pipe.Decl {
+			body.vars[v.Ident[0]] = scope{}
+		}
+		c.walk(body, n.List)
+		c.walk(f.block(f.dot, nil), n.ElseList)
+	case *parse.TemplateNode:
+		dot := scope{}
+		if n.Pipe != nil {
+			dot, _ = c.pipe(f, n.Pipe, false)
+		}
+		c.template(n.Name, dot)
+	}
+}
+
+// pipe checks a pipeline, and returns what it evaluates to and the paths it
+// references.
+func (c *valuesChecker) pipe(f *frame, pipe *parse.PipeNode, tolerant bool) (scope, [][]string) {
+	if pipe == nil {
+		return scope{}, nil
+	}
+	for _, cmd := range pipe.Cmds {
+		if id, ok := cmd.Args[0].(*parse.IdentifierNode); ok && tolerantFuncs[id.Ident] {
+			tolerant = true
+		}
+	}
+
+	var result scope
+	var refs [][]string
+	for i, cmd := range pipe.Cmds {
+		// The value of a command is given to the next one.
+		if i > 0 {
+			c.read(f, result, cmd)
+		}
+		var r [][]string
+		result, r = c.command(f, cmd, tolerant)
+		refs = append(refs, r...)
+	}
+	if len(pipe.Cmds) > 1 {
+		result = scope{}
+	}
+	for _, v := range pipe.Decl {
+		f.vars[v.Ident[0]] = result
+	}
+	return result, refs
+}
+
+func (c *valuesChecker) command(f *frame, cmd *parse.CommandNode, tolerant bool) (scope, [][]string) {
+	var refs [][]string
+	args := cmd.Args
+	function := false
+	if id, ok := args[0].(*parse.IdentifierNode); ok {
+		function = true
+		args = args[1:]
+		if id.Ident == "include" && len(args) == 2 {
+			if name, ok := args[0].(*parse.StringNode); ok {
+				dot, r := c.arg(f, args[1], tolerant)
+				c.template(name.Text, dot)
+				refs = append(refs, r...)
+				args = args[:1]
+			}
+		}
+	}
+
+	var result scope
+	for _, arg := range args {
+		var r [][]string
+		result, r = c.arg(f, arg, tolerant)
+		refs = append(refs, r...)
+		if function {
+			c.read(f, result, arg)
+		}
+	}
+	if len(cmd.Args) > 1 {
+		result = scope{}
+	}
+	return result, refs
+}
+
+func (c *valuesChecker) arg(f *frame, node parse.Node, tolerant bool) (scope, [][]string) {
+	switch n := node.(type) {
+	case *parse.DotNode:
+		return f.dot, nil
+	case *parse.FieldNode:
+		return c.ref(f, f.dot.field(n.Ident...), n, tolerant)
+	case *parse.VariableNode:
+		return c.ref(f, f.vars[n.Ident[0]].field(n.Ident[1:]...), n, tolerant)
+	case *parse.ChainNode:
+		s, refs := c.arg(f, n.Node, tolerant)
+		s, r := c.ref(f, s.field(n.Field...), n, tolerant)
+		return s, append(refs, r...)
+	case *parse.PipeNode:
+		return c.pipe(f, n, tolerant)
+	}
+	return scope{}, nil
+}
+
+// ref records a reference to a value, unless it is set, optional or
+// intended to be missing.
+func (c *valuesChecker) ref(f *frame, s scope, node parse.Node, tolerant bool) (scope, [][]string) {
+	if !s.known {
+		return s, nil
+	}
+	c.record(f, s, node, false)
+	refs := [][]string{s.path}
+	if tolerant || len(s.path) < 2 || s.path[0] != "Values" || c.guarded(f, s.path) || c.isOptional(s.path[1:]) || c.exists(s.path) {
+		return s, refs
+	}
+
+	template, line, ok := location(f.tree, node)
+	if !ok {
+		return s, refs
+	}
+	c.found[MissingValue{
+		Template: template,
+		Line:     line,
+		Path:     "." + strings.Join(s.path, "."),
+	}] = true
+	return s, refs
+}
+
+// read records that a value is read as a whole.
+func (c *valuesChecker) read(f *frame, s scope, node parse.Node) {
+	if s.known {
+		c.record(f, s, node, true)
+	}
+}
+
+// record records a reference to the values, or to the render values
+// themselves, if references are recorded.
+func (c *valuesChecker) record(f *frame, s scope, node parse.Node, whole bool) {
+	if c.refs == nil || len(s.path) > 0 && s.path[0] != "Values" {
+		return
+	}
+	template, line, ok := location(f.tree, node)
+	if !ok {
+		return
+	}
+	p := s.path
+	// The methods of .Values, such as AsMap, read the values as a whole.
+	if len(p) == 2 {
+		if _, ok := valuesType.MethodByName(p[1]); ok {
+			p, whole = p[:1], true
+		}
+	}
+	ref := ValueReference{Template: template, Line: line, Path: "." + strings.Join(p, ".")}
+	// A value read as a whole at a location is only recorded so.
+	read := ref
+	read.Whole = true
+	if c.refs[read] {
+		return
+	}
+	if whole {
+		delete(c.refs, ref)
+		ref = read
+	}
+	c.refs[ref] = true
+}
+
+// location returns the file and line of a node of a template.
+func location(tree *parse.Tree, node parse.Node) (string, int, bool) {
+	location, _ := tree.ErrorContext(node)
+	// The location is "file:line:column".
+	parts := strings.Split(location, ":")
+	if len(parts) < 3 {
+		return "", 0, false
+	}
+	line, _ := strconv.Atoi(parts[len(parts)-2])
+	return strings.Join(parts[:len(parts)-2], ":"), line, true
+}
+
+func (c *valuesChecker) guarded(f *frame, p []string) bool {
+	for _, g := range f.guards {
+		if hasPathPrefix(p, g) {
+			return true
+		}
+	}
+	return false
+}
+
+// isOptional returns whether the path of a value is allowed to be missing. A
+// pattern allows the value at its path and all the values below it, and '*'
+// matches any key, e.g. "ingress.annotations" or "*.podLabels".
+func (c *valuesChecker) isOptional(p []string) bool {
+	for _, o := range c.optional {
+		pattern := strings.Split(o, ".")
+		if len(pattern) > len(p) {
+			continue
+		}
+		match := true
+		for i, k := range pattern {
+			if k != "*" && k != p[i] {
+				match = false
+				break
+			}
+		}
+		if match {
+			return true
+		}
+	}
+	return false
+}
+
+// valuesType has the methods of chartutil.Values, which templates may call on
+// .Values.
+var valuesType = reflect.TypeOf(chartutil.Values{})
+
+// exists returns whether the value at the path of the render values is set.
+// Paths below values which are not maps cannot be told apart from methods,
+// so they are assumed to exist.
+func (c *valuesChecker) exists(p []string) bool {
+	var cur interface{} = c.vals
+	for _, k := range p {
+		switch m := cur.(type) {
+		case chartutil.Values:
+			if _, ok := valuesType.MethodByName(k); ok {
+				return true
+			}
+			v, ok := m[k]
+			if !ok {
+				return false
+			}
+			cur = v
+		case map[string]interface{}:
+			v, ok := m[k]
+			if !ok {
+				return false
+			}
+			cur = v
+		case nil:
+			return false
+		default:
+			return true
+		}
+	}
+	return true
+}
+
+func hasPathPrefix(p, prefix []string) bool {
+	if len(prefix) > len(p) {
+		return false
+	}
+	for i := range prefix {
+		if p[i] != prefix[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,173 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"reflect"
+	"testing"
+
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chartutil"
+)
+
+const strictDeployment = `{{- $image := .Values.image }}
+name: {{ include "web.name" . }}
+image: {{ $image.repository }}:{{ $image.tag }}
+replicas: {{ .Values.replicas | default 1 }}
+{{- if .Values.resources }}
+resources: {{ .Values.resources.limits | toYaml }}
+{{- end }}
+{{- with .Values.podLabels }}
+labels: {{ .app }}
+{{- end }}
+{{- range .Values.ports }}
+port: {{ .number }} {{ $.Values.protocol }}
+{{- end }}
+pullPolicy: {{ $.Values.image.pullPolicy }}
+affinity: {{ .Values.affinity.nodeAffinity }}
+annotations: {{ .Values.annotations.team }}
+values: {{ .Values.AsMap | len }}
+`
+
+const strictHelpers = `{{- define "web.name" -}}
+{{ .Chart.Name }}-{{ .Values.nameOverride }}
+{{- end -}}
+`
+
+func strictChart() *chart.Chart {
+	sub := &chart.Chart{
+		Metadata: &chart.Metadata{Name: "sub"},
+		Templates: []*chart.File{
+			{Name: "templates/config", Data: []byte("key: {{ .Values.key }}\nmissing: {{ .Values.missing }}\n")},
+		},
+		Values: map[string]interface{}{"key": "value"},
+	}
+	c := &chart.Chart{
+		Metadata: &chart.Metadata{Name: "web"},
+		Templates: []*chart.File{
+			{Name: "templates/deployment", Data: []byte(strictDeployment)},
+			{Name: "templates/_helpers.tpl", Data: []byte(strictHelpers)},
+		},
+		Values: map[string]interface{}{
+			"image":       map[string]interface{}{"repository": "nginx"},
+			"ports":       []interface{}{map[string]interface{}{"number": 80}},
+			"affinity":    nil,
+			"annotations": map[string]interface{}{},
+		},
+	}
+	c.AddDependency(sub)
+	return c
+}
+
+func strictValues(t *testing.T, c *chart.Chart) chartutil.Values {
+	vals, err := chartutil.ToRenderValues(c, map[string]interface{}{}, chartutil.ReleaseOptions{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return vals
+}
+
+func TestMissingValues(t *testing.T) {
+	c := strictChart()
+	missing, err := Engine{}.MissingValues(c, strictValues(t, c))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := []MissingValue{
+		{"web/charts/sub/templates/config", 2, ".Values.missing"},
+		{"web/templates/_helpers.tpl", 2, ".Values.nameOverride"},
+		{"web/templates/deployment", 3, ".Values.image.tag"},
+		{"web/templates/deployment", 12, ".Values.protocol"},
+		{"web/templates/deployment", 14, ".Values.image.pullPolicy"},
+		{"web/templates/deployment", 15, ".Values.affinity.nodeAffinity"},
+		{"web/templates/deployment", 16, ".Values.annotations.team"},
+	}
+	if !reflect.DeepEqual(missing, expect) {
+		t.Errorf("expected missing values\n%v\ngot\n%v", expect, missing)
+	}
+
+	e := Engine{OptionalValues: []string{"image", "*.team", "affinity.nodeAffinity", "protocol", "nameOverride", "missing"}}
+	if missing, _ := e.MissingValues(c, strictValues(t, c)); len(missing) != 0 {
+		t.Errorf("expected optional values not to be missing, got %v", missing)
+	}
+}
+
+func TestRenderStrictValues(t *testing.T) {
+	c := strictChart()
+	e := Engine{StrictValues: true}
+	_, err := e.Render(c, strictValues(t, c))
+	if _, ok := err.(MissingValuesError); !ok {
+		t.Fatalf("expected a MissingValuesError, got %v", err)
+	}
+	if len(err.(MissingValuesError)) != 7 {
+		t.Errorf("expected 7 missing values, got %s", err)
+	}
+
+	c.Values["image"].(map[string]interface{})["tag"] = "1.19"
+	c.Values["affinity"] = map[string]interface{}{}
+	e.OptionalValues = []string{"image.pullPolicy", "protocol", "affinity", "annotations", "nameOverride", "missing"}
+	out, err := e.Render(c, strictValues(t, c))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(out) == 0 {
+		t.Error("expected rendered templates")
+	}
+}
+
+func TestValueReferences(t *testing.T) {
+	c := strictChart()
+	refs, err := Engine{}.ValueReferences(c, strictValues(t, c))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := []ValueReference{
+		{Template: "web/charts/sub/templates/config", Line: 1, Path: ".Values.key", Whole: true},
+		{Template: "web/charts/sub/templates/config", Line: 2, Path: ".Values.missing", Whole: true},
+		{Template: "web/templates/_helpers.tpl", Line: 2, Path: ".Values.nameOverride", Whole: true},
+		{Template: "web/templates/deployment", Line: 1, Path: ".Values.image"},
+		{Template: "web/templates/deployment", Line: 3, Path: ".Values.image.repository", Whole: true},
+		{Template: "web/templates/deployment", Line: 3, Path: ".Values.image.tag", Whole: true},
+		{Template: "web/templates/deployment", Line: 4, Path: ".Values.replicas", Whole: true},
+		{Template: "web/templates/deployment", Line: 5, Path: ".Values.resources"},
+		{Template: "web/templates/deployment", Line: 6, Path: ".Values.resources.limits", Whole: true},
+		{Template: "web/templates/deployment", Line: 8, Path: ".Values.podLabels"},
+		{Template: "web/templates/deployment", Line: 9, Path: ".Values.podLabels.app", Whole: true},
+		{Template: "web/templates/deployment", Line: 11, Path: ".Values.ports", Whole: true},
+		{Template: "web/templates/deployment", Line: 12, Path: ".Values.protocol", Whole: true},
+		{Template: "web/templates/deployment", Line: 14, Path: ".Values.image.pullPolicy", Whole: true},
+		{Template: "web/templates/deployment", Line: 15, Path: ".Values.affinity.nodeAffinity", Whole: true},
+		{Template: "web/templates/deployment", Line: 16, Path: ".Values.annotations.team", Whole: true},
+		{Template: "web/templates/deployment", Line: 17, Path: ".Values", Whole: true},
+	}
+	if !reflect.DeepEqual(refs, expect) {
+		t.Errorf("expected references\n%v\ngot\n%v", expect, refs)
+	}
+}
+
+func TestCheckValues(t *testing.T) {
+	c := strictChart()
+	missing, refs, err := Engine{}.CheckValues(c, strictValues(t, c))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectMissing, _ := Engine{}.MissingValues(c, strictValues(t, c))
+	expectRefs, _ := Engine{}.ValueReferences(c, strictValues(t, c))
+	if !reflect.DeepEqual(missing, expectMissing) || !reflect.DeepEqual(refs, expectRefs) {
+		t.Errorf("expected the missing values and references of MissingValues and ValueReferences, got\n%v\n%v", missing, refs)
+	}
+}
@@ -19,18 +19,59 @@ package lint // import "helm.sh/helm/v3/pkg/lint"
 import (
 	"path/filepath"
 
+	"helm.sh/helm/v3/pkg/chart/loader"
 	"helm.sh/helm/v3/pkg/lint/rules"
 	"helm.sh/helm/v3/pkg/lint/support"
 )
 
 // All runs all of the available linters on the given base directory.
 func All(basedir string, values map[string]interface{}, namespace string, strict bool) support.Linter {
+	return AllWithOptions(basedir, values, Options{TemplateOptions: rules.TemplateOptions{Namespace: namespace}})
+}
+
+// Options are the options of the linters run by AllWithOptions.
+type Options struct {
+	// TemplateOptions are the options of the templates linter. Its Checkers
+	// can also be configured like the rules of Helm.
+	rules.TemplateOptions
+	// Config configures the rules, overriding the .helmlint.yaml file of the
+	// chart.
+	Config *support.Config
+}
+
+// AllWithOptions runs all of the available linters on the given base
+// directory with the given options.
+func AllWithOptions(basedir string, values map[string]interface{}, opts Options) support.Linter {
 	// Using abs path to get directory context
 	chartDir, _ := filepath.Abs(basedir)
 
 	linter := support.Linter{ChartDir: chartDir}
+	known := rules.Rules()
+	for _, c := range opts.Checkers {
+		known = append(known, c.Rules()...)
+	}
+	config, err := support.LoadConfig(filepath.Join(chartDir, support.ConfigFileName))
+	if err == nil {
+		err = CheckPluginConfig(config, opts.Checkers)
+	}
+	if err == nil {
+		config, err = config.Resolve(known)
+	}
+	linter.RunLinterRule(support.ErrorSev, support.ConfigFileName, err)
+	var override *support.Config
+	err = CheckPluginConfig(opts.Config, opts.Checkers)
+	if err == nil {
+		override, err = opts.Config.Resolve(known)
+	}
+	linter.RunLinterRule(support.ErrorSev, support.ConfigFileName, err)
+	linter.Config = config.Merge(override)
+
+	// The chart is reported on by the rules if it cannot be loaded.
+	chrt, _ := loader.Load(chartDir)
+
 	rules.Chartfile(&linter)
-	rules.ValuesWithOverrides(&linter, values)
-	rules.Templates(&linter, values, namespace, strict)
+	rules.Dependencies(&linter)
+	rules.ValuesWithChart(&linter, chrt, values)
+	rules.TemplatesWithOptions(&linter, values, opts.TemplateOptions)
 	return linter
 }
@@ -0,0 +1,277 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lint
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"helm.sh/helm/v3/pkg/cli"
+	"helm.sh/helm/v3/pkg/lint/rules"
+	"helm.sh/helm/v3/pkg/lint/support"
+	"helm.sh/helm/v3/pkg/plugin"
+)
+
+// PluginCheckers returns the checkers of the given installed plugins, by name.
+// The linters of plugins are commands run with every linted chart, so only the
+// plugins a user names are loaded. Naming a plugin which is not installed or
+// declares no linters is an error. A named plugin which cannot be loaded, or
+// which declares invalid rules, is reported with warn and skipped.
+//
+// Rules are configured by ID or name, which must be unique. A rule of a plugin
+// whose ID or name is already taken is skipped too, and configuring it is an
+// error reported by CheckPluginConfig.
+func PluginCheckers(settings *cli.EnvSettings, names []string, warn func(format string, v ...interface{})) ([]support.Checker, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+	wanted := map[string]bool{}
+	for _, n := range names {
+		wanted[n] = false
+	}
+	owners := map[string]string{}
+	for _, r := range rules.Rules() {
+		owners[r.ID], owners[r.Name] = "helm", "helm"
+	}
+	var result []support.Checker
+	for _, p := range findPlugins(settings.PluginsDirectory, wanted, warn) {
+		if _, ok := wanted[p.Metadata.Name]; !ok {
+			continue
+		}
+		wanted[p.Metadata.Name] = true
+		if len(p.Metadata.Linters) == 0 {
+			return nil, errors.Errorf("plugin %q declares no linters", p.Metadata.Name)
+		}
+		checkers, err := pluginCheckers(p, settings, owners)
+		if err != nil {
+			warn("skipping plugin %q: %s", p.Metadata.Name, err)
+			continue
+		}
+		for _, c := range checkers {
+			for _, conflict := range c.skipped {
+				warn("%s, skipping it", conflict)
+			}
+			for _, r := range c.rules {
+				owners[r.ID], owners[r.Name] = "plugin "+p.Metadata.Name, "plugin "+p.Metadata.Name
+			}
+			result = append(result, c)
+		}
+	}
+	for _, n := range names {
+		if !wanted[n] {
+			return nil, errors.Errorf("lint plugin %q is not installed", n)
+		}
+	}
+	return result, nil
+}
+
+// findPlugins loads the plugins of the plugin directories. The plugins which
+// cannot be loaded are skipped, with a warning if their directory is named as
+// a wanted plugin, which is then marked as found.
+func findPlugins(plugdirs string, wanted map[string]bool, warn func(format string, v ...interface{})) []*plugin.Plugin {
+	var found []*plugin.Plugin
+	for _, dir := range filepath.SplitList(plugdirs) {
+		matches, err := filepath.Glob(filepath.Join(dir, "*", plugin.PluginFileName))
+		if err != nil {
+			warn("skipping plugins of %s: %s", dir, err)
+			continue
+		}
+		for _, m := range matches {
+			p, err := plugin.LoadDir(filepath.Dir(m))
+			if err != nil {
+				if _, ok := wanted[filepath.Base(filepath.Dir(m))]; ok {
+					warn("skipping plugin %s: %s", filepath.Dir(m), err)
+					wanted[filepath.Base(filepath.Dir(m))] = true
+				}
+				continue
+			}
+			found = append(found, p)
+		}
+	}
+	return found
+}
+
+// pluginCheckers returns the checkers of the linters of a plugin. Its rules
+// whose ID or name has an owner are recorded as conflicts.
+func pluginCheckers(p *plugin.Plugin, settings *cli.EnvSettings, owners map[string]string) ([]*pluginChecker, error) {
+	var result []*pluginChecker
+	declared := map[string]bool{}
+	for _, linter := range p.Metadata.Linters {
+		checker := &pluginChecker{
+			command:   linter.Command,
+			settings:  settings,
+			name:      p.Metadata.Name,
+			base:      p.Dir,
+			conflicts: map[string]string{},
+		}
+		for _, lr := range linter.Rules {
+			if lr.ID == "" {
+				return nil, errors.Errorf("lint rule %q has no ID", lr.Name)
+			}
+			r := support.Rule{ID: lr.ID, Name: lr.Name, Severity: support.ErrorSev, Disabled: lr.Disabled, Description: lr.Description}
+			if lr.Severity != "" {
+				var err error
+				if r.Severity, err = support.ParseSeverity(lr.Severity); err != nil {
+					return nil, errors.Wrapf(err, "lint rule %s", lr.ID)
+				}
+			}
+			conflict := ""
+			for _, key := range []string{lr.ID, lr.Name} {
+				if key == "" || conflict != "" {
+					continue
+				}
+				if owner, ok := owners[key]; ok {
+					conflict = fmt.Sprintf("plugin %q: lint rule %s is already a rule of %s", p.Metadata.Name, key, owner)
+				} else if declared[key] {
+					conflict = fmt.Sprintf("plugin %q: lint rule %s is declared twice", p.Metadata.Name, key)
+				}
+			}
+			if conflict != "" {
+				checker.skipped = append(checker.skipped, conflict)
+				for _, key := range []string{lr.ID, lr.Name} {
+					if key != "" {
+						checker.conflicts[key] = conflict
+					}
+				}
+				continue
+			}
+			declared[lr.ID], declared[lr.Name] = true, lr.Name != ""
+			checker.rules = append(checker.rules, r)
+		}
+		result = append(result, checker)
+	}
+	return result, nil
+}
+
+// CheckPluginConfig returns an error if a lint configuration configures a
+// rule of a plugin which was skipped by PluginCheckers because its ID or name
+// is already taken.
+func CheckPluginConfig(config *support.Config, checkers []support.Checker) error {
+	if config == nil {
+		return nil
+	}
+	for _, c := range checkers {
+		p, ok := c.(*pluginChecker)
+		if !ok {
+			continue
+		}
+		for _, key := range sortedKeys(config.Rules) {
+			if conflict, ok := p.conflicts[key]; ok {
+				return errors.Errorf("lint rule %s cannot be configured: %s", key, conflict)
+			}
+		}
+	}
+	return nil
+}
+
+func sortedKeys(m map[string]support.RuleConfig) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// pluginChecker is a checker implemented by the linter of a plugin.
+type pluginChecker struct {
+	command  string
+	settings *cli.EnvSettings
+	name     string
+	base     string
+	rules    []support.Rule
+	// conflicts are the reasons the rules of the plugin which were skipped
+	// were skipped, by ID and name, and skipped those reasons in order.
+	conflicts map[string]string
+	skipped   []string
+}
+
+// pluginMessage is a message printed by the linter of a plugin, e.g.
+//
+//	{"rule": "ACME001", "path": "templates/deployment.yaml:12", "message": "image is not from registry.example.com"}
+//
+// The severity, one of "info", "warning" or "error", defaults to that of the
+// rule.
+type pluginMessage struct {
+	Rule     string `json:"rule"`
+	Severity string `json:"severity,omitempty"`
+	Path     string `json:"path"`
+	Message  string `json:"message"`
+}
+
+// pluginOutput is what the linter of a plugin prints.
+type pluginOutput struct {
+	Messages []pluginMessage `json:"messages"`
+}
+
+func (p *pluginChecker) Rules() []support.Rule {
+	return p.rules
+}
+
+// Check runs the linter command of the plugin with the chart as JSON on its
+// standard input.
+func (p *pluginChecker) Check(c *support.RenderedChart) ([]support.Message, error) {
+	input, err := json.Marshal(c)
+	if err != nil {
+		return nil, err
+	}
+	commands := strings.Split(p.command, " ")
+	prog := exec.Command(filepath.Join(p.base, commands[0]), commands[1:]...)
+	plugin.SetupPluginEnv(p.settings, p.name, p.base)
+	prog.Env = os.Environ()
+	prog.Stdin = bytes.NewReader(input)
+	buf := bytes.NewBuffer(nil)
+	prog.Stdout = buf
+	prog.Stderr = os.Stderr
+	if err := prog.Run(); err != nil {
+		if _, ok := err.(*exec.ExitError); ok {
+			return nil, errors.Errorf("lint plugin %q exited with error", p.name)
+		}
+		return nil, errors.Wrapf(err, "lint plugin %q", p.name)
+	}
+
+	if len(bytes.TrimSpace(buf.Bytes())) == 0 {
+		return nil, nil
+	}
+	var out pluginOutput
+	if err := json.Unmarshal(buf.Bytes(), &out); err != nil {
+		return nil, errors.Wrapf(err, "lint plugin %q printed invalid messages", p.name)
+	}
+	var messages []support.Message
+	for _, m := range out.Messages {
+		if _, ok := p.conflicts[m.Rule]; ok {
+			// The rule was skipped, its ID is that of another rule.
+			continue
+		}
+		severity := support.UnknownSev
+		if m.Severity != "" {
+			if severity, err = support.ParseSeverity(m.Severity); err != nil {
+				return nil, errors.Wrapf(err, "lint plugin %q printed an invalid message", p.name)
+			}
+		}
+		messages = append(messages, support.Message{Severity: severity, Path: m.Path, Err: errors.New(m.Message), RuleID: m.Rule})
+	}
+	return messages, nil
+}
@@ -0,0 +1,96 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lint
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"testing"
+
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/cli"
+	"helm.sh/helm/v3/pkg/lint/support"
+)
+
+func TestPluginCheckers(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("TODO: refactor this test to work on windows")
+	}
+	env := cli.New()
+	env.PluginsDirectory = "testdata/plugins"
+	var warnings []string
+	warn := func(format string, v ...interface{}) {
+		warnings = append(warnings, fmt.Sprintf(format, v...))
+	}
+	checkers, err := PluginCheckers(env, nil, warn)
+	if err != nil || len(checkers) != 0 {
+		t.Errorf("expected the plugins not to be loaded unless named, got %v, %v", checkers, err)
+	}
+	if _, err := PluginCheckers(env, []string{"missing"}, warn); err == nil || err.Error() != `lint plugin "missing" is not installed` {
+		t.Errorf("expected an error about the plugin which is not installed, got %v", err)
+	}
+	checkers, err = PluginCheckers(env, []string{"policy"}, warn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(warnings) != 0 {
+		t.Errorf("unexpected warnings %v", warnings)
+	}
+	if len(checkers) != 1 {
+		t.Fatalf("expected 1 checker, got %d", len(checkers))
+	}
+	rules := checkers[0].Rules()
+	if len(rules) != 2 || rules[0].Severity != support.ErrorSev || rules[1].Name != "registry-allowlist" || rules[1].Severity != support.WarningSev {
+		t.Errorf("unexpected rules %v", rules)
+	}
+
+	messages, err := checkers[0].Check(&support.RenderedChart{
+		Metadata:  &chart.Metadata{Name: "web"},
+		Values:    map[string]interface{}{"image": "docker.io/nginx"},
+		Manifests: map[string]string{"templates/pod.yaml": "metadata:\n  labels:\n    cost-center: web\n"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(messages) != 1 || messages[0].RuleID != "ACME002" || messages[0].Severity != support.ErrorSev || messages[0].Path != "values.yaml" {
+		t.Errorf("unexpected messages %v", messages)
+	}
+
+	env.PluginsDirectory = "testdata/plugins-conflicting"
+	if _, err = PluginCheckers(env, []string{"conflicting"}, warn); err != nil || len(warnings) != 1 {
+		t.Errorf("expected no warnings about the broken plugin which is not named, got %v, %v", warnings, err)
+	}
+	warnings = nil
+	if checkers, err = PluginCheckers(env, []string{"broken", "conflicting"}, warn); err != nil {
+		t.Fatal(err)
+	}
+	if len(warnings) != 2 || !strings.HasPrefix(warnings[0], "skipping plugin testdata/plugins-conflicting/broken: ") ||
+		warnings[1] != `plugin "conflicting": lint rule HL001 is already a rule of helm, skipping it` {
+		t.Errorf("expected warnings about the broken plugin and the rule of Helm, got %v", warnings)
+	}
+	if len(checkers) != 1 || len(checkers[0].Rules()) != 1 || checkers[0].Rules()[0].ID != "CONF001" {
+		t.Fatalf("expected the rules of the conflicting plugin but HL001, got %v", checkers)
+	}
+	if err := CheckPluginConfig(&support.Config{Rules: map[string]support.RuleConfig{"CONF001": {}, "conflicting-extra": {}}}, checkers); err != nil {
+		t.Errorf("expected rules which do not conflict to be configurable, got %v", err)
+	}
+	err = CheckPluginConfig(&support.Config{Rules: map[string]support.RuleConfig{"chart-yaml": {}}}, checkers)
+	if err == nil || err.Error() != `lint rule chart-yaml cannot be configured: plugin "conflicting": lint rule HL001 is already a rule of helm` {
+		t.Errorf("expected an error about the rule of Helm, got %v", err)
+	}
+}
@@ -0,0 +1,327 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rules
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/yaml"
+
+	"helm.sh/helm/v3/pkg/lint/support"
+	"helm.sh/helm/v3/pkg/releaseutil"
+)
+
+// recommendedLabels are the labels every object of a chart should have, as
+// set by the charts created with 'helm create'.
+//
+// See https://kubernetes.io/docs/concepts/overview/working-with-objects/common-labels/
+var recommendedLabels = []string{
+	"app.kubernetes.io/name",
+	"app.kubernetes.io/instance",
+	"app.kubernetes.io/version",
+	"app.kubernetes.io/managed-by",
+}
+
+// BestPractices returns the best-practice rules, which check the content of
+// the rendered objects. They are disabled unless enabled by the
+// configuration or by the BestPractices option.
+func BestPractices() []support.Rule {
+	return []support.Rule{
+		ResourceRequirements,
+		ImageTag,
+		Probes,
+		SecurityContext,
+		HostPath,
+		RecommendedLabels,
+		ServiceSelector,
+	}
+}
+
+// renderedObject is an object rendered by a template.
+type renderedObject struct {
+	// path is the template rendering the object.
+	path string
+	obj  map[string]interface{}
+}
+
+func (o renderedObject) kind() string {
+	kind, _ := o.obj["kind"].(string)
+	return kind
+}
+
+func (o renderedObject) String() string {
+	name, _ := nestedMap(o.obj, "metadata")["name"].(string)
+	return o.kind() + "/" + name
+}
+
+// splitObjects returns the objects rendered by a template. Documents which
+// are not objects are left to the other rules.
+func splitObjects(path, content string) []renderedObject {
+	manifests := releaseutil.SplitManifests(content)
+	keys := make([]string, 0, len(manifests))
+	for k := range manifests {
+		keys = append(keys, k)
+	}
+	sort.Sort(releaseutil.BySplitManifestsOrder(keys))
+
+	var objects []renderedObject
+	for _, k := range keys {
+		var obj map[string]interface{}
+		if err := yaml.Unmarshal([]byte(manifests[k]), &obj); err != nil || obj["kind"] == nil {
+			continue
+		}
+		objects = append(objects, renderedObject{path: path, obj: obj})
+	}
+	return objects
+}
+
+// validateBestPractices runs the enabled best-practice rules on the objects
+// rendered by the templates of a chart.
+func validateBestPractices(linter *support.Linter, objects []renderedObject) {
+	for _, o := range objects {
+		linter.RunRule(RecommendedLabels, o.path, validateRecommendedLabels(o))
+
+		spec := podSpec(o)
+		if spec == nil {
+			continue
+		}
+		linter.RunRule(HostPath, o.path, validateNoHostPath(o, spec))
+		linter.RunRule(SecurityContext, o.path, validateSecurityContext(o, spec))
+		for _, c := range containers(spec, "initContainers", "containers") {
+			linter.RunRule(ResourceRequirements, o.path, validateResourceRequirements(o, c))
+			linter.RunRule(ImageTag, o.path, validateImageTag(o, c))
+		}
+		if longRunning(o.kind()) {
+			for _, c := range containers(spec, "containers") {
+				linter.RunRule(Probes, o.path, validateProbes(o, c))
+			}
+		}
+	}
+	if linter.RuleEnabled(ServiceSelector) {
+		for _, o := range objects {
+			if o.kind() == "Service" {
+				linter.RunRule(ServiceSelector, o.path, validateServiceSelector(o, objects))
+			}
+		}
+	}
+}
+
+// podSpec returns the spec of the pods of a workload, or nil if the object
+// is not a workload.
+func podSpec(o renderedObject) map[string]interface{} {
+	if t := podTemplate(o); t != nil {
+		return nestedMap(t, "spec")
+	}
+	return nil
+}
+
+// podTemplate returns the template of the pods of a workload, or the pod
+// itself, with the labels of the pods in its metadata.
+func podTemplate(o renderedObject) map[string]interface{} {
+	switch o.kind() {
+	case "Pod":
+		return o.obj
+	case "Deployment", "StatefulSet", "DaemonSet", "ReplicaSet", "ReplicationController", "Job":
+		return nestedMap(o.obj, "spec", "template")
+	case "CronJob":
+		return nestedMap(o.obj, "spec", "jobTemplate", "spec", "template")
+	}
+	return nil
+}
+
+// longRunning returns whether the pods of a kind of workload are expected to
+// keep running, and so to be probed.
+func longRunning(kind string) bool {
+	switch kind {
+	case "Deployment", "StatefulSet", "DaemonSet", "ReplicaSet", "ReplicationController":
+		return true
+	}
+	return false
+}
+
+func containers(spec map[string]interface{}, fields ...string) []map[string]interface{} {
+	var list []map[string]interface{}
+	for _, field := range fields {
+		items, _ := spec[field].([]interface{})
+		for _, item := range items {
+			if c, ok := item.(map[string]interface{}); ok {
+				list = append(list, c)
+			}
+		}
+	}
+	return list
+}
+
+func containerName(c map[string]interface{}) string {
+	name, _ := c["name"].(string)
+	return name
+}
+
+// nestedMap returns the map at the path of fields in obj, or nil.
+func nestedMap(obj map[string]interface{}, fields ...string) map[string]interface{} {
+	cur := obj
+	for _, f := range fields {
+		next, ok := cur[f].(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		cur = next
+	}
+	return cur
+}
+
+func validateResourceRequirements(o renderedObject, c map[string]interface{}) error {
+	resources := nestedMap(c, "resources")
+	var missing []string
+	for _, field := range []string{"requests", "limits"} {
+		if len(nestedMap(resources, field)) == 0 {
+			missing = append(missing, field)
+		}
+	}
+	if len(missing) > 0 {
+		return errors.Errorf("%s: container %q sets no resource %s", o, containerName(c), strings.Join(missing, " or "))
+	}
+	return nil
+}
+
+func validateImageTag(o renderedObject, c map[string]interface{}) error {
+	image, _ := c["image"].(string)
+	if image == "" || strings.Contains(image, "@") {
+		return nil
+	}
+	// The tag follows the last ':' after the registry, which may have a port.
+	name := image[strings.LastIndex(image, "/")+1:]
+	i := strings.LastIndex(name, ":")
+	switch {
+	case i < 0:
+		return errors.Errorf("%s: container %q uses the untagged image %q", o, containerName(c), image)
+	case name[i+1:] == "latest":
+		return errors.Errorf("%s: container %q uses the image %q with the latest tag", o, containerName(c), image)
+	}
+	return nil
+}
+
+func validateProbes(o renderedObject, c map[string]interface{}) error {
+	var missing []string
+	for _, probe := range []string{"livenessProbe", "readinessProbe"} {
+		if nestedMap(c, probe) == nil {
+			missing = append(missing, probe)
+		}
+	}
+	if len(missing) > 0 {
+		return errors.Errorf("%s: container %q has no %s", o, containerName(c), strings.Join(missing, " or "))
+	}
+	return nil
+}
+
+func validateSecurityContext(o renderedObject, spec map[string]interface{}) error {
+	pod := nestedMap(spec, "securityContext")
+	var problems []string
+	for _, c := range containers(spec, "initContainers", "containers") {
+		sc := nestedMap(c, "securityContext")
+		name := containerName(c)
+		if privileged, _ := sc["privileged"].(bool); privileged {
+			problems = append(problems, fmt.Sprintf("container %q is privileged", name))
+		}
+		if securityField(sc, pod, "runAsUser") == float64(0) {
+			problems = append(problems, fmt.Sprintf("container %q runs as root", name))
+		} else if nonRoot, _ := securityField(sc, pod, "runAsNonRoot").(bool); !nonRoot {
+			problems = append(problems, fmt.Sprintf("container %q may run as root, runAsNonRoot is not set", name))
+		}
+	}
+	if len(problems) > 0 {
+		return errors.Errorf("%s: %s", o, strings.Join(problems, ", "))
+	}
+	return nil
+}
+
+// securityField returns a field of the security context of a container,
+// which defaults to the one of its pod.
+func securityField(container, pod map[string]interface{}, field string) interface{} {
+	if v, ok := container[field]; ok {
+		return v
+	}
+	return pod[field]
+}
+
+func validateNoHostPath(o renderedObject, spec map[string]interface{}) error {
+	volumes, _ := spec["volumes"].([]interface{})
+	var names []string
+	for _, v := range volumes {
+		volume, _ := v.(map[string]interface{})
+		if nestedMap(volume, "hostPath") != nil {
+			name, _ := volume["name"].(string)
+			names = append(names, fmt.Sprintf("%q", name))
+		}
+	}
+	if len(names) > 0 {
+		return errors.Errorf("%s: hostPath volumes %s expose the filesystem of the node", o, strings.Join(names, ", "))
+	}
+	return nil
+}
+
+func validateRecommendedLabels(o renderedObject) error {
+	labels := nestedMap(o.obj, "metadata", "labels")
+	var missing []string
+	for _, l := range recommendedLabels {
+		if _, ok := labels[l]; !ok {
+			missing = append(missing, l)
+		}
+	}
+	if len(missing) > 0 {
+		return errors.Errorf("%s: missing recommended labels %s", o, strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// validateServiceSelector checks that the selector of a Service matches the
+// pods of a workload rendered by the chart.
+func validateServiceSelector(svc renderedObject, objects []renderedObject) error {
+	spec := nestedMap(svc.obj, "spec")
+	selector := nestedMap(spec, "selector")
+	if len(selector) == 0 || spec["type"] == "ExternalName" {
+		return nil
+	}
+	for _, o := range objects {
+		if t := podTemplate(o); t != nil && matchesLabels(nestedMap(t, "metadata", "labels"), selector) {
+			return nil
+		}
+	}
+
+	keys := make([]string, 0, len(selector))
+	for k := range selector {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	pairs := make([]string, len(keys))
+	for i, k := range keys {
+		pairs[i] = fmt.Sprintf("%s=%v", k, selector[k])
+	}
+	return errors.Errorf("%s: selector %s matches no pods rendered by the chart", svc, strings.Join(pairs, ","))
+}
+
+func matchesLabels(labels, selector map[string]interface{}) bool {
+	for k, v := range selector {
+		if l, ok := labels[k]; !ok || fmt.Sprint(l) != fmt.Sprint(v) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,138 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rules
+
+import (
+	"testing"
+
+	"helm.sh/helm/v3/pkg/lint/support"
+)
+
+const bestPracticesManifest = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  labels:
+    app.kubernetes.io/name: web
+    app.kubernetes.io/instance: test
+spec:
+  template:
+    metadata:
+      labels:
+        app: web
+    spec:
+      securityContext:
+        runAsNonRoot: true
+      volumes:
+        - name: docker
+          hostPath:
+            path: /var/run/docker.sock
+      initContainers:
+        - name: init
+          image: busybox
+          resources:
+            requests:
+              cpu: 10m
+            limits:
+              cpu: 10m
+      containers:
+        - name: web
+          image: registry:5000/nginx:latest
+          securityContext:
+            privileged: true
+            runAsUser: 0
+          readinessProbe:
+            httpGet:
+              path: /
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+spec:
+  selector:
+    app: web
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: other
+spec:
+  selector:
+    app: other
+    tier: frontend
+`
+
+func TestValidateBestPractices(t *testing.T) {
+	objects := splitObjects("templates/web.yaml", bestPracticesManifest)
+	if len(objects) != 3 {
+		t.Fatalf("expected 3 objects, got %d", len(objects))
+	}
+
+	pack := &support.Config{Rules: map[string]support.RuleConfig{}}
+	enabled := true
+	for _, r := range BestPractices() {
+		pack.Rules[r.ID] = support.RuleConfig{Enabled: &enabled}
+	}
+	linter := support.Linter{ChartDir: "testdata", Config: pack}
+	validateBestPractices(&linter, objects)
+
+	expect := []string{
+		`[INFO] HL032 templates/web.yaml: Deployment/web: missing recommended labels app.kubernetes.io/version, app.kubernetes.io/managed-by`,
+		`[WARNING] HL031 templates/web.yaml: Deployment/web: hostPath volumes "docker" expose the filesystem of the node`,
+		`[WARNING] HL030 templates/web.yaml: Deployment/web: container "web" is privileged, container "web" runs as root`,
+		`[WARNING] HL028 templates/web.yaml: Deployment/web: container "init" uses the untagged image "busybox"`,
+		`[WARNING] HL027 templates/web.yaml: Deployment/web: container "web" sets no resource requests or limits`,
+		`[WARNING] HL028 templates/web.yaml: Deployment/web: container "web" uses the image "registry:5000/nginx:latest" with the latest tag`,
+		`[WARNING] HL029 templates/web.yaml: Deployment/web: container "web" has no livenessProbe`,
+		`[INFO] HL032 templates/web.yaml: Service/web: missing recommended labels app.kubernetes.io/name, app.kubernetes.io/instance, app.kubernetes.io/version, app.kubernetes.io/managed-by`,
+		`[INFO] HL032 templates/web.yaml: Service/other: missing recommended labels app.kubernetes.io/name, app.kubernetes.io/instance, app.kubernetes.io/version, app.kubernetes.io/managed-by`,
+		`[WARNING] HL033 templates/web.yaml: Service/other: selector app=other,tier=frontend matches no pods rendered by the chart`,
+	}
+	if len(linter.Messages) != len(expect) {
+		t.Fatalf("expected %d messages, got %d: %v", len(expect), len(linter.Messages), linter.Messages)
+	}
+	for i, m := range linter.Messages {
+		if m.Error() != expect[i] {
+			t.Errorf("expected message\n%s\ngot\n%s", expect[i], m.Error())
+		}
+	}
+
+	// The rules are disabled by default.
+	linter = support.Linter{ChartDir: "testdata"}
+	validateBestPractices(&linter, objects)
+	if len(linter.Messages) != 0 {
+		t.Errorf("expected no messages, got %v", linter.Messages)
+	}
+}
+
+func TestValidateImageTag(t *testing.T) {
+	o := renderedObject{obj: map[string]interface{}{"kind": "Pod"}}
+	for image, valid := range map[string]bool{
+		"nginx":                      false,
+		"nginx:latest":               false,
+		"localhost:5000/nginx":       false,
+		"nginx:1.19":                 true,
+		"localhost:5000/nginx:1.19":  true,
+		"nginx@sha256:0123456789abc": true,
+	} {
+		err := validateImageTag(o, map[string]interface{}{"name": "c", "image": image})
+		if (err == nil) != valid {
+			t.Errorf("validateImageTag(%q): expected valid %t, got %v", image, valid, err)
+		}
+	}
+}
@@ -35,27 +35,27 @@ func Chartfile(linter *support.Linter) {
 	chartFileName := "Chart.yaml"
 	chartPath := filepath.Join(linter.ChartDir, chartFileName)
 
-	linter.RunLinterRule(support.ErrorSev, chartFileName, validateChartYamlNotDirectory(chartPath))
+	linter.RunRule(ChartfileNotDirectory, chartFileName, validateChartYamlNotDirectory(chartPath))
 
 	chartFile, err := chartutil.LoadChartfile(chartPath)
-	validChartFile := linter.RunLinterRule(support.ErrorSev, chartFileName, validateChartYamlFormat(err))
+	validChartFile := linter.RunRule(ChartfileFormat, chartFileName, validateChartYamlFormat(err))
 
 	// Guard clause. Following linter rules require a parsable ChartFile
 	if !validChartFile {
 		return
 	}
 
-	linter.RunLinterRule(support.ErrorSev, chartFileName, validateChartName(chartFile))
+	linter.RunRule(ChartName, chartFileName, validateChartName(chartFile))
 
 	// Chart metadata
-	linter.RunLinterRule(support.ErrorSev, chartFileName, validateChartAPIVersion(chartFile))
-	linter.RunLinterRule(support.ErrorSev, chartFileName, validateChartVersion(chartFile))
-	linter.RunLinterRule(support.ErrorSev, chartFileName, validateChartMaintainer(chartFile))
-	linter.RunLinterRule(support.ErrorSev, chartFileName, validateChartSources(chartFile))
-	linter.RunLinterRule(support.InfoSev, chartFileName, validateChartIconPresence(chartFile))
-	linter.RunLinterRule(support.ErrorSev, chartFileName, validateChartIconURL(chartFile))
-	linter.RunLinterRule(support.ErrorSev, chartFileName, validateChartType(chartFile))
-	linter.RunLinterRule(support.ErrorSev, chartFileName, validateChartDependencies(chartFile))
+	linter.RunRule(ChartAPIVersion, chartFileName, validateChartAPIVersion(chartFile))
+	linter.RunRule(ChartVersion, chartFileName, validateChartVersion(chartFile))
+	linter.RunRule(ChartMaintainers, chartFileName, validateChartMaintainer(chartFile))
+	linter.RunRule(ChartSources, chartFileName, validateChartSources(chartFile))
+	linter.RunRule(ChartIcon, chartFileName, validateChartIconPresence(chartFile))
+	linter.RunRule(ChartIconURL, chartFileName, validateChartIconURL(chartFile))
+	linter.RunRule(ChartType, chartFileName, validateChartType(chartFile))
+	linter.RunRule(ChartDependencies, chartFileName, validateChartDependencies(chartFile))
 }
 
 func validateChartYamlNotDirectory(chartPath string) error {
@@ -0,0 +1,253 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rules
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/pkg/errors"
+
+	"helm.sh/helm/v3/internal/resolver"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/lint/support"
+)
+
+// Dependencies runs the linter rules checking that the dependencies of a
+// chart are consistent with its lock file, its charts/ directory and its
+// values.
+func Dependencies(linter *support.Linter) {
+	c, err := loader.LoadDir(linter.ChartDir)
+	// A chart which cannot be loaded is reported by the ChartLoad rule.
+	if err != nil || c.Metadata == nil {
+		return
+	}
+	// Charts of apiVersion v1 may declare their dependencies in the
+	// deprecated requirements.yaml and requirements.lock files.
+	depsFileName, lockFileName := "Chart.yaml", "Chart.lock"
+	for _, f := range c.Raw {
+		switch f.Name {
+		case "requirements.yaml":
+			depsFileName = f.Name
+		case "requirements.lock":
+			lockFileName = f.Name
+		}
+	}
+
+	if c.Lock != nil {
+		for _, err := range validateDependencyLock(c, lockFileName, depsFileName) {
+			linter.RunRule(DependencyLock, lockFileName, err)
+		}
+	}
+	archives, _ := filepath.Glob(filepath.Join(linter.ChartDir, "charts", "*.tgz"))
+	for _, archive := range archives {
+		path := filepath.Join("charts", filepath.Base(archive))
+		linter.RunRule(UndeclaredDependencies, path, validateDependencyArchive(c, archive, depsFileName))
+	}
+	for _, err := range validateDependencyAliases(c) {
+		linter.RunRule(DependencyAliases, depsFileName, err)
+	}
+	for _, err := range validateDependencyConditions(c) {
+		linter.RunRule(DependencyConditions, depsFileName, err)
+	}
+}
+
+// validateDependencyLock returns the inconsistencies between the dependencies
+// of a chart and its lock file.
+func validateDependencyLock(c *chart.Chart, lockFileName, depsFileName string) []error {
+	var errs []error
+	locked := map[string]*chart.Dependency{}
+	for _, l := range c.Lock.Dependencies {
+		locked[l.Name] = l
+	}
+	declared := map[string]bool{}
+	for _, d := range c.Metadata.Dependencies {
+		declared[d.Name] = true
+		l, ok := locked[d.Name]
+		if !ok {
+			errs = append(errs, errors.Errorf("dependency %q is not locked", d.Name))
+			continue
+		}
+		if !satisfies(d.Version, l.Version) {
+			errs = append(errs, errors.Errorf("dependency %q is locked to version %s, which does not satisfy %s", d.Name, l.Version, d.Version))
+		}
+	}
+	for _, l := range c.Lock.Dependencies {
+		if !declared[l.Name] {
+			errs = append(errs, errors.Errorf("locked dependency %q is not in %s", l.Name, depsFileName))
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+
+	// The digest of dependencies from repositories referred to by name is
+	// that of their URLs, which depend on the repositories configured when
+	// the lock was written.
+	for _, d := range c.Metadata.Dependencies {
+		if strings.HasPrefix(d.Repository, "@") || strings.HasPrefix(d.Repository, "alias:") {
+			return nil
+		}
+	}
+	if sum, err := resolver.HashReq(c.Metadata.Dependencies, c.Lock.Dependencies); err == nil && sum == c.Lock.Digest {
+		return nil
+	}
+	// Locks of apiVersion v1 charts may have been written by Helm 2.
+	if c.Metadata.APIVersion == chart.APIVersionV1 {
+		if sum, err := resolver.HashV2Req(c.Metadata.Dependencies); err == nil && sum == c.Lock.Digest {
+			return nil
+		}
+	}
+	return []error{errors.Errorf("%s is out of sync with the dependencies in %s, run 'helm dependency update'", lockFileName, depsFileName)}
+}
+
+// validateDependencyArchive checks that an archive of the charts/ directory
+// is a declared dependency, at the locked version if the chart has a lock.
+func validateDependencyArchive(c *chart.Chart, archive, depsFileName string) error {
+	sub, err := loader.LoadFile(archive)
+	if err != nil {
+		return errors.Wrap(err, "unable to load chart")
+	}
+	for _, d := range c.Metadata.Dependencies {
+		if d.Name != sub.Name() {
+			continue
+		}
+		if c.Lock != nil {
+			for _, l := range c.Lock.Dependencies {
+				if l.Name == d.Name && l.Version != sub.Metadata.Version {
+					return errors.Errorf("chart %s version %s is not the locked version %s", sub.Name(), sub.Metadata.Version, l.Version)
+				}
+			}
+		}
+		if !satisfies(d.Version, sub.Metadata.Version) {
+			return errors.Errorf("chart %s version %s does not satisfy the version %s of the dependency", sub.Name(), sub.Metadata.Version, d.Version)
+		}
+		return nil
+	}
+	return errors.Errorf("chart %s is not a dependency in %s", sub.Name(), depsFileName)
+}
+
+// validateDependencyAliases returns the collisions between the names of
+// the subcharts of the dependencies, which are their aliases if they have
+// one, and between those and the subcharts which are not dependencies.
+func validateDependencyAliases(c *chart.Chart) []error {
+	var errs []error
+	seen := map[string]bool{}
+	declared := map[string]bool{}
+	for _, d := range c.Metadata.Dependencies {
+		declared[d.Name] = true
+	}
+	for _, sub := range c.Dependencies() {
+		if !declared[sub.Name()] {
+			seen[sub.Name()] = true
+		}
+	}
+	for _, d := range c.Metadata.Dependencies {
+		name := dependencyName(d)
+		if seen[name] {
+			if d.Alias != "" {
+				errs = append(errs, errors.Errorf("alias %q of dependency %q collides with another subchart", d.Alias, d.Name))
+			} else {
+				errs = append(errs, errors.Errorf("dependency %q collides with another subchart, set an alias", d.Name))
+			}
+		}
+		seen[name] = true
+	}
+	return errs
+}
+
+// validateDependencyConditions returns the conditions and tags of the
+// dependencies which the values of the chart do not define. Helm enables
+// dependencies whose conditions or tags are undefined.
+func validateDependencyConditions(c *chart.Chart) []error {
+	var errs []error
+	for _, d := range c.Metadata.Dependencies {
+		if d.Condition != "" {
+			v, ok := conditionValue(c, d.Condition)
+			if !ok {
+				errs = append(errs, errors.Errorf("condition %q of dependency %q is not defined in values.yaml", d.Condition, dependencyName(d)))
+			} else if _, isBool := v.(bool); !isBool {
+				errs = append(errs, errors.Errorf("condition %q of dependency %q is not a boolean", d.Condition, dependencyName(d)))
+			}
+		}
+		for _, tag := range d.Tags {
+			if _, err := chartutil.Values(c.Values).PathValue("tags." + tag); err != nil {
+				errs = append(errs, errors.Errorf("tag %q of dependency %q is not defined in values.yaml", tag, dependencyName(d)))
+			}
+		}
+	}
+	return errs
+}
+
+// conditionValue returns the value of the first path of a condition set by
+// the values of the chart or by those of the dependency it is below, which
+// Helm coalesces.
+func conditionValue(c *chart.Chart, condition string) (interface{}, bool) {
+	for _, cond := range strings.Split(condition, ",") {
+		cond = strings.TrimSpace(cond)
+		if v, err := chartutil.Values(c.Values).PathValue(cond); err == nil {
+			return v, true
+		}
+		parts := strings.SplitN(cond, ".", 2)
+		if len(parts) < 2 {
+			continue
+		}
+		for _, d := range c.Metadata.Dependencies {
+			if dependencyName(d) != parts[0] {
+				continue
+			}
+			for _, sub := range c.Dependencies() {
+				if sub.Name() != d.Name {
+					continue
+				}
+				if v, err := chartutil.Values(sub.Values).PathValue(parts[1]); err == nil {
+					return v, true
+				}
+			}
+		}
+	}
+	return nil, false
+}
+
+// dependencyName returns the name of the subchart of a dependency.
+func dependencyName(d *chart.Dependency) string {
+	if d.Alias != "" {
+		return d.Alias
+	}
+	return d.Name
+}
+
+// satisfies returns whether a version satisfies a constraint. An invalid
+// constraint or version is only satisfied by an identical one, and an empty
+// constraint by any version.
+func satisfies(constraint, version string) bool {
+	if constraint == "" || constraint == version {
+		return true
+	}
+	c, err := semver.NewConstraint(constraint)
+	if err != nil {
+		return false
+	}
+	v, err := semver.NewVersion(version)
+	if err != nil {
+		return false
+	}
+	return c.Check(v)
+}
@@ -0,0 +1,170 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rules
+
+import (
+	"testing"
+
+	"helm.sh/helm/v3/internal/resolver"
+	"helm.sh/helm/v3/pkg/chart"
+)
+
+func errorStrings(errs []error) []string {
+	var s []string
+	for _, err := range errs {
+		s = append(s, err.Error())
+	}
+	return s
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestValidateDependencyLock(t *testing.T) {
+	deps := []*chart.Dependency{
+		{Name: "db", Version: "^1.2.0", Repository: "https://example.com/charts"},
+		{Name: "cache", Version: "2.0.0", Repository: "https://example.com/charts"},
+	}
+	locked := []*chart.Dependency{
+		{Name: "db", Version: "1.3.1", Repository: "https://example.com/charts"},
+		{Name: "cache", Version: "2.0.0", Repository: "https://example.com/charts"},
+	}
+	digest, err := resolver.HashReq(deps, locked)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		deps   []*chart.Dependency
+		lock   *chart.Lock
+		expect []string
+	}{
+		{
+			name: "in sync",
+			deps: deps,
+			lock: &chart.Lock{Digest: digest, Dependencies: locked},
+		},
+		{
+			name:   "stale digest",
+			deps:   deps,
+			lock:   &chart.Lock{Digest: "sha256:0", Dependencies: locked},
+			expect: []string{"Chart.lock is out of sync with the dependencies in Chart.yaml, run 'helm dependency update'"},
+		},
+		{
+			name: "dependency changed",
+			deps: []*chart.Dependency{
+				{Name: "db", Version: "^2.0.0", Repository: "https://example.com/charts"},
+				{Name: "queue", Version: "1.0.0", Repository: "https://example.com/charts"},
+			},
+			lock: &chart.Lock{Digest: digest, Dependencies: locked},
+			expect: []string{
+				`dependency "db" is locked to version 1.3.1, which does not satisfy ^2.0.0`,
+				`dependency "queue" is not locked`,
+				`locked dependency "cache" is not in Chart.yaml`,
+			},
+		},
+		{
+			name: "repository referred to by name",
+			deps: []*chart.Dependency{
+				{Name: "db", Version: "^1.2.0", Repository: "@example"},
+				{Name: "cache", Version: "2.0.0", Repository: "@example"},
+			},
+			lock: &chart.Lock{Digest: "sha256:0", Dependencies: locked},
+		},
+	}
+
+	for _, tt := range tests {
+		c := &chart.Chart{
+			Metadata: &chart.Metadata{Name: "web", APIVersion: chart.APIVersionV2, Dependencies: tt.deps},
+			Lock:     tt.lock,
+		}
+		got := errorStrings(validateDependencyLock(c, "Chart.lock", "Chart.yaml"))
+		if !equalStrings(got, tt.expect) {
+			t.Errorf("%s: expected %q, got %q", tt.name, tt.expect, got)
+		}
+	}
+}
+
+func TestValidateDependencyAliases(t *testing.T) {
+	c := &chart.Chart{
+		Metadata: &chart.Metadata{
+			Name: "web",
+			Dependencies: []*chart.Dependency{
+				{Name: "db"},
+				{Name: "postgresql", Alias: "db"},
+				{Name: "redis", Alias: "cache"},
+				{Name: "redis", Alias: "sessions"},
+				{Name: "memcached", Alias: "vendored"},
+			},
+		},
+	}
+	for _, name := range []string{"db", "postgresql", "redis", "vendored"} {
+		c.AddDependency(&chart.Chart{Metadata: &chart.Metadata{Name: name}})
+	}
+
+	expect := []string{
+		`alias "db" of dependency "postgresql" collides with another subchart`,
+		`alias "vendored" of dependency "memcached" collides with another subchart`,
+	}
+	if got := errorStrings(validateDependencyAliases(c)); !equalStrings(got, expect) {
+		t.Errorf("expected %q, got %q", expect, got)
+	}
+}
+
+func TestValidateDependencyConditions(t *testing.T) {
+	c := &chart.Chart{
+		Metadata: &chart.Metadata{
+			Name: "web",
+			Dependencies: []*chart.Dependency{
+				{Name: "db", Condition: "db.enabled"},
+				{Name: "redis", Alias: "cache", Condition: "cache.enabled"},
+				{Name: "queue", Condition: "queue.enabled,global.queue.enabled", Tags: []string{"backend", "async"}},
+				{Name: "search", Condition: "search"},
+				{Name: "mail", Condition: "mail.enabled"},
+			},
+		},
+		Values: map[string]interface{}{
+			"db":     map[string]interface{}{"enabled": true},
+			"global": map[string]interface{}{"queue": map[string]interface{}{"enabled": false}},
+			"search": "yes",
+			"tags":   map[string]interface{}{"backend": true},
+		},
+	}
+	c.AddDependency(&chart.Chart{
+		Metadata: &chart.Metadata{Name: "redis"},
+		Values:   map[string]interface{}{"enabled": false},
+	})
+
+	expect := []string{
+		`tag "async" of dependency "queue" is not defined in values.yaml`,
+		`condition "search" of dependency "search" is not a boolean`,
+		`condition "mail.enabled" of dependency "mail" is not defined in values.yaml`,
+	}
+	if got := errorStrings(validateDependencyConditions(c)); !equalStrings(got, expect) {
+		t.Errorf("expected %q, got %q", expect, got)
+	}
+}
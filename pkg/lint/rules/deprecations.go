@@ -16,65 +16,53 @@ limitations under the License.
 
 package rules // import "helm.sh/helm/v3/pkg/lint/rules"
 
-import "fmt"
+import (
+	"fmt"
 
-// deprecatedAPIs lists APIs that are deprecated (left) with suggested alternatives (right).
-//
-// An empty rvalue indicates that the API is completely deprecated.
-var deprecatedAPIs = map[string]string{
-	"extensions/v1beta1 Deployment":                             "apps/v1 Deployment",
-	"extensions/v1beta1 DaemonSet":                              "apps/v1 DaemonSet",
-	"extensions/v1beta1 ReplicaSet":                             "apps/v1 ReplicaSet",
-	"extensions/v1beta1 PodSecurityPolicy":                      "policy/v1beta1 PodSecurityPolicy",
-	"extensions/v1beta1 NetworkPolicy":                          "networking.k8s.io/v1beta1 NetworkPolicy",
-	"extensions/v1beta1 Ingress":                                "networking.k8s.io/v1beta1 Ingress",
-	"apps/v1beta1 Deployment":                                   "apps/v1 Deployment",
-	"apps/v1beta1 StatefulSet":                                  "apps/v1 StatefulSet",
-	"apps/v1beta1 ReplicaSet":                                   "apps/v1 ReplicaSet",
-	"apps/v1beta2 Deployment":                                   "apps/v1 Deployment",
-	"apps/v1beta2 StatefulSet":                                  "apps/v1 StatefulSet",
-	"apps/v1beta2 DaemonSet":                                    "apps/v1 DaemonSet",
-	"apps/v1beta2 ReplicaSet":                                   "apps/v1 ReplicaSet",
-	"apiextensions.k8s.io/v1beta1 CustomResourceDefinition":     "apiextensions.k8s.io/v1 CustomResourceDefinition",
-	"rbac.authorization.k8s.io/v1alpha1 ClusterRole":            "rbac.authorization.k8s.io/v1 ClusterRole",
-	"rbac.authorization.k8s.io/v1alpha1 ClusterRoleList":        "rbac.authorization.k8s.io/v1 ClusterRoleList",
-	"rbac.authorization.k8s.io/v1alpha1 ClusterRoleBinding":     "rbac.authorization.k8s.io/v1 ClusterRoleBinding",
-	"rbac.authorization.k8s.io/v1alpha1 ClusterRoleBindingList": "rbac.authorization.k8s.io/v1 ClusterRoleBindingList",
-	"rbac.authorization.k8s.io/v1alpha1 Role":                   "rbac.authorization.k8s.io/v1 Role",
-	"rbac.authorization.k8s.io/v1alpha1 RoleList":               "rbac.authorization.k8s.io/v1 RoleList",
-	"rbac.authorization.k8s.io/v1alpha1 RoleBinding":            "rbac.authorization.k8s.io/v1 RoleBinding",
-	"rbac.authorization.k8s.io/v1alpha1 RoleBindingList":        "rbac.authorization.k8s.io/v1 RoleBindingList",
-	"rbac.authorization.k8s.io/v1beta1 ClusterRole":             "rbac.authorization.k8s.io/v1 ClusterRole",
-	"rbac.authorization.k8s.io/v1beta1 ClusterRoleList":         "rbac.authorization.k8s.io/v1 ClusterRoleList",
-	"rbac.authorization.k8s.io/v1beta1 ClusterRoleBinding":      "rbac.authorization.k8s.io/v1 ClusterRoleBinding",
-	"rbac.authorization.k8s.io/v1beta1 ClusterRoleBindingList":  "rbac.authorization.k8s.io/v1 ClusterRoleBindingList",
-	"rbac.authorization.k8s.io/v1beta1 Role":                    "rbac.authorization.k8s.io/v1 Role",
-	"rbac.authorization.k8s.io/v1beta1 RoleList":                "rbac.authorization.k8s.io/v1 RoleList",
-	"rbac.authorization.k8s.io/v1beta1 RoleBinding":             "rbac.authorization.k8s.io/v1 RoleBinding",
-	"rbac.authorization.k8s.io/v1beta1 RoleBindingList":         "rbac.authorization.k8s.io/v1 RoleBindingList",
-}
+	"helm.sh/helm/v3/pkg/chartutil"
+)
 
 // deprecatedAPIError indicates than an API is deprecated in Kubernetes
 type deprecatedAPIError struct {
 	Deprecated  string
 	Alternative string
+	// Version is the version of Kubernetes deprecating or removing the API.
+	Version string
+	// Removed is whether the API is no longer served.
+	Removed bool
 }
 
 func (e deprecatedAPIError) Error() string {
 	msg := fmt.Sprintf("the kind %q is deprecated", e.Deprecated)
+	if e.Removed {
+		msg = fmt.Sprintf("the kind %q is removed", e.Deprecated)
+	}
+	if e.Version != "" {
+		msg += fmt.Sprintf(" in Kubernetes %s", e.Version)
+	}
 	if e.Alternative != "" {
 		msg += fmt.Sprintf(" in favor of %q", e.Alternative)
 	}
 	return msg
 }
 
+// validateNoDeprecations checks that a resource uses no API deprecated by the
+// default Kubernetes version.
 func validateNoDeprecations(resource *K8sYamlStruct) error {
-	gvk := fmt.Sprintf("%s %s", resource.APIVersion, resource.Kind)
-	if alt, ok := deprecatedAPIs[gvk]; ok {
-		return deprecatedAPIError{
-			Deprecated:  gvk,
-			Alternative: alt,
-		}
+	return validateAPIDeprecation(resource, chartutil.DefaultDeprecatedAPIs, chartutil.DefaultCapabilities.KubeVersion)
+}
+
+// validateAPIDeprecation checks that a resource uses no API deprecated or
+// removed by a Kubernetes version.
+func validateAPIDeprecation(resource *K8sYamlStruct, apis chartutil.DeprecatedAPIs, kubeVersion chartutil.KubeVersion) error {
+	api := apis.Lookup(resource.APIVersion, resource.Kind)
+	switch {
+	case api == nil:
+		return nil
+	case api.IsRemoved(kubeVersion):
+		return deprecatedAPIError{Deprecated: api.String(), Alternative: api.Replacement, Version: api.RemovedIn, Removed: true}
+	case api.IsDeprecated(kubeVersion):
+		return deprecatedAPIError{Deprecated: api.String(), Alternative: api.Replacement, Version: api.DeprecatedIn}
 	}
 	return nil
 }
@@ -16,7 +16,13 @@ limitations under the License.
 
 package rules // import "helm.sh/helm/v3/pkg/lint/rules"
 
-import "testing"
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+
+	"helm.sh/helm/v3/pkg/chartutil"
+)
 
 func TestValidateNoDeprecations(t *testing.T) {
 	deprecated := &K8sYamlStruct{
@@ -40,3 +46,36 @@ func TestValidateNoDeprecations(t *testing.T) {
 		t.Errorf("Expected a v1 Pod to not be deprecated")
 	}
 }
+
+func TestValidateAPIDeprecation(t *testing.T) {
+	ingress := &K8sYamlStruct{APIVersion: "networking.k8s.io/v1beta1", Kind: "Ingress"}
+	tests := []struct {
+		version string
+		err     string
+	}{
+		{"1.18", ""},
+		{"1.19", `the kind "networking.k8s.io/v1beta1 Ingress" is deprecated in Kubernetes 1.19 in favor of "networking.k8s.io/v1 Ingress"`},
+		{"1.22", `the kind "networking.k8s.io/v1beta1 Ingress" is removed in Kubernetes 1.22 in favor of "networking.k8s.io/v1 Ingress"`},
+	}
+	for _, tt := range tests {
+		v, err := chartutil.ParseKubeVersion(tt.version)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = validateAPIDeprecation(ingress, chartutil.DefaultDeprecatedAPIs, v)
+		if tt.err == "" {
+			if err != nil {
+				t.Errorf("Kubernetes %s: unexpected error %q", tt.version, err)
+			}
+			continue
+		}
+		if err == nil || err.Error() != tt.err {
+			t.Errorf("Kubernetes %s: expected error %q, got %v", tt.version, tt.err, err)
+			continue
+		}
+		var de deprecatedAPIError
+		if !errors.As(err, &de) || de.Removed != (tt.version == "1.22") {
+			t.Errorf("Kubernetes %s: unexpected error %#v", tt.version, err)
+		}
+	}
+}
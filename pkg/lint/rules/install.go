@@ -0,0 +1,146 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rules
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/yaml"
+
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/engine"
+	"helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/releaseutil"
+)
+
+// RenderedRelease is a chart rendered as Helm installs it.
+type RenderedRelease struct {
+	// Files are the rendered templates by path, e.g.
+	// "mychart/templates/deployment.yaml", including the notes and outputs.
+	Files map[string]string
+	// Hooks and Manifests are the objects of the templates, in install order.
+	Hooks     []*release.Hook
+	Manifests []releaseutil.Manifest
+}
+
+// ReleaseRenderer renders a chart as Helm installs it, with the given
+// capabilities. When the templates render but their objects cannot be
+// sorted, it returns the rendered Files with the error.
+type ReleaseRenderer func(c *chart.Chart, values chartutil.Values, caps *chartutil.Capabilities) (*RenderedRelease, error)
+
+// renderRelease is the ReleaseRenderer of TemplatesWithOptions when none is
+// given. It renders in lint mode and reproducibly, and sorts the objects of
+// the templates apart from the notes and outputs, as Helm does.
+func renderRelease(fixtures *engine.Fixtures) ReleaseRenderer {
+	return func(c *chart.Chart, values chartutil.Values, caps *chartutil.Capabilities) (*RenderedRelease, error) {
+		var seed int64
+		now := engine.DefaultRenderTime
+		e := engine.Engine{LintMode: true, RenderSeed: &seed, RenderTime: &now, LookupFixtures: fixtures}
+		files, err := e.Render(c, values)
+		if err != nil {
+			return nil, err
+		}
+		r := &RenderedRelease{Files: files}
+		objects := map[string]string{}
+		for name, content := range files {
+			if !strings.HasSuffix(name, "NOTES.txt") && !strings.HasSuffix(name, "OUTPUTS.yaml") {
+				objects[name] = content
+			}
+		}
+		r.Hooks, r.Manifests, err = releaseutil.SortManifests(objects, caps.APIVersions, releaseutil.InstallOrder)
+		return r, err
+	}
+}
+
+// hookDeletePolicies are the known values of the helm.sh/hook-delete-policy
+// annotation.
+var hookDeletePolicies = map[release.HookDeletePolicy]bool{
+	release.HookSucceeded:          true,
+	release.HookFailed:             true,
+	release.HookBeforeHookCreation: true,
+}
+
+// validateHookAnnotations checks the hook annotations of the objects of a
+// rendered template. Helm skips the objects with unknown hook events, and
+// ignores invalid weights and delete policies.
+func validateHookAnnotations(content string) error {
+	for _, m := range sortedManifests(content) {
+		var head releaseutil.SimpleHead
+		if err := yaml.Unmarshal([]byte(m), &head); err != nil || head.Metadata == nil {
+			continue
+		}
+		annotations := head.Metadata.Annotations
+		events, ok := annotations[release.HookAnnotation]
+		if !ok {
+			continue
+		}
+		for _, event := range strings.Split(events, ",") {
+			// crd-install hooks are reported by the crd-hooks rule.
+			if _, ok := releaseutil.ParseHookEvent(event); !ok && !strings.EqualFold(strings.TrimSpace(event), "crd-install") {
+				return errors.Errorf("%s %q has an unknown hook event %q, and would not be installed", head.Kind, head.Metadata.Name, strings.TrimSpace(event))
+			}
+		}
+		if weight, ok := annotations[release.HookWeightAnnotation]; ok {
+			if _, err := strconv.Atoi(weight); err != nil {
+				return errors.Errorf("%s %q has a hook weight %q which is not an integer", head.Kind, head.Metadata.Name, weight)
+			}
+		}
+		if policies, ok := annotations[release.HookDeleteAnnotation]; ok {
+			for _, policy := range strings.Split(policies, ",") {
+				policy = strings.ToLower(strings.TrimSpace(policy))
+				if !hookDeletePolicies[release.HookDeletePolicy(policy)] {
+					return errors.Errorf("%s %q has an unknown hook delete policy %q", head.Kind, head.Metadata.Name, policy)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// validateCRD checks that a file of the crds/ directory holds Kubernetes
+// objects, which Helm installs before rendering the templates.
+func validateCRD(data []byte) error {
+	for _, m := range sortedManifests(string(data)) {
+		var head releaseutil.SimpleHead
+		if err := yaml.Unmarshal([]byte(m), &head); err != nil {
+			return errors.Wrap(err, "unable to parse YAML")
+		}
+		if head.Version == "" || head.Kind == "" {
+			return errors.New("object has no apiVersion or kind")
+		}
+	}
+	return nil
+}
+
+// sortedManifests returns the documents of a YAML stream in order.
+func sortedManifests(content string) []string {
+	manifests := releaseutil.SplitManifests(content)
+	keys := make([]string, 0, len(manifests))
+	for k := range manifests {
+		keys = append(keys, k)
+	}
+	sort.Sort(releaseutil.BySplitManifestsOrder(keys))
+	docs := make([]string, 0, len(keys))
+	for _, k := range keys {
+		docs = append(docs, manifests[k])
+	}
+	return docs
+}
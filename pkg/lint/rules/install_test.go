@@ -0,0 +1,86 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rules
+
+import (
+	"testing"
+)
+
+func TestValidateHookAnnotations(t *testing.T) {
+	manifest := func(annotations string) string {
+		return "apiVersion: batch/v1\nkind: Job\nmetadata:\n  name: migrate\n  annotations:\n" + annotations
+	}
+	tests := []struct {
+		name     string
+		manifest string
+		expect   string
+	}{
+		{
+			name:     "not a hook",
+			manifest: manifest("    team: db\n"),
+		},
+		{
+			name:     "valid hook",
+			manifest: manifest("    helm.sh/hook: pre-install, Post-Upgrade\n    helm.sh/hook-weight: \"-5\"\n    helm.sh/hook-delete-policy: before-hook-creation,hook-succeeded\n"),
+		},
+		{
+			name:     "crd-install hook",
+			manifest: manifest("    helm.sh/hook: crd-install\n"),
+		},
+		{
+			name:     "unknown event",
+			manifest: "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: config\n---\n" + manifest("    helm.sh/hook: pre-install,post-instal\n"),
+			expect:   `Job "migrate" has an unknown hook event "post-instal", and would not be installed`,
+		},
+		{
+			name:     "invalid weight",
+			manifest: manifest("    helm.sh/hook: pre-install\n    helm.sh/hook-weight: first\n"),
+			expect:   `Job "migrate" has a hook weight "first" which is not an integer`,
+		},
+		{
+			name:     "unknown delete policy",
+			manifest: manifest("    helm.sh/hook: pre-install\n    helm.sh/hook-delete-policy: hook-succeeded,after-install\n"),
+			expect:   `Job "migrate" has an unknown hook delete policy "after-install"`,
+		},
+	}
+
+	for _, tt := range tests {
+		err := validateHookAnnotations(tt.manifest)
+		if tt.expect == "" {
+			if err != nil {
+				t.Errorf("%s: unexpected error: %s", tt.name, err)
+			}
+			continue
+		}
+		if err == nil || err.Error() != tt.expect {
+			t.Errorf("%s: expected error %q, got %v", tt.name, tt.expect, err)
+		}
+	}
+}
+
+func TestValidateCRD(t *testing.T) {
+	valid := "apiVersion: apiextensions.k8s.io/v1\nkind: CustomResourceDefinition\nmetadata:\n  name: crontabs.stable.example.com\n"
+	if err := validateCRD([]byte(valid + "---\n" + valid)); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+	if err := validateCRD([]byte(valid + "---\nkind: CustomResourceDefinition\n")); err == nil {
+		t.Error("expected an error for an object without apiVersion")
+	}
+	if err := validateCRD([]byte(valid + "---\n- crontabs\n")); err == nil {
+		t.Error("expected an error for a document which is not an object")
+	}
+}
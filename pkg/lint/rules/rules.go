@@ -0,0 +1,250 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rules
+
+import "helm.sh/helm/v3/pkg/lint/support"
+
+// The lint rules. Their IDs are stable: new rules get new IDs, and the IDs
+// of removed rules are not reused.
+var (
+	ChartfileNotDirectory = support.Rule{
+		ID: "HL001", Name: "chart-yaml-file", Severity: support.ErrorSev,
+		Description: "Chart.yaml is a file",
+	}
+	ChartfileFormat = support.Rule{
+		ID: "HL002", Name: "chart-yaml-format", Severity: support.ErrorSev,
+		Description: "Chart.yaml is valid YAML",
+	}
+	ChartName = support.Rule{
+		ID: "HL003", Name: "chart-name", Severity: support.ErrorSev,
+		Description: "the chart has a name",
+	}
+	ChartAPIVersion = support.Rule{
+		ID: "HL004", Name: "chart-api-version", Severity: support.ErrorSev,
+		Description: "the apiVersion of the chart is v1 or v2",
+	}
+	ChartVersion = support.Rule{
+		ID: "HL005", Name: "chart-version", Severity: support.ErrorSev,
+		Description: "the version of the chart is a semantic version",
+	}
+	ChartMaintainers = support.Rule{
+		ID: "HL006", Name: "chart-maintainers", Severity: support.ErrorSev,
+		Description: "the maintainers of the chart have names and valid emails and URLs",
+	}
+	ChartSources = support.Rule{
+		ID: "HL007", Name: "chart-sources", Severity: support.ErrorSev,
+		Description: "the sources of the chart are valid URLs",
+	}
+	ChartIcon = support.Rule{
+		ID: "HL008", Name: "chart-icon", Severity: support.InfoSev,
+		Description: "the chart has an icon",
+	}
+	ChartIconURL = support.Rule{
+		ID: "HL009", Name: "chart-icon-url", Severity: support.ErrorSev,
+		Description: "the icon of the chart is a valid URL",
+	}
+	ChartType = support.Rule{
+		ID: "HL010", Name: "chart-type", Severity: support.ErrorSev,
+		Description: "the type of the chart is only set with apiVersion v2",
+	}
+	ChartDependencies = support.Rule{
+		ID: "HL011", Name: "chart-dependencies", Severity: support.ErrorSev,
+		Description: "dependencies are declared in Chart.yaml only with apiVersion v2",
+	}
+	ValuesFileExists = support.Rule{
+		ID: "HL012", Name: "values-file", Severity: support.InfoSev,
+		Description: "the chart has a values.yaml file",
+	}
+	ValuesFileFormat = support.Rule{
+		ID: "HL013", Name: "values-format", Severity: support.ErrorSev,
+		Description: "values.yaml is valid YAML",
+	}
+	ValuesSchema = support.Rule{
+		ID: "HL014", Name: "values-schema", Severity: support.ErrorSev,
+		Description: "the values validate against values.schema.json",
+	}
+	TemplatesDir = support.Rule{
+		ID: "HL015", Name: "templates-dir", Severity: support.WarningSev,
+		Description: "the chart has a templates directory",
+	}
+	ChartLoad = support.Rule{
+		ID: "HL016", Name: "chart-load", Severity: support.ErrorSev,
+		Description: "the chart can be loaded",
+	}
+	KubeVersion = support.Rule{
+		ID: "HL017", Name: "kube-version", Severity: support.ErrorSev,
+		Description: "the kubeVersion of the chart is compatible with the given capabilities",
+	}
+	TemplatesRender = support.Rule{
+		ID: "HL018", Name: "templates-render", Severity: support.ErrorSev,
+		Description: "the templates render",
+	}
+	MissingValues = support.Rule{
+		ID: "HL019", Name: "missing-values", Severity: support.ErrorSev, Disabled: true,
+		Description: "the templates only reference values which are set (enabled by --strict-values)",
+	}
+	TemplateExtension = support.Rule{
+		ID: "HL020", Name: "template-extension", Severity: support.ErrorSev,
+		Description: "templates have a .yaml, .yml, .tpl or .txt extension",
+	}
+	CRDHooks = support.Rule{
+		ID: "HL021", Name: "crd-hooks", Severity: support.WarningSev,
+		Description: "templates do not use the crd-install hook, which Helm 3 ignores",
+	}
+	ReleaseTime = support.Rule{
+		ID: "HL022", Name: "release-time", Severity: support.ErrorSev,
+		Description: "templates do not use .Release.Time, which Helm 3 removed",
+	}
+	Outputs = support.Rule{
+		ID: "HL023", Name: "outputs", Severity: support.ErrorSev,
+		Description: "templates/OUTPUTS.yaml renders to a YAML map",
+	}
+	ManifestFormat = support.Rule{
+		ID: "HL024", Name: "manifest-format", Severity: support.ErrorSev,
+		Description: "templates render to valid YAML",
+	}
+	MetadataName = support.Rule{
+		ID: "HL025", Name: "metadata-name", Severity: support.ErrorSev,
+		Description: "the names of the objects are valid",
+	}
+	DeprecatedAPIs = support.Rule{
+		ID: "HL026", Name: "deprecated-apis", Severity: support.WarningSev,
+		Description: "the objects do not use Kubernetes APIs deprecated by the target Kubernetes version",
+	}
+	ResourceRequirements = support.Rule{
+		ID: "HL027", Name: "resource-requirements", Severity: support.WarningSev, Disabled: true,
+		Description: "containers set resource requests and limits (best practice)",
+	}
+	ImageTag = support.Rule{
+		ID: "HL028", Name: "image-tag", Severity: support.WarningSev, Disabled: true,
+		Description: "containers use tagged images other than latest (best practice)",
+	}
+	Probes = support.Rule{
+		ID: "HL029", Name: "probes", Severity: support.WarningSev, Disabled: true,
+		Description: "containers of long-running workloads have liveness and readiness probes (best practice)",
+	}
+	SecurityContext = support.Rule{
+		ID: "HL030", Name: "security-context", Severity: support.WarningSev, Disabled: true,
+		Description: "containers are not privileged and do not run as root (best practice)",
+	}
+	HostPath = support.Rule{
+		ID: "HL031", Name: "host-path", Severity: support.WarningSev, Disabled: true,
+		Description: "pods do not mount hostPath volumes (best practice)",
+	}
+	RecommendedLabels = support.Rule{
+		ID: "HL032", Name: "recommended-labels", Severity: support.InfoSev, Disabled: true,
+		Description: "objects have the recommended app.kubernetes.io labels (best practice)",
+	}
+	ServiceSelector = support.Rule{
+		ID: "HL033", Name: "service-selector", Severity: support.WarningSev, Disabled: true,
+		Description: "the selectors of Services match pods rendered by the chart (best practice)",
+	}
+	RemovedAPIs = support.Rule{
+		ID: "HL034", Name: "removed-apis", Severity: support.ErrorSev,
+		Description: "the objects do not use Kubernetes APIs removed by the target Kubernetes version",
+	}
+	UnusedValues = support.Rule{
+		ID: "HL035", Name: "unused-values", Severity: support.WarningSev, Disabled: true,
+		Description: "the values defined in values.yaml are read by the templates (enabled by --values-usage)",
+	}
+	UndocumentedValues = support.Rule{
+		ID: "HL036", Name: "undocumented-values", Severity: support.WarningSev, Disabled: true,
+		Description: "the values read by the templates are defined in values.yaml or values.schema.json (enabled by --values-usage)",
+	}
+	DependencyLock = support.Rule{
+		ID: "HL037", Name: "dependency-lock", Severity: support.ErrorSev,
+		Description: "Chart.lock is in sync with the dependencies of Chart.yaml",
+	}
+	UndeclaredDependencies = support.Rule{
+		ID: "HL038", Name: "undeclared-dependencies", Severity: support.WarningSev,
+		Description: "the archives in charts/ are the dependencies of Chart.yaml, at their locked versions",
+	}
+	DependencyAliases = support.Rule{
+		ID: "HL039", Name: "dependency-aliases", Severity: support.ErrorSev,
+		Description: "the names and aliases of the dependencies do not collide with other subcharts",
+	}
+	DependencyConditions = support.Rule{
+		ID: "HL040", Name: "dependency-conditions", Severity: support.WarningSev,
+		Description: "the conditions and tags of the dependencies are defined in values.yaml",
+	}
+	InstallRender = support.Rule{
+		ID: "HL041", Name: "install-render", Severity: support.ErrorSev,
+		Description: "the chart renders as Helm installs it, with its hooks and manifests sorted in install order",
+	}
+	HookAnnotations = support.Rule{
+		ID: "HL042", Name: "hook-annotations", Severity: support.ErrorSev,
+		Description: "the hook annotations have known events, integer weights and known delete policies",
+	}
+	CRDs = support.Rule{
+		ID: "HL043", Name: "crds", Severity: support.ErrorSev,
+		Description: "the files of the crds/ directory are valid Kubernetes objects",
+	}
+	DuplicateResources = support.Rule{
+		ID: "HL044", Name: "duplicate-resources", Severity: support.ErrorSev,
+		Description: "no two templates of the chart and its subcharts render the same object, including hooks",
+	}
+)
+
+// Rules returns all the lint rules, in the order of their IDs.
+func Rules() []support.Rule {
+	return []support.Rule{
+		ChartfileNotDirectory,
+		ChartfileFormat,
+		ChartName,
+		ChartAPIVersion,
+		ChartVersion,
+		ChartMaintainers,
+		ChartSources,
+		ChartIcon,
+		ChartIconURL,
+		ChartType,
+		ChartDependencies,
+		ValuesFileExists,
+		ValuesFileFormat,
+		ValuesSchema,
+		TemplatesDir,
+		ChartLoad,
+		KubeVersion,
+		TemplatesRender,
+		MissingValues,
+		TemplateExtension,
+		CRDHooks,
+		ReleaseTime,
+		Outputs,
+		ManifestFormat,
+		MetadataName,
+		DeprecatedAPIs,
+		ResourceRequirements,
+		ImageTag,
+		Probes,
+		SecurityContext,
+		HostPath,
+		RecommendedLabels,
+		ServiceSelector,
+		RemovedAPIs,
+		UnusedValues,
+		UndocumentedValues,
+		DependencyLock,
+		UndeclaredDependencies,
+		DependencyAliases,
+		DependencyConditions,
+		InstallRender,
+		HookAnnotations,
+		CRDs,
+		DuplicateResources,
+	}
+}
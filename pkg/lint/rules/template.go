@@ -30,6 +30,7 @@ import (
 	"helm.sh/helm/v3/pkg/chartutil"
 	"helm.sh/helm/v3/pkg/engine"
 	"helm.sh/helm/v3/pkg/lint/support"
+	"helm.sh/helm/v3/pkg/releaseutil"
 )
 
 var (
@@ -37,6 +38,9 @@ var (
 	releaseTimeSearch = regexp.MustCompile(`\.Release\.Time`)
 )
 
+// outputsFileName is the template holding the structured outputs of a chart.
+const outputsFileName = "templates/OUTPUTS.yaml"
+
 // validName is a regular expression for names.
 //
 // This is different than action.ValidName. It conforms to the regular expression
@@ -45,12 +49,52 @@ var (
 // For details, see https://kubernetes.io/docs/concepts/overview/working-with-objects/names/#names
 var validName = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*$`)
 
+// TemplateOptions are the options of TemplatesWithOptions.
+type TemplateOptions struct {
+	// Namespace is the namespace of the release the templates are rendered for.
+	Namespace string
+	// Capabilities, if set, replace the default capabilities when rendering.
+	Capabilities *chartutil.Capabilities
+	// StrictValues enables the MissingValues rule, which reports every
+	// reference of the templates to a value which is not set, except for
+	// those in OptionalValues.
+	StrictValues   bool
+	OptionalValues []string
+	// BestPractices enables the best-practice rules, unless the configuration
+	// disables them.
+	BestPractices bool
+	// DeprecatedAPIs, if set, replace chartutil.DefaultDeprecatedAPIs. The
+	// APIs are checked against the Kubernetes version of Capabilities, or else
+	// of chartutil.DefaultCapabilities.
+	DeprecatedAPIs chartutil.DeprecatedAPIs
+	// ValuesUsage enables the UnusedValues and UndocumentedValues rules,
+	// unless the configuration disables them.
+	ValuesUsage bool
+	// Checkers are run with the rendered chart, e.g. those of the lint
+	// plugins.
+	Checkers []support.Checker
+	// LookupFixtures, if set, are queried by the lookup template function,
+	// which otherwise returns empty objects.
+	LookupFixtures *engine.Fixtures
+	// Render, if set, renders the chart as Helm installs it. The templates
+	// are checked with what it renders, and the errors installing the chart
+	// would fail with, such as those sorting its manifests, are reported.
+	Render ReleaseRenderer
+}
+
 // Templates lints the templates in the Linter.
 func Templates(linter *support.Linter, values map[string]interface{}, namespace string, strict bool) {
+	TemplatesWithOptions(linter, values, TemplateOptions{Namespace: namespace})
+}
+
+// TemplatesWithOptions lints the templates in the Linter with the given
+// options.
+func TemplatesWithOptions(linter *support.Linter, values map[string]interface{}, opts TemplateOptions) {
+	caps := opts.Capabilities
 	path := "templates/"
 	templatesPath := filepath.Join(linter.ChartDir, path)
 
-	templatesDirExist := linter.RunLinterRule(support.WarningSev, path, validateTemplatesDir(templatesPath))
+	templatesDirExist := linter.RunRule(TemplatesDir, path, validateTemplatesDir(templatesPath))
 
 	// Templates directory is optional for now
 	if !templatesDirExist {
@@ -60,35 +104,120 @@ func Templates(linter *support.Linter, values map[string]interface{}, namespace
 	// Load chart and parse templates
 	chart, err := loader.Load(linter.ChartDir)
 
-	chartLoaded := linter.RunLinterRule(support.ErrorSev, path, err)
+	chartLoaded := linter.RunRule(ChartLoad, path, err)
 
 	if !chartLoaded {
 		return
 	}
 
+	for _, crd := range chart.CRDObjects() {
+		linter.RunRule(CRDs, strings.TrimPrefix(crd.Filename, chart.Name()+"/"), validateCRD(crd.File.Data))
+	}
+
+	// Given the capabilities of a cluster, the chart must support its version
+	// as it would have to when installed.
+	if caps != nil && chart.Metadata.KubeVersion != "" && !chartutil.IsCompatibleRange(chart.Metadata.KubeVersion, caps.KubeVersion.String()) {
+		linter.RunRule(KubeVersion, path, errors.Errorf("chart requires kubeVersion: %s which is incompatible with Kubernetes %s", chart.Metadata.KubeVersion, caps.KubeVersion.String()))
+		return
+	}
+
 	options := chartutil.ReleaseOptions{
 		Name:      "test-release",
-		Namespace: namespace,
+		Namespace: opts.Namespace,
 	}
 
 	cvals, err := chartutil.CoalesceValues(chart, values)
 	if err != nil {
 		return
 	}
-	valuesToRender, err := chartutil.ToRenderValues(chart, cvals, options, nil)
+	valuesToRender, err := chartutil.ToRenderValues(chart, cvals, options, caps)
 	if err != nil {
-		linter.RunLinterRule(support.ErrorSev, path, err)
+		linter.RunRule(TemplatesRender, path, err)
 		return
 	}
-	var e engine.Engine
-	e.LintMode = true
-	renderedContentMap, err := e.Render(chart, valuesToRender)
-
-	renderOk := linter.RunLinterRule(support.ErrorSev, path, err)
+	if opts.StrictValues {
+		linter.Config = linter.Config.Merge(&support.Config{Rules: map[string]support.RuleConfig{
+			MissingValues.ID: {Enabled: &opts.StrictValues},
+		}})
+	}
+	if opts.ValuesUsage {
+		pack := &support.Config{Rules: map[string]support.RuleConfig{
+			UnusedValues.ID:       {Enabled: &opts.ValuesUsage},
+			UndocumentedValues.ID: {Enabled: &opts.ValuesUsage},
+		}}
+		linter.Config = pack.Merge(linter.Config)
+	}
+	// The values the templates read are found by following them once,
+	// without rendering them.
+	checkMissing := linter.RuleEnabled(MissingValues)
+	checkUsage := linter.RuleEnabled(UnusedValues) || linter.RuleEnabled(UndocumentedValues)
+	if checkMissing || checkUsage {
+		e := engine.Engine{OptionalValues: opts.OptionalValues}
+		missing, refs, err := e.CheckValues(chart, valuesToRender)
+		if !linter.RunRule(TemplatesRender, path, err) {
+			return
+		}
+		for _, m := range missing {
+			if checkMissing {
+				linter.RunRule(MissingValues, fmt.Sprintf("%s:%d", strings.TrimPrefix(m.Template, chart.Name()+"/"), m.Line),
+					errors.Errorf("value %s is not set", m.Path))
+			}
+		}
+		if checkUsage {
+			validateValuesUsage(linter, chart, refs)
+		}
+	}
 
-	if !renderOk {
+	// The chart is rendered once, as Helm installs it, and the templates are
+	// checked one by one with what it renders.
+	render := opts.Render
+	if render == nil {
+		render = renderRelease(opts.LookupFixtures)
+	}
+	renderCaps := caps
+	if renderCaps == nil {
+		renderCaps = chartutil.DefaultCapabilities
+	}
+	rel, installErr := render(chart, valuesToRender, renderCaps)
+	if rel == nil || rel.Files == nil {
+		linter.RunRule(TemplatesRender, path, installErr)
 		return
 	}
+	renderedContentMap := rel.Files
+
+	if len(opts.Checkers) > 0 {
+		rendered := &support.RenderedChart{Metadata: chart.Metadata, Values: cvals, Manifests: map[string]string{}}
+		for name, content := range renderedContentMap {
+			if strings.TrimSpace(content) != "" {
+				rendered.Manifests[strings.TrimPrefix(name, chart.Name()+"/")] = content
+			}
+		}
+		for _, c := range opts.Checkers {
+			linter.RunChecker(c, rendered)
+		}
+	}
+
+	if opts.BestPractices {
+		pack := &support.Config{Rules: map[string]support.RuleConfig{}}
+		for _, r := range BestPractices() {
+			pack.Rules[r.ID] = support.RuleConfig{Enabled: &opts.BestPractices}
+		}
+		linter.Config = pack.Merge(linter.Config)
+	}
+	bestPractices := false
+	for _, r := range BestPractices() {
+		bestPractices = bestPractices || linter.RuleEnabled(r)
+	}
+	var objects []renderedObject
+	manifestsOk := true
+
+	deprecatedAPIs, kubeVersion := opts.DeprecatedAPIs, chartutil.DefaultCapabilities.KubeVersion
+	if deprecatedAPIs == nil {
+		deprecatedAPIs = chartutil.DefaultDeprecatedAPIs
+	}
+	if caps != nil {
+		kubeVersion = caps.KubeVersion
+	}
 
 	/* Iterate over all the templates to check:
 	- It is a .yaml file
@@ -101,11 +230,11 @@ func Templates(linter *support.Linter, values map[string]interface{}, namespace
 		fileName, data := template.Name, template.Data
 		path = fileName
 
-		linter.RunLinterRule(support.ErrorSev, path, validateAllowedExtension(fileName))
+		linter.RunRule(TemplateExtension, path, validateAllowedExtension(fileName))
 		// These are v3 specific checks to make sure and warn people if their
 		// chart is not compatible with v3
-		linter.RunLinterRule(support.WarningSev, path, validateNoCRDHooks(data))
-		linter.RunLinterRule(support.ErrorSev, path, validateNoReleaseTime(data))
+		linter.RunRule(CRDHooks, path, validateNoCRDHooks(data))
+		linter.RunRule(ReleaseTime, path, validateNoReleaseTime(data))
 
 		// We only apply the following lint rules to yaml files
 		if filepath.Ext(fileName) != ".yaml" || filepath.Ext(fileName) == ".yml" {
@@ -120,6 +249,13 @@ func Templates(linter *support.Linter, values map[string]interface{}, namespace
 		// linter.RunLinterRule(support.WarningSev, path, validateQuotes(string(preExecutedTemplate)))
 
 		renderedContent := renderedContentMap[filepath.Join(chart.Name(), fileName)]
+
+		// The outputs of a chart are structured data, not a Kubernetes object.
+		if fileName == outputsFileName {
+			linter.RunRule(Outputs, path, validateOutputs(renderedContent))
+			continue
+		}
+
 		if strings.TrimSpace(renderedContent) != "" {
 			var yamlStruct K8sYamlStruct
 			// Even though K8sYamlStruct only defines a few fields, an error in any other
@@ -128,9 +264,28 @@ func Templates(linter *support.Linter, values map[string]interface{}, namespace
 
 			// If YAML linting fails, we sill progress. So we don't capture the returned state
 			// on this linter run.
-			linter.RunLinterRule(support.ErrorSev, path, validateYamlContent(err))
-			linter.RunLinterRule(support.ErrorSev, path, validateMetadataName(&yamlStruct))
-			linter.RunLinterRule(support.ErrorSev, path, validateNoDeprecations(&yamlStruct))
+			manifestsOk = linter.RunRule(ManifestFormat, path, validateYamlContent(err)) && manifestsOk
+			linter.RunRule(MetadataName, path, validateMetadataName(&yamlStruct))
+			linter.RunRule(HookAnnotations, path, validateHookAnnotations(renderedContent))
+			var de deprecatedAPIError
+			if err := validateAPIDeprecation(&yamlStruct, deprecatedAPIs, kubeVersion); errors.As(err, &de) && de.Removed {
+				linter.RunRule(RemovedAPIs, path, err)
+			} else {
+				linter.RunRule(DeprecatedAPIs, path, err)
+			}
+
+			if bestPractices {
+				objects = append(objects, splitObjects(path, renderedContent)...)
+			}
+		}
+	}
+	validateBestPractices(linter, objects)
+
+	// The templates which are not valid YAML are already reported, and fail
+	// sorting the objects of the release the same way.
+	if manifestsOk && linter.RunRule(InstallRender, "templates/", installErr) {
+		for _, d := range releaseutil.FindDuplicateObjects(rel.Hooks, rel.Manifests) {
+			linter.RunRule(DuplicateResources, strings.TrimPrefix(d.Duplicate, chart.Name()+"/"), d)
 		}
 	}
 }
@@ -162,6 +317,14 @@ func validateYamlContent(err error) error {
 	return errors.Wrap(err, "unable to parse YAML")
 }
 
+func validateOutputs(content string) error {
+	var outputs map[string]interface{}
+	if err := yaml.Unmarshal([]byte(content), &outputs); err != nil {
+		return errors.Wrap(err, "outputs must be a YAML map")
+	}
+	return nil
+}
+
 func validateMetadataName(obj *K8sYamlStruct) error {
 	// This will return an error if the characters do not abide by the standard OR if the
 	// name is left empty.
@@ -134,6 +134,20 @@ func TestValidateMetadataName(t *testing.T) {
 	}
 }
 
+func TestValidateOutputs(t *testing.T) {
+	outputs := map[string]bool{
+		"":                    true,
+		"host: db\nport: 1":   true,
+		"- a\n- b":            false,
+		"host: [unterminated": false,
+	}
+	for input, expectPass := range outputs {
+		if err := validateOutputs(input); (err == nil) != expectPass {
+			t.Errorf("Expected outputs %q to pass: %t, got %v", input, expectPass, err)
+		}
+	}
+}
+
 func TestDeprecatedAPIFails(t *testing.T) {
 	mychart := chart.Chart{
 		Metadata: &chart.Metadata{
@@ -173,6 +187,9 @@ func TestDeprecatedAPIFails(t *testing.T) {
 	if err.Deprecated != "apps/v1beta1 Deployment" {
 		t.Errorf("Surprised to learn that %q is deprecated", err.Deprecated)
 	}
+	if id := linter.Messages[0].RuleID; id != RemovedAPIs.ID {
+		t.Errorf("Expected an API removed by the default Kubernetes version to be reported by %s, got %s", RemovedAPIs.ID, id)
+	}
 }
 
 const manifest = `apiVersion: v1
@@ -17,12 +17,15 @@ limitations under the License.
 package rules
 
 import (
+	"fmt"
 	"io/ioutil"
 	"os"
 	"path/filepath"
 
 	"github.com/pkg/errors"
 
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chart/loader"
 	"helm.sh/helm/v3/pkg/chartutil"
 	"helm.sh/helm/v3/pkg/lint/support"
 )
@@ -41,15 +44,36 @@ func Values(linter *support.Linter) {
 //
 // If additional values are supplied, they are coalesced into the values in values.yaml.
 func ValuesWithOverrides(linter *support.Linter, values map[string]interface{}) {
+	chrt, _ := loader.Load(linter.ChartDir)
+	ValuesWithChart(linter, chrt, values)
+}
+
+// ValuesWithChart tests the values.yaml file like ValuesWithOverrides, where
+// chrt is the chart loaded from the directory of the linter, if it could be.
+// References of the schema to other files are resolved within chrt.
+func ValuesWithChart(linter *support.Linter, chrt *chart.Chart, values map[string]interface{}) {
 	file := "values.yaml"
 	vf := filepath.Join(linter.ChartDir, file)
-	fileExists := linter.RunLinterRule(support.InfoSev, file, validateValuesFileExistence(vf))
+	fileExists := linter.RunRule(ValuesFileExists, file, validateValuesFileExistence(vf))
 
 	if !fileExists {
 		return
 	}
 
-	linter.RunLinterRule(support.ErrorSev, file, validateValuesFile(vf, values))
+	err := validateValuesFile(vf, chrt, values)
+	if errs, ok := err.(chartutil.SchemaErrors); ok {
+		// Report each violation of the schema on the line of the value.
+		data, _ := ioutil.ReadFile(vf)
+		for _, e := range errs {
+			path := file
+			if line := chartutil.ValuesFileLine(data, e.Keys); line > 0 {
+				path = fmt.Sprintf("%s:%d", file, line)
+			}
+			linter.RunRule(ValuesSchema, path, errors.Errorf("%s: %s", e.Path, e.Message))
+		}
+		return
+	}
+	linter.RunRule(ValuesFileFormat, file, err)
 }
 
 func validateValuesFileExistence(valuesPath string) error {
@@ -60,7 +84,7 @@ func validateValuesFileExistence(valuesPath string) error {
 	return nil
 }
 
-func validateValuesFile(valuesPath string, overrides map[string]interface{}) error {
+func validateValuesFile(valuesPath string, chrt *chart.Chart, overrides map[string]interface{}) error {
 	values, err := chartutil.ReadValuesFile(valuesPath)
 	if err != nil {
 		return errors.Wrap(err, "unable to parse YAML")
@@ -82,5 +106,11 @@ func validateValuesFile(valuesPath string, overrides map[string]interface{}) err
 	if err != nil {
 		return err
 	}
+
+	// References of the schema to other files can only be resolved within the
+	// chart.
+	if chrt != nil && chrt.Schema != nil {
+		return chartutil.ValidateAgainstChartSchema(chrt, values)
+	}
 	return chartutil.ValidateAgainstSingleSchema(values, schema)
 }
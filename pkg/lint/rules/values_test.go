@@ -25,6 +25,8 @@ import (
 	"github.com/stretchr/testify/assert"
 
 	"helm.sh/helm/v3/internal/test/ensure"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/lint/support"
 )
 
 var nonExistingValuesFilePath = filepath.Join("/fake/dir", "values.yaml")
@@ -69,7 +71,7 @@ func TestValidateValuesFileWellFormed(t *testing.T) {
 	tmpdir := ensure.TempFile(t, "values.yaml", []byte(badYaml))
 	defer os.RemoveAll(tmpdir)
 	valfile := filepath.Join(tmpdir, "values.yaml")
-	if err := validateValuesFile(valfile, map[string]interface{}{}); err == nil {
+	if err := validateValuesFile(valfile, nil, map[string]interface{}{}); err == nil {
 		t.Fatal("expected values file to fail parsing")
 	}
 }
@@ -81,7 +83,7 @@ func TestValidateValuesFileSchema(t *testing.T) {
 	createTestingSchema(t, tmpdir)
 
 	valfile := filepath.Join(tmpdir, "values.yaml")
-	if err := validateValuesFile(valfile, map[string]interface{}{}); err != nil {
+	if err := validateValuesFile(valfile, nil, map[string]interface{}{}); err != nil {
 		t.Fatalf("Failed validation with %s", err)
 	}
 }
@@ -95,7 +97,7 @@ func TestValidateValuesFileSchemaFailure(t *testing.T) {
 
 	valfile := filepath.Join(tmpdir, "values.yaml")
 
-	err := validateValuesFile(valfile, map[string]interface{}{})
+	err := validateValuesFile(valfile, nil, map[string]interface{}{})
 	if err == nil {
 		t.Fatal("expected values file to fail parsing")
 	}
@@ -113,7 +115,7 @@ func TestValidateValuesFileSchemaOverrides(t *testing.T) {
 	createTestingSchema(t, tmpdir)
 
 	valfile := filepath.Join(tmpdir, "values.yaml")
-	if err := validateValuesFile(valfile, overrides); err != nil {
+	if err := validateValuesFile(valfile, nil, overrides); err != nil {
 		t.Fatalf("Failed validation with %s", err)
 	}
 }
@@ -126,3 +128,48 @@ func createTestingSchema(t *testing.T, dir string) string {
 	}
 	return schemafile
 }
+
+func TestValuesWithOverridesSchemaLocations(t *testing.T) {
+	yaml := "username: admin\n\npassword:\n  - swordfish\n"
+	tmpdir := ensure.TempFile(t, "values.yaml", []byte(yaml))
+	defer os.RemoveAll(tmpdir)
+	createTestingSchema(t, tmpdir)
+
+	linter := support.Linter{ChartDir: tmpdir}
+	ValuesWithOverrides(&linter, map[string]interface{}{})
+
+	if len(linter.Messages) != 1 {
+		t.Fatalf("Expected one message, got %v", linter.Messages)
+	}
+	msg := linter.Messages[0]
+	assert.Equal(t, "values.yaml:3", msg.Path)
+	assert.Contains(t, msg.Err.Error(), "$.password: Invalid type")
+}
+
+func TestValuesWithChartSchemaRefs(t *testing.T) {
+	tmpdir := ensure.TempFile(t, "values.yaml", []byte("port: http\n"))
+	defer os.RemoveAll(tmpdir)
+	files := map[string]string{
+		"Chart.yaml":         "apiVersion: v2\nname: refs\nversion: 0.1.0\n",
+		"values.schema.json": `{"properties": {"port": {"$ref": "defs.json#/port"}}}`,
+		"defs.json":          `{"port": {"type": "integer"}}`,
+	}
+	for name, data := range files {
+		if err := ioutil.WriteFile(filepath.Join(tmpdir, name), []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	chrt, err := loader.Load(tmpdir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	linter := support.Linter{ChartDir: tmpdir}
+	ValuesWithChart(&linter, chrt, map[string]interface{}{})
+
+	if len(linter.Messages) != 1 {
+		t.Fatalf("Expected one message, got %v", linter.Messages)
+	}
+	assert.Equal(t, "values.yaml:1", linter.Messages[0].Path)
+	assert.Contains(t, linter.Messages[0].Err.Error(), "$.port: Invalid type")
+}
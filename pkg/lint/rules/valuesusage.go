@@ -0,0 +1,277 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rules
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/engine"
+	"helm.sh/helm/v3/pkg/lint/support"
+)
+
+// valueRead is a read of a value by the templates, with the path of the
+// value below .Values.
+type valueRead struct {
+	path  []string
+	whole bool
+}
+
+// valuesUsage is how the templates of a chart and of its subcharts read its
+// values.
+type valuesUsage struct {
+	chart *chart.Chart
+	// dir is the directory of the chart in the linted chart, e.g.
+	// "charts/sub/", or "" for the linted chart.
+	dir       string
+	parent    *valuesUsage
+	subcharts []*valuesUsage
+	// refs are the references of the templates of the chart itself.
+	refs []engine.ValueReference
+	// reads are the reads of the values of the chart by its templates and
+	// those of its subcharts.
+	reads []valueRead
+	// values are those of the values.yaml file of the chart, as coalescing
+	// the values of a chart changes those it was loaded with.
+	values map[string]interface{}
+	schema map[string]interface{}
+}
+
+// validateValuesUsage reports, for a chart and each of its subcharts, the
+// values of values.yaml which the templates do not read, and the values read
+// by the templates which neither values.yaml nor values.schema.json define.
+func validateValuesUsage(linter *support.Linter, c *chart.Chart, refs []engine.ValueReference) {
+	root := newValuesUsage(c, "", nil, refs)
+	// Global values are shared by the charts, and so read by any of them.
+	var globals []valueRead
+	root.walk(func(u *valuesUsage) {
+		for _, r := range u.reads {
+			if len(r.path) > 0 && r.path[0] == chartutil.GlobalKey {
+				globals = append(globals, r)
+			}
+		}
+	})
+
+	root.walk(func(u *valuesUsage) {
+		for _, p := range u.unused(globals) {
+			linter.RunRule(UnusedValues, u.dir+chartutil.ValuesfileName,
+				errors.Errorf("value .Values.%s is not read by the templates", strings.Join(p, ".")))
+		}
+		reported := map[string]bool{}
+		for _, r := range u.refs {
+			p := strings.Split(strings.TrimPrefix(r.Path, "."), ".")
+			if len(p) < 2 || reported[r.Path] || u.defined(p[1:]) {
+				continue
+			}
+			reported[r.Path] = true
+			linter.RunRule(UndocumentedValues, fmt.Sprintf("%s:%d", strings.TrimPrefix(r.Template, c.Name()+"/"), r.Line),
+				errors.Errorf("value %s is not defined in %s or %s", r.Path, u.dir+chartutil.ValuesfileName, u.dir+chartutil.SchemafileName))
+		}
+	})
+}
+
+// newValuesUsage returns the usage of the values of a chart, given the
+// references of the templates of the linted chart.
+func newValuesUsage(c *chart.Chart, dir string, parent *valuesUsage, refs []engine.ValueReference) *valuesUsage {
+	u := &valuesUsage{chart: c, dir: dir, parent: parent}
+	for _, f := range c.Raw {
+		if f.Name == chartutil.ValuesfileName {
+			// Invalid values are reported by the ValuesFormat rule.
+			u.values, _ = chartutil.ReadValues(f.Data)
+		}
+	}
+	if len(c.Schema) > 0 {
+		// An invalid schema is reported by the ValuesSchema rule.
+		json.Unmarshal(c.Schema, &u.schema)
+	}
+
+	prefix := c.ChartFullPath() + "/templates/"
+	for _, r := range refs {
+		if strings.HasPrefix(r.Template, prefix) {
+			u.refs = append(u.refs, r)
+			u.reads = append(u.reads, readOf(r))
+		}
+	}
+
+	// Helm itself reads the conditions and tags of the dependencies.
+	if c.Metadata != nil {
+		for _, d := range c.Metadata.Dependencies {
+			for _, cond := range strings.Split(d.Condition, ",") {
+				if cond = strings.TrimSpace(cond); cond != "" {
+					u.reads = append(u.reads, valueRead{path: strings.Split(cond, ".")})
+				}
+			}
+			if len(d.Tags) > 0 {
+				u.reads = append(u.reads, valueRead{path: []string{"tags"}, whole: true})
+			}
+		}
+	}
+
+	// The values of a subchart are those of its parent under its name.
+	for _, dep := range c.Dependencies() {
+		sub := newValuesUsage(dep, path.Join(dir, "charts", dep.Name())+"/", u, refs)
+		u.subcharts = append(u.subcharts, sub)
+		for _, r := range sub.reads {
+			u.reads = append(u.reads, valueRead{path: append([]string{dep.Name()}, r.path...), whole: r.whole})
+		}
+	}
+	return u
+}
+
+// readOf returns the read of a reference. Reading the render values as a
+// whole reads all the values.
+func readOf(r engine.ValueReference) valueRead {
+	p := strings.Split(strings.TrimPrefix(r.Path, "."), ".")
+	if p[0] == "" {
+		return valueRead{whole: true}
+	}
+	return valueRead{path: p[1:], whole: r.Whole}
+}
+
+// walk calls fn with the usage of the chart and of each of its subcharts.
+func (u *valuesUsage) walk(fn func(*valuesUsage)) {
+	fn(u)
+	for _, sub := range u.subcharts {
+		sub.walk(fn)
+	}
+}
+
+// unused returns the paths of the values of values.yaml which are not read,
+// without the paths below them.
+func (u *valuesUsage) unused(globals []valueRead) [][]string {
+	reads := append(append([]valueRead{}, u.reads...), globals...)
+	var unused [][]string
+	var visit func(p []string, v interface{})
+	visit = func(p []string, v interface{}) {
+		if len(p) > 0 {
+			touched := false
+			for _, r := range reads {
+				if r.whole && hasPrefix(p, r.path) {
+					return
+				}
+				touched = touched || hasPrefix(r.path, p)
+			}
+			if !touched {
+				unused = append(unused, p)
+				return
+			}
+		}
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return
+		}
+		keys := make([]string, 0, len(m))
+		for k := range m {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			visit(append(append([]string{}, p...), k), m[k])
+		}
+	}
+	visit(nil, u.values)
+	return unused
+}
+
+// defined returns whether a value is defined by the values.yaml file or the
+// schema of the chart, or by those of its parents, which set the values of
+// their subcharts.
+func (u *valuesUsage) defined(p []string) bool {
+	if definedInValues(u.values, p) || definedInSchema(u.schema, p) {
+		return true
+	}
+	if u.parent == nil {
+		return false
+	}
+	if p[0] == chartutil.GlobalKey {
+		return u.parent.defined(p)
+	}
+	return u.parent.defined(append([]string{u.chart.Name()}, p...))
+}
+
+// definedInValues returns whether a value is defined by values. The values
+// below a value which is not a map, such as null, are assumed to be.
+func definedInValues(values map[string]interface{}, p []string) bool {
+	var cur interface{} = values
+	for _, k := range p {
+		m, ok := cur.(map[string]interface{})
+		if !ok {
+			return true
+		}
+		if cur, ok = m[k]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// definedInSchema returns whether a value is defined by the properties of a
+// JSON schema. Objects with additional or pattern properties define any key,
+// and the values below a schema which is not an object are assumed to be.
+func definedInSchema(schema map[string]interface{}, p []string) bool {
+	if schema == nil {
+		return false
+	}
+	cur := schema
+	for _, k := range p {
+		if _, ok := cur["$ref"]; ok {
+			return true
+		}
+		if t, ok := cur["type"].(string); ok && t != "object" {
+			return true
+		}
+		if props, ok := cur["properties"].(map[string]interface{}); ok {
+			if next, ok := props[k].(map[string]interface{}); ok {
+				cur = next
+				continue
+			}
+		}
+		switch additional := cur["additionalProperties"].(type) {
+		case map[string]interface{}:
+			cur = additional
+			continue
+		case bool:
+			if additional {
+				return true
+			}
+		}
+		if _, ok := cur["patternProperties"]; ok {
+			return true
+		}
+		return false
+	}
+	return true
+}
+
+func hasPrefix(p, prefix []string) bool {
+	if len(prefix) > len(p) {
+		return false
+	}
+	for i := range prefix {
+		if p[i] != prefix[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,133 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rules
+
+import (
+	"encoding/json"
+	"testing"
+
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/engine"
+	"helm.sh/helm/v3/pkg/lint/support"
+)
+
+const valuesUsageValues = `name: web
+image:
+  repository: nginx
+  tag: stable
+resources: {}
+ingress:
+  enabled: false
+  hosts: []
+global:
+  domain: example.com
+db:
+  enabled: true
+  user: admin
+`
+
+func TestValidateValuesUsage(t *testing.T) {
+	db := &chart.Chart{
+		Metadata: &chart.Metadata{Name: "db"},
+		Raw:      []*chart.File{{Name: "values.yaml", Data: []byte("user: root\nport: 5432\n")}},
+	}
+	c := &chart.Chart{
+		Metadata: &chart.Metadata{
+			Name:         "web",
+			Dependencies: []*chart.Dependency{{Name: "db", Condition: "db.enabled"}},
+		},
+		Raw:    []*chart.File{{Name: "values.yaml", Data: []byte(valuesUsageValues)}},
+		Schema: []byte(`{"properties": {"replicas": {"type": "integer"}, "labels": {"type": "object", "additionalProperties": {"type": "string"}}}}`),
+	}
+	c.AddDependency(db)
+
+	refs := []engine.ValueReference{
+		{Template: "web/templates/deployment.yaml", Line: 3, Path: ".Values.image.repository", Whole: true},
+		{Template: "web/templates/deployment.yaml", Line: 4, Path: ".Values.resources", Whole: true},
+		{Template: "web/templates/deployment.yaml", Line: 5, Path: ".Values.replicas", Whole: true},
+		{Template: "web/templates/deployment.yaml", Line: 6, Path: ".Values.labels.team", Whole: true},
+		{Template: "web/templates/deployment.yaml", Line: 7, Path: ".Values.ingress"},
+		{Template: "web/templates/deployment.yaml", Line: 8, Path: ".Values.affinity", Whole: true},
+		{Template: "web/templates/deployment.yaml", Line: 9, Path: ".Values.affinity", Whole: true},
+		{Template: "web/charts/db/templates/secret.yaml", Line: 2, Path: ".Values.user", Whole: true},
+		{Template: "web/charts/db/templates/secret.yaml", Line: 3, Path: ".Values.global.domain", Whole: true},
+		{Template: "web/charts/db/templates/secret.yaml", Line: 4, Path: ".Values.password", Whole: true},
+	}
+
+	enabled := true
+	linter := support.Linter{Config: &support.Config{Rules: map[string]support.RuleConfig{
+		UnusedValues.ID:       {Enabled: &enabled},
+		UndocumentedValues.ID: {Enabled: &enabled},
+	}}}
+	validateValuesUsage(&linter, c, refs)
+
+	expect := []string{
+		"values.yaml: value .Values.image.tag is not read by the templates",
+		"values.yaml: value .Values.ingress.enabled is not read by the templates",
+		"values.yaml: value .Values.ingress.hosts is not read by the templates",
+		"values.yaml: value .Values.name is not read by the templates",
+		"templates/deployment.yaml:8: value .Values.affinity is not defined in values.yaml or values.schema.json",
+		"charts/db/values.yaml: value .Values.port is not read by the templates",
+		"charts/db/templates/secret.yaml:4: value .Values.password is not defined in charts/db/values.yaml or charts/db/values.schema.json",
+	}
+	if len(linter.Messages) != len(expect) {
+		t.Errorf("expected %d messages, got %d", len(expect), len(linter.Messages))
+	}
+	for i, msg := range linter.Messages {
+		if i < len(expect) && msg.Path+": "+msg.Err.Error() != expect[i] {
+			t.Errorf("expected message %d %q, got %q", i, expect[i], msg.Path+": "+msg.Err.Error())
+		}
+	}
+}
+
+func TestDefinedInSchema(t *testing.T) {
+	var schema map[string]interface{}
+	err := json.Unmarshal([]byte(`{
+		"properties": {
+			"image": {"type": "object", "properties": {"tag": {"type": "string"}}},
+			"labels": {"type": "object", "additionalProperties": {"type": "string"}},
+			"env": {"type": "object", "patternProperties": {"^[A-Z_]+$": {"type": "string"}}},
+			"ports": {"$ref": "#/definitions/ports"},
+			"extra": {"type": "object", "additionalProperties": false}
+		}
+	}`), &schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		path    []string
+		defined bool
+	}{
+		{[]string{"image"}, true},
+		{[]string{"image", "tag"}, true},
+		{[]string{"image", "tag", "suffix"}, true},
+		{[]string{"image", "digest"}, false},
+		{[]string{"labels", "team"}, true},
+		{[]string{"env", "DEBUG"}, true},
+		{[]string{"ports", "http"}, true},
+		{[]string{"extra", "key"}, false},
+		{[]string{"missing"}, false},
+	}
+	for _, tt := range tests {
+		if got := definedInSchema(schema, tt.path); got != tt.defined {
+			t.Errorf("definedInSchema(%v) = %t, expected %t", tt.path, got, tt.defined)
+		}
+	}
+	if definedInSchema(nil, []string{"image"}) {
+		t.Error("expected no value to be defined without a schema")
+	}
+}
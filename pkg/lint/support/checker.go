@@ -0,0 +1,77 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package support
+
+import (
+	"github.com/pkg/errors"
+
+	"helm.sh/helm/v3/pkg/chart"
+)
+
+// Checker lints charts with rules Helm does not ship, such as the policies
+// of an organization. Its messages are configured, suppressed and reported
+// like those of the rules of Helm.
+type Checker interface {
+	// Rules returns the rules of the checker. Their IDs must not be those of
+	// other rules.
+	Rules() []Rule
+	// Check returns the messages of the rules of the checker about a chart.
+	// Messages without a severity have that of their rule.
+	Check(c *RenderedChart) ([]Message, error)
+}
+
+// RenderedChart is a chart rendered for linting, as given to a Checker.
+type RenderedChart struct {
+	Metadata *chart.Metadata `json:"metadata"`
+	// Values are the values the chart is rendered with.
+	Values map[string]interface{} `json:"values"`
+	// Manifests are the rendered templates of the chart and of its
+	// subcharts, by path in the chart, e.g. "templates/deployment.yaml" or
+	// "charts/db/templates/secret.yaml".
+	Manifests map[string]string `json:"manifests"`
+}
+
+// RunChecker records the messages of a checker about a chart like those of
+// RunRule. The checker is not run if all its rules are disabled.
+func (l *Linter) RunChecker(checker Checker, c *RenderedChart) {
+	rules := map[string]Rule{}
+	enabled := false
+	for _, r := range checker.Rules() {
+		rules[r.ID] = r
+		enabled = enabled || l.RuleEnabled(r)
+	}
+	if !enabled {
+		return
+	}
+
+	messages, err := checker.Check(c)
+	if err != nil {
+		l.RunLinterRule(ErrorSev, "templates/", err)
+		return
+	}
+	for _, m := range messages {
+		rule, ok := rules[m.RuleID]
+		if !ok {
+			l.RunLinterRule(ErrorSev, m.Path, errors.Errorf("message of unknown lint rule %q: %v", m.RuleID, m.Err))
+			continue
+		}
+		if m.Severity != UnknownSev {
+			rule.Severity = m.Severity
+		}
+		l.RunRule(rule, m.Path, m.Err)
+	}
+}
@@ -0,0 +1,83 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package support
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type testChecker struct {
+	messages []Message
+	err      error
+	checked  bool
+}
+
+func (c *testChecker) Rules() []Rule {
+	return []Rule{testRule, disabledRule}
+}
+
+func (c *testChecker) Check(*RenderedChart) ([]Message, error) {
+	c.checked = true
+	return c.messages, c.err
+}
+
+func TestRunChecker(t *testing.T) {
+	checker := &testChecker{messages: []Message{
+		{Path: "templates/a.yaml", Err: errors.New("default severity"), RuleID: testRule.ID},
+		{Severity: InfoSev, Path: "templates/b.yaml", Err: errors.New("own severity"), RuleID: testRule.ID},
+		{Path: "templates/c.yaml", Err: errors.New("disabled"), RuleID: disabledRule.ID},
+		{Path: "templates/d.yaml", Err: errors.New("unknown"), RuleID: "HL999"},
+	}}
+	linter := Linter{}
+	linter.RunChecker(checker, &RenderedChart{})
+
+	expect := []Message{
+		{Severity: WarningSev, Path: "templates/a.yaml", Err: checker.messages[0].Err, RuleID: testRule.ID},
+		{Severity: InfoSev, Path: "templates/b.yaml", Err: checker.messages[1].Err, RuleID: testRule.ID},
+	}
+	if !reflect.DeepEqual(linter.Messages[:2], expect) {
+		t.Errorf("expected %v, got %v", expect, linter.Messages)
+	}
+	if len(linter.Messages) != 3 || linter.Messages[2].Error() != `[ERROR] templates/d.yaml: message of unknown lint rule "HL999": unknown` {
+		t.Errorf("expected an error about the unknown rule, got %v", linter.Messages)
+	}
+
+	// The configured severity replaces that of the messages.
+	config, _ := ParseConfig([]byte("rules:\n  test-rule:\n    severity: error\n"))
+	linter = Linter{Config: config}
+	linter.RunChecker(checker, &RenderedChart{})
+	if linter.Messages[1].Severity != ErrorSev || linter.HighestSeverity != ErrorSev {
+		t.Errorf("expected the configured severity, got %v", linter.Messages)
+	}
+
+	config, _ = ParseConfig([]byte("rules:\n  test-rule:\n    enabled: false\n"))
+	checker = &testChecker{}
+	linter = Linter{Config: config}
+	linter.RunChecker(checker, &RenderedChart{})
+	if checker.checked {
+		t.Error("expected a checker whose rules are disabled not to be run")
+	}
+
+	checker = &testChecker{err: errors.New("policy unavailable")}
+	linter = Linter{}
+	linter.RunChecker(checker, &RenderedChart{})
+	if len(linter.Messages) != 1 || linter.Messages[0].Error() != "[ERROR] templates/: policy unavailable" {
+		t.Errorf("expected the error of the checker, got %v", linter.Messages)
+	}
+}
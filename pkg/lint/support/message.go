@@ -16,7 +16,13 @@ limitations under the License.
 
 package support
 
-import "fmt"
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
 
 // Severity indicates the severity of a Message.
 const (
@@ -39,6 +45,11 @@ type Linter struct {
 	// The highest severity of all the failing lint rules
 	HighestSeverity int
 	ChartDir        string
+	// Config configures the rules run with RunRule.
+	Config *Config
+
+	// suppressed caches the rules disabled by the comments of the files.
+	suppressed map[string]map[string]bool
 }
 
 // Message describes an error encountered while linting.
@@ -47,10 +58,49 @@ type Message struct {
 	Severity int
 	Path     string
 	Err      error
+	// RuleID is the ID of the rule of the message, if any.
+	RuleID string
+	// Contexts are the configurations the chart was linted with which
+	// produced the message, e.g. "values ci/ingress-values.yaml, Kubernetes
+	// v1.16.0", when linting a chart with several configurations.
+	Contexts []string
 }
 
 func (m Message) Error() string {
-	return fmt.Sprintf("[%s] %s: %s", sev[m.Severity], m.Path, m.Err.Error())
+	msg := fmt.Sprintf("[%s] %s: %s", sev[m.Severity], m.Path, m.Err.Error())
+	if m.RuleID != "" {
+		msg = fmt.Sprintf("[%s] %s %s: %s", sev[m.Severity], m.RuleID, m.Path, m.Err.Error())
+	}
+	if len(m.Contexts) > 0 {
+		msg += fmt.Sprintf(" (in %s)", strings.Join(m.Contexts, "; "))
+	}
+	return msg
+}
+
+// templateLocation finds the location of a template in the errors of the
+// template engine, e.g. "mychart/templates/deployment.yaml:12:4".
+var templateLocation = regexp.MustCompile(`[^\s:()/]+/((?:charts/[^\s:()/]+/)*templates/[^\s:()]+):(\d+)(?::(\d+))?`)
+
+// Location returns the file of the chart the message is about, and the line
+// and column in the file where known, or zero.
+func (m Message) Location() (file string, line, column int) {
+	file = m.Path
+	if parts := strings.SplitN(m.Path, ":", 3); len(parts) > 1 {
+		file = parts[0]
+		line, _ = strconv.Atoi(parts[1])
+		if len(parts) > 2 {
+			column, _ = strconv.Atoi(parts[2])
+		}
+		return file, line, column
+	}
+	if strings.HasSuffix(file, "/") && m.Err != nil {
+		if loc := templateLocation.FindStringSubmatch(m.Err.Error()); loc != nil {
+			line, _ = strconv.Atoi(loc[2])
+			column, _ = strconv.Atoi(loc[3])
+			return loc[1], line, column
+		}
+	}
+	return file, 0, 0
 }
 
 // NewMessage creates a new Message struct
@@ -74,3 +124,8 @@ func (l *Linter) RunLinterRule(severity int, path string, err error) bool {
 	}
 	return err == nil
 }
+
+func sortedStrings(s []string) []string {
+	sort.Strings(s)
+	return s
+}
@@ -63,18 +63,39 @@ func TestRunLinterRule(t *testing.T) {
 }
 
 func TestMessage(t *testing.T) {
-	m := Message{ErrorSev, "Chart.yaml", errors.New("Foo")}
+	m := Message{Severity: ErrorSev, Path: "Chart.yaml", Err: errors.New("Foo")}
 	if m.Error() != "[ERROR] Chart.yaml: Foo" {
 		t.Errorf("Unexpected output: %s", m.Error())
 	}
 
-	m = Message{WarningSev, "templates/", errors.New("Bar")}
+	m = Message{Severity: WarningSev, Path: "templates/", Err: errors.New("Bar")}
 	if m.Error() != "[WARNING] templates/: Bar" {
 		t.Errorf("Unexpected output: %s", m.Error())
 	}
 
-	m = Message{InfoSev, "templates/rc.yaml", errors.New("FooBar")}
+	m = Message{Severity: InfoSev, Path: "templates/rc.yaml", Err: errors.New("FooBar")}
 	if m.Error() != "[INFO] templates/rc.yaml: FooBar" {
 		t.Errorf("Unexpected output: %s", m.Error())
 	}
 }
+
+func TestMessageLocation(t *testing.T) {
+	tests := []struct {
+		path, err    string
+		file         string
+		line, column int
+	}{
+		{"Chart.yaml", "icon is recommended", "Chart.yaml", 0, 0},
+		{"values.yaml:12", "replicas: Invalid type", "values.yaml", 12, 0},
+		{"templates/", `template: web/templates/deployment.yaml:12:4: executing "web/templates/deployment.yaml" at <.Values.image.tag>: nil pointer`, "templates/deployment.yaml", 12, 4},
+		{"templates/", `parse error at (web/charts/db/templates/secret.yaml:3): function "foo" not defined`, "charts/db/templates/secret.yaml", 3, 0},
+		{"templates/", "directory not found", "templates/", 0, 0},
+	}
+	for _, tt := range tests {
+		m := Message{Severity: ErrorSev, Path: tt.path, Err: errors.New(tt.err)}
+		file, line, column := m.Location()
+		if file != tt.file || line != tt.line || column != tt.column {
+			t.Errorf("Location of %q, %q: expected %s:%d:%d, got %s:%d:%d", tt.path, tt.err, tt.file, tt.line, tt.column, file, line, column)
+		}
+	}
+}
@@ -0,0 +1,263 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package support
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/yaml"
+)
+
+// ConfigFileName is the name of the lint configuration file of a chart.
+const ConfigFileName = ".helmlint.yaml"
+
+// Rule is a lint rule.
+type Rule struct {
+	// ID identifies the rule, e.g. "HL001". The ID of a rule never changes,
+	// and the IDs of removed rules are not reused.
+	ID string
+	// Name is a short name of the rule, e.g. "chart-name".
+	Name string
+	// Severity is the default severity of the messages of the rule.
+	Severity int
+	// Disabled rules are not run unless enabled by the configuration.
+	Disabled bool
+	// Description describes what the rule checks.
+	Description string
+}
+
+// Config configures the lint rules, e.g. from the .helmlint.yaml file of a
+// chart:
+//
+//	rules:
+//	  HL008:
+//	    severity: error
+//	  chart-sources:
+//	    enabled: false
+type Config struct {
+	// Rules configures rules by ID or name.
+	Rules map[string]RuleConfig `json:"rules,omitempty"`
+}
+
+// RuleConfig configures a lint rule.
+type RuleConfig struct {
+	// Enabled, if set, enables or disables the rule.
+	Enabled *bool `json:"enabled,omitempty"`
+	// Severity, if set, replaces the severity of the messages of the rule:
+	// one of "info", "warning" or "error".
+	Severity string `json:"severity,omitempty"`
+}
+
+// LoadConfig reads a lint configuration file. A missing file is no
+// configuration, and no error.
+func LoadConfig(filename string) (*Config, error) {
+	data, err := ioutil.ReadFile(filename)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return ParseConfig(data)
+}
+
+// ParseConfig parses a lint configuration.
+func ParseConfig(data []byte) (*Config, error) {
+	c := &Config{}
+	if err := yaml.UnmarshalStrict(data, c); err != nil {
+		return nil, errors.Wrap(err, "unable to parse lint configuration")
+	}
+	for key, rc := range c.Rules {
+		if rc.Severity != "" {
+			if _, err := ParseSeverity(rc.Severity); err != nil {
+				return nil, errors.Wrapf(err, "rule %s", key)
+			}
+		}
+	}
+	return c, nil
+}
+
+// Merge returns the configuration c overridden by the rules configured in
+// other.
+func (c *Config) Merge(other *Config) *Config {
+	merged := &Config{Rules: map[string]RuleConfig{}}
+	for _, cfg := range []*Config{c, other} {
+		if cfg == nil {
+			continue
+		}
+		for key, rc := range cfg.Rules {
+			m := merged.Rules[key]
+			if rc.Enabled != nil {
+				m.Enabled = rc.Enabled
+			}
+			if rc.Severity != "" {
+				m.Severity = rc.Severity
+			}
+			merged.Rules[key] = m
+		}
+	}
+	return merged
+}
+
+// Resolve returns the configuration with the rules configured by ID, so
+// that configurations naming the same rule differently merge. It returns an
+// error if the configuration configures rules which are not known.
+func (c *Config) Resolve(known []Rule) (*Config, error) {
+	if c == nil {
+		return nil, nil
+	}
+	ids := map[string]string{}
+	for _, r := range known {
+		ids[r.ID] = r.ID
+		ids[r.Name] = r.ID
+	}
+	byName := &Config{Rules: map[string]RuleConfig{}}
+	byID := &Config{Rules: map[string]RuleConfig{}}
+	var unknown []string
+	for key, rc := range c.Rules {
+		id, ok := ids[key]
+		switch {
+		case !ok:
+			unknown = append(unknown, key)
+		case key == id:
+			byID.Rules[id] = rc
+		default:
+			byName.Rules[id] = rc
+		}
+	}
+	if len(unknown) > 0 {
+		return nil, errors.Errorf("unknown lint rules: %s", strings.Join(sortedStrings(unknown), ", "))
+	}
+	return byName.Merge(byID), nil
+}
+
+// rule returns the configuration of a rule, by ID or else by name.
+func (c *Config) rule(r Rule) RuleConfig {
+	if c == nil {
+		return RuleConfig{}
+	}
+	if rc, ok := c.Rules[r.ID]; ok {
+		return rc
+	}
+	return c.Rules[r.Name]
+}
+
+// Enabled returns whether a rule is run with the configuration.
+func (c *Config) Enabled(r Rule) bool {
+	if rc := c.rule(r); rc.Enabled != nil {
+		return *rc.Enabled
+	}
+	return !r.Disabled
+}
+
+// Severity returns the severity of the messages of a rule with the
+// configuration.
+func (c *Config) Severity(r Rule) int {
+	if rc := c.rule(r); rc.Severity != "" {
+		if sev, err := ParseSeverity(rc.Severity); err == nil {
+			return sev
+		}
+	}
+	return r.Severity
+}
+
+// ParseSeverity parses the name of a severity, such as "warning".
+func ParseSeverity(name string) (int, error) {
+	for i, s := range sev {
+		if i != UnknownSev && strings.EqualFold(name, s) {
+			return i, nil
+		}
+	}
+	return UnknownSev, errors.Errorf("invalid severity %q, must be one of: info, warning, error", name)
+}
+
+// SeverityName returns the name of a severity, such as "WARNING".
+func SeverityName(severity int) string {
+	if severity < 0 || severity >= len(sev) {
+		return sev[UnknownSev]
+	}
+	return sev[severity]
+}
+
+// suppressionComment disables rules in a file, e.g.
+// {{/* helm-lint-disable HL012 HL013 */}} in a template, or
+// # helm-lint-disable HL008 in a YAML file. Without IDs, it disables all the
+// rules.
+var suppressionComment = regexp.MustCompile(`helm-lint-disable((?:[ \t]+[A-Za-z0-9-]+)*)`)
+
+// suppressions returns the IDs or names of the rules disabled in a file of
+// the chart, with "" standing for all the rules.
+func (l *Linter) suppressions(path string) map[string]bool {
+	// Messages on a line of a file, e.g. "values.yaml:12", belong to the file.
+	if i := strings.LastIndex(path, ":"); i > 0 {
+		path = path[:i]
+	}
+	if s, ok := l.suppressed[path]; ok {
+		return s
+	}
+	if l.suppressed == nil {
+		l.suppressed = map[string]map[string]bool{}
+	}
+
+	s := map[string]bool{}
+	if data, err := ioutil.ReadFile(filepath.Join(l.ChartDir, filepath.FromSlash(path))); err == nil {
+		for _, m := range suppressionComment.FindAllStringSubmatch(string(data), -1) {
+			ids := strings.Fields(m[1])
+			if len(ids) == 0 {
+				s[""] = true
+			}
+			for _, id := range ids {
+				s[id] = true
+			}
+		}
+	}
+	l.suppressed[path] = s
+	return s
+}
+
+// RuleEnabled returns whether a rule is run with the configuration of the
+// linter, e.g. to skip the work of disabled rules.
+func (l *Linter) RuleEnabled(rule Rule) bool {
+	return l.Config.Enabled(rule)
+}
+
+// RunRule records a message of the rule with the configured severity if err
+// is not nil, unless the rule is disabled by the configuration of the linter
+// or by a comment in the file at path. It returns true if the validation
+// passed.
+func (l *Linter) RunRule(rule Rule, path string, err error) bool {
+	if err == nil {
+		return true
+	}
+	if !l.Config.Enabled(rule) {
+		return false
+	}
+	if s := l.suppressions(path); s[""] || s[rule.ID] || s[rule.Name] {
+		return false
+	}
+
+	severity := l.Config.Severity(rule)
+	l.Messages = append(l.Messages, Message{Severity: severity, Path: path, Err: err, RuleID: rule.ID})
+	if severity > l.HighestSeverity {
+		l.HighestSeverity = severity
+	}
+	return false
+}
@@ -0,0 +1,127 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package support
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+var (
+	testRule     = Rule{ID: "HL100", Name: "test-rule", Severity: WarningSev}
+	disabledRule = Rule{ID: "HL101", Name: "disabled-rule", Severity: ErrorSev, Disabled: true}
+)
+
+func TestParseConfig(t *testing.T) {
+	c, err := ParseConfig([]byte("rules:\n  HL100:\n    severity: error\n  disabled-rule:\n    enabled: true\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !c.Enabled(disabledRule) || c.Severity(disabledRule) != ErrorSev {
+		t.Errorf("expected %s to be enabled with severity ERROR", disabledRule.ID)
+	}
+	if !c.Enabled(testRule) || c.Severity(testRule) != ErrorSev {
+		t.Errorf("expected %s to be enabled with severity ERROR", testRule.ID)
+	}
+	resolved, err := c.Resolve([]Rule{testRule, disabledRule})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := resolved.Rules["HL101"]; !ok || len(resolved.Rules) != 2 {
+		t.Errorf("expected the rules to be configured by ID, got %v", resolved.Rules)
+	}
+	if _, err := c.Resolve([]Rule{testRule}); err == nil || err.Error() != "unknown lint rules: disabled-rule" {
+		t.Errorf("expected an unknown rule error, got %v", err)
+	}
+
+	for _, data := range []string{
+		"rules:\n  HL100:\n    severity: fatal\n",
+		"rules:\n  HL100:\n    disabled: true\n",
+	} {
+		if _, err := ParseConfig([]byte(data)); err == nil {
+			t.Errorf("expected an error parsing %q", data)
+		}
+	}
+}
+
+func TestConfigMerge(t *testing.T) {
+	enabled, disabled := true, false
+	c := &Config{Rules: map[string]RuleConfig{"HL100": {Enabled: &disabled, Severity: "info"}}}
+	merged := c.Merge(&Config{Rules: map[string]RuleConfig{"HL100": {Enabled: &enabled}}})
+	if !merged.Enabled(testRule) || merged.Severity(testRule) != InfoSev {
+		t.Errorf("expected %s to be enabled with severity INFO, got %v", testRule.ID, merged.Rules)
+	}
+
+	var none *Config
+	if !none.Enabled(testRule) || none.Enabled(disabledRule) || none.Severity(testRule) != WarningSev {
+		t.Error("expected the defaults of the rules without a configuration")
+	}
+	if c, err := LoadConfig(filepath.Join("testdata", "missing.yaml")); c != nil || err != nil {
+		t.Errorf("expected no configuration and no error, got %v, %v", c, err)
+	}
+}
+
+func TestRunRule(t *testing.T) {
+	dir, err := ioutil.TempDir("", "helm-lint-support")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	files := map[string]string{
+		"one.yaml": "{{/* helm-lint-disable HL100 */}}\n",
+		"two.yaml": "# helm-lint-disable\n",
+		"all.yaml": "kind: ConfigMap\n",
+	}
+	for name, data := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	l := Linter{ChartDir: dir, Config: &Config{Rules: map[string]RuleConfig{"test-rule": {Severity: "info"}}}}
+	for _, path := range []string{"one.yaml", "two.yaml:3", "missing.yaml", "all.yaml"} {
+		if l.RunRule(testRule, path, errLint) {
+			t.Errorf("expected %s to fail", path)
+		}
+	}
+	if !l.RunRule(testRule, "all.yaml", nil) {
+		t.Error("expected the rule to pass without an error")
+	}
+	l.RunRule(disabledRule, "all.yaml", errLint)
+
+	expect := []Message{
+		{Severity: InfoSev, Path: "missing.yaml", Err: errLint, RuleID: "HL100"},
+		{Severity: InfoSev, Path: "all.yaml", Err: errLint, RuleID: "HL100"},
+	}
+	if len(l.Messages) != len(expect) {
+		t.Fatalf("expected %d messages, got %v", len(expect), l.Messages)
+	}
+	for i, m := range expect {
+		if !reflect.DeepEqual(l.Messages[i], m) {
+			t.Errorf("expected message %v, got %v", m, l.Messages[i])
+		}
+	}
+	if l.HighestSeverity != InfoSev {
+		t.Errorf("expected highest severity INFO, got %s", SeverityName(l.HighestSeverity))
+	}
+	if got := l.Messages[0].Error(); got != "[INFO] HL100 missing.yaml: lint failed" {
+		t.Errorf("unexpected output: %s", got)
+	}
+}
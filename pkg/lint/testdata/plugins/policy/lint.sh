@@ -0,0 +1,18 @@
+#!/bin/sh
+
+input=$(cat)
+sep=""
+echo '{"messages": ['
+case "$input" in
+  *cost-center*) ;;
+  *)
+    echo '{"rule": "ACME001", "path": "templates/", "message": "the objects have no cost-center label"}'
+    sep=","
+    ;;
+esac
+case "$input" in
+  *docker.io/*)
+    echo "$sep"'{"rule": "ACME002", "severity": "error", "path": "values.yaml", "message": "image docker.io/nginx is not from registry.example.com"}'
+    ;;
+esac
+echo ']}'
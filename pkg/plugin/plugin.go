@@ -40,6 +40,33 @@ type Downloaders struct {
 	Command string `json:"command"`
 }
 
+// Linters represents the plugins capability if it can lint charts with
+// rules of its own, such as the policies of an organization.
+type Linters struct {
+	// Command is the executable path with which the plugin lints a chart. It
+	// is given the rendered chart as JSON on its standard input, and prints
+	// the messages of its rules as JSON on its standard output.
+	Command string `json:"command"`
+	// Rules are the lint rules of the command.
+	Rules []LintRule `json:"rules"`
+}
+
+// LintRule describes a lint rule of a plugin.
+type LintRule struct {
+	// ID identifies the rule, e.g. "ACME001". It must not be the ID of a rule
+	// of Helm or of another plugin.
+	ID string `json:"id"`
+	// Name is a short name of the rule, e.g. "registry-allowlist".
+	Name string `json:"name"`
+	// Severity is the default severity of the messages of the rule: one of
+	// "info", "warning" or "error", the default.
+	Severity string `json:"severity"`
+	// Disabled rules are not run unless enabled by the lint configuration.
+	Disabled bool `json:"disabled"`
+	// Description describes what the rule checks.
+	Description string `json:"description"`
+}
+
 // PlatformCommand represents a command for a particular operating system and architecture
 type PlatformCommand struct {
 	OperatingSystem string `json:"os"`
@@ -94,6 +121,9 @@ type Metadata struct {
 	// Downloaders field is used if the plugin supply downloader mechanism
 	// for special protocols.
 	Downloaders []Downloaders `json:"downloaders"`
+
+	// Linters field is used if the plugin supplies lint rules.
+	Linters []Linters `json:"linters"`
 }
 
 // Plugin represents a plugin.
@@ -0,0 +1,30 @@
+/*
+Copyright The Helm Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package release
+
+// Audit records who performed a release operation and from where.
+type Audit struct {
+	// User is the identity that performed the operation. It is taken from the
+	// Kubernetes credentials when possible and from the local user otherwise.
+	User string `json:"user,omitempty"`
+	// HelmVersion is the version of the Helm client that performed the operation.
+	HelmVersion string `json:"helm_version,omitempty"`
+	// Hostname is the name of the host the Helm client ran on.
+	Hostname string `json:"hostname,omitempty"`
+	// Flags are the command-line flags given to the Helm client, with secret
+	// values redacted.
+	Flags []string `json:"flags,omitempty"`
+}
@@ -33,4 +33,8 @@ type Info struct {
 	Status Status `json:"status,omitempty"`
 	// Contains the rendered templates/NOTES.txt if available
 	Notes string `json:"notes,omitempty"`
+	// Outputs contains the rendered templates/OUTPUTS.yaml if available
+	Outputs map[string]interface{} `json:"outputs,omitempty"`
+	// Audit describes who performed the operation that produced this revision.
+	Audit *Audit `json:"audit,omitempty"`
 }
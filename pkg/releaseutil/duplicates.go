@@ -0,0 +1,119 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package releaseutil
+
+import (
+	"fmt"
+
+	"sigs.k8s.io/yaml"
+
+	"helm.sh/helm/v3/pkg/release"
+)
+
+// ObjectID identifies a Kubernetes object of a release. Objects of the same
+// kind are the same object whatever their API version.
+type ObjectID struct {
+	Kind      string
+	Namespace string
+	Name      string
+}
+
+func (id ObjectID) String() string {
+	if id.Namespace == "" {
+		return fmt.Sprintf("%s %q", id.Kind, id.Name)
+	}
+	return fmt.Sprintf("%s %q in namespace %q", id.Kind, id.Name, id.Namespace)
+}
+
+// DuplicateObject is an object rendered by two templates of a release.
+// Installing the release fails, or one of the objects overwrites the other.
+type DuplicateObject struct {
+	ID ObjectID
+	// Path is the template rendering the object first, and Duplicate the one
+	// rendering it again.
+	Path, Duplicate string
+}
+
+func (d *DuplicateObject) Error() string {
+	if d.Path == d.Duplicate {
+		return fmt.Sprintf("%s is rendered twice by %s", d.ID, d.Path)
+	}
+	return fmt.Sprintf("%s is rendered by both %s and %s", d.ID, d.Path, d.Duplicate)
+}
+
+// FindDuplicateObjects returns the objects rendered more than once among the
+// manifests and the hooks of a release, in install order. Hooks are only
+// duplicates of each other if they run on a same event, e.g. a pre-install and
+// a pre-upgrade hook may share an identity. Objects without a kind or a name,
+// such as those using generateName, are ignored.
+//
+// Namespaces are compared as rendered: an object in the namespace of the
+// release which does not set it is not a duplicate of one which does.
+func FindDuplicateObjects(hooks []*release.Hook, manifests []Manifest) []*DuplicateObject {
+	type object struct {
+		path string
+		// hook is the hook rendering the object, if any.
+		hook *release.Hook
+	}
+	seen := map[ObjectID][]object{}
+	var result []*DuplicateObject
+
+	add := func(id ObjectID, path string, hook *release.Hook) {
+		if id.Kind == "" || id.Name == "" {
+			return
+		}
+		for _, first := range seen[id] {
+			if hook != nil && first.hook != nil && !shareEvent(first.hook, hook) {
+				continue
+			}
+			result = append(result, &DuplicateObject{ID: id, Path: first.path, Duplicate: path})
+			return
+		}
+		seen[id] = append(seen[id], object{path: path, hook: hook})
+	}
+
+	for _, m := range manifests {
+		add(objectID(m.Head), m.Name, nil)
+	}
+	for _, h := range hooks {
+		var head SimpleHead
+		if err := yaml.Unmarshal([]byte(h.Manifest), &head); err != nil {
+			continue
+		}
+		add(objectID(&head), h.Path, h)
+	}
+	return result
+}
+
+// shareEvent returns whether two hooks run on a same event.
+func shareEvent(a, b *release.Hook) bool {
+	for _, e := range a.Events {
+		for _, f := range b.Events {
+			if e == f {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func objectID(head *SimpleHead) ObjectID {
+	if head == nil || head.Metadata == nil {
+		return ObjectID{}
+	}
+	return ObjectID{Kind: head.Kind, Namespace: head.Metadata.Namespace, Name: head.Metadata.Name}
+}
@@ -0,0 +1,125 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package releaseutil
+
+import (
+	"testing"
+
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/release"
+)
+
+func TestFindDuplicateObjects(t *testing.T) {
+	files := map[string]string{
+		"web/templates/config.yaml": `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config
+  namespace: other`,
+		"web/charts/db/templates/config.yaml": `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config`,
+		"web/templates/deployment.yaml": `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web`,
+		"web/charts/db/templates/deployment.yaml": `apiVersion: extensions/v1beta1
+kind: Deployment
+metadata:
+  name: web`,
+		"web/templates/service.yaml": `apiVersion: v1
+kind: Service
+metadata:
+  name: config`,
+		"web/templates/job.yaml": `apiVersion: batch/v1
+kind: Job
+metadata:
+  generateName: migrate-`,
+		"web/templates/hooks.yaml": `apiVersion: v1
+kind: Pod
+metadata:
+  name: migrate
+  annotations:
+    helm.sh/hook: pre-install
+---
+apiVersion: v1
+kind: Pod
+metadata:
+  name: migrate
+  annotations:
+    helm.sh/hook: post-install
+    helm.sh/hook-delete-policy: before-hook-creation
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: config
+  annotations:
+    helm.sh/hook: pre-install`,
+	}
+	hooks, manifests, err := SortManifests(files, chartutil.DefaultVersionSet, InstallOrder)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expect := []string{
+		`ConfigMap "config" is rendered by both web/charts/db/templates/config.yaml and web/templates/config.yaml`,
+		`Deployment "web" is rendered by both web/charts/db/templates/deployment.yaml and web/templates/deployment.yaml`,
+		`Service "config" is rendered by both web/templates/service.yaml and web/templates/hooks.yaml`,
+	}
+	dups := FindDuplicateObjects(hooks, manifests)
+	if len(dups) != len(expect) {
+		t.Fatalf("expected %d duplicates, got %v", len(expect), dups)
+	}
+	for i, d := range dups {
+		if d.Error() != expect[i] {
+			t.Errorf("expected %q, got %q", expect[i], d.Error())
+		}
+	}
+}
+
+func TestFindDuplicateObjectsOfHooks(t *testing.T) {
+	hook := func(path string, events ...release.HookEvent) *release.Hook {
+		return &release.Hook{
+			Path:     path,
+			Manifest: "apiVersion: batch/v1\nkind: Job\nmetadata:\n  name: migrate\n",
+			Events:   events,
+		}
+	}
+
+	hooks := []*release.Hook{
+		hook("web/templates/install.yaml", release.HookPreInstall),
+		hook("web/templates/upgrade.yaml", release.HookPreUpgrade),
+	}
+	if dups := FindDuplicateObjects(hooks, nil); len(dups) != 0 {
+		t.Errorf("expected hooks of distinct events not to be duplicates, got %v", dups)
+	}
+
+	hooks = append(hooks, hook("web/templates/hooks.yaml", release.HookPostDelete, release.HookPreUpgrade))
+	if dups := FindDuplicateObjects(hooks, nil); len(dups) != 1 {
+		t.Errorf("expected 1 duplicate, got %v", dups)
+	} else if expect := `Job "migrate" is rendered by both web/templates/upgrade.yaml and web/templates/hooks.yaml`; dups[0].Error() != expect {
+		t.Errorf("expected %q, got %q", expect, dups[0].Error())
+	}
+
+}
@@ -66,6 +66,13 @@ var events = map[string]release.HookEvent{
 	"test-success": release.HookTest,
 }
 
+// ParseHookEvent returns the hook event of a name in the helm.sh/hook
+// annotation, and whether the event is known.
+func ParseHookEvent(name string) (release.HookEvent, bool) {
+	e, ok := events[strings.ToLower(strings.TrimSpace(name))]
+	return e, ok
+}
+
 // SortManifests takes a map of filename/YAML contents, splits the file
 // by manifest entries, and sorts the entries into hook types.
 //
@@ -75,6 +82,10 @@ var events = map[string]release.HookEvent{
 //
 // Files that do not parse into the expected format are simply placed into a map and
 // returned.
+//
+// The order of the results only depends on the files and their contents, not
+// on the iteration order of the map: resources of the same kind are ordered
+// by file name, and by their position within a file.
 func SortManifests(files map[string]string, apis chartutil.VersionSet, ordering KindSortOrder) ([]*release.Hook, []Manifest, error) {
 	result := &result{}
 
@@ -179,8 +190,7 @@ func (file *manifestFile) sort(result *result) error {
 
 		isUnknownHook := false
 		for _, hookType := range strings.Split(hookTypes, ",") {
-			hookType = strings.ToLower(strings.TrimSpace(hookType))
-			e, ok := events[hookType]
+			e, ok := ParseHookEvent(hookType)
 			if !ok {
 				isUnknownHook = true
 				break
@@ -226,3 +226,38 @@ metadata:
 		}
 	}
 }
+
+func TestSortManifestsIsDeterministic(t *testing.T) {
+	files := map[string]string{}
+	for i, kind := range []string{"Deployment", "Service", "Widget", "Gadget", "ConfigMap", "Job"} {
+		for j := 0; j < 3; j++ {
+			manifest := "kind: " + kind + "\nmetadata:\n  name: " + kind + string(rune('a'+j)) + "\n"
+			if kind == "Job" {
+				manifest += "  annotations:\n    helm.sh/hook: pre-install\n"
+			}
+			files["templates/"+string(rune('a'+i))+string(rune('a'+j))] = manifest + "---\n" + manifest
+		}
+	}
+
+	names := func() []string {
+		hooks, manifests, err := SortManifests(files, chartutil.VersionSet{"v1"}, InstallOrder)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var out []string
+		for _, h := range hooks {
+			out = append(out, h.Path+" "+h.Name)
+		}
+		for _, m := range manifests {
+			out = append(out, m.Name+" "+m.Head.Metadata.Name)
+		}
+		return out
+	}
+
+	expect := names()
+	for i := 0; i < 20; i++ {
+		if got := names(); !reflect.DeepEqual(got, expect) {
+			t.Fatalf("expected the same order on every run, got\n%v\nand\n%v", expect, got)
+		}
+	}
+}
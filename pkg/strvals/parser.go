@@ -17,8 +17,10 @@ package strvals
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"strconv"
 	"strings"
 
@@ -29,6 +31,9 @@ import (
 // ErrNotList indicates that a non-list was treated as a list.
 var ErrNotList = errors.New("not a list")
 
+// PointerPrefix marks a key given as an RFC 6901 JSON pointer.
+const PointerPrefix = "pointer:"
+
 // ToYAML takes a string of arguments and converts to a YAML document.
 func ToYAML(s string) (string, error) {
 	m, err := Parse(s)
@@ -103,6 +108,17 @@ func ParseIntoFile(s string, dest map[string]interface{}, reader RunesValueReade
 	return t.parse()
 }
 
+// ParseJSON parses a set line whose values are JSON documents and merges the
+// result into dest.
+//
+// A set line is of the form name1=json1,name2=json2, where each value may be
+// any JSON value, including objects and arrays.
+func ParseJSON(s string, dest map[string]interface{}) error {
+	scanner := bytes.NewBufferString(s)
+	t := newJSONParser(scanner, dest)
+	return t.parse()
+}
+
 // RunesValueReader is a function that takes the given value (a slice of runes)
 // and returns the parsed value
 type RunesValueReader func([]rune) (interface{}, error)
@@ -112,10 +128,16 @@ type RunesValueReader func([]rune) (interface{}, error)
 //
 // where sc is the source of the original data being parsed
 // where data is the final parsed data from the parses with correct types
+// where isjsonval is set if values are JSON documents rather than strvals
+//
+// Keys are either dotted paths such as a.b[0].c or, if they start with
+// PointerPrefix, RFC 6901 JSON pointers such as
+// pointer:/metadata/annotations/prometheus.io~1scrape.
 type parser struct {
-	sc     *bytes.Buffer
-	data   map[string]interface{}
-	reader RunesValueReader
+	sc        *bytes.Buffer
+	data      map[string]interface{}
+	reader    RunesValueReader
+	isjsonval bool
 }
 
 func newParser(sc *bytes.Buffer, data map[string]interface{}, stringBool bool) *parser {
@@ -129,9 +151,19 @@ func newFileParser(sc *bytes.Buffer, data map[string]interface{}, reader RunesVa
 	return &parser{sc: sc, data: data, reader: reader}
 }
 
+func newJSONParser(sc *bytes.Buffer, data map[string]interface{}) *parser {
+	return &parser{sc: sc, data: data, isjsonval: true}
+}
+
 func (t *parser) parse() error {
 	for {
-		err := t.key(t.data)
+		var err error
+		if bytes.HasPrefix(t.sc.Bytes(), []byte(PointerPrefix)) {
+			t.sc.Next(len(PointerPrefix))
+			err = t.pointerKey(t.data)
+		} else {
+			err = t.key(t.data)
+		}
 		if err == nil {
 			continue
 		}
@@ -185,6 +217,14 @@ func (t *parser) key(data map[string]interface{}) (reterr error) {
 			return err
 		case last == '=':
 			//End of key. Consume =, Get value.
+			if t.isjsonval {
+				v, e := t.jsonVal()
+				if e != nil {
+					return errors.Wrapf(e, "key %q", string(k))
+				}
+				set(data, string(k), v)
+				return nil
+			}
 			// FIXME: Get value list first
 			vl, e := t.valList()
 			switch e {
@@ -280,6 +320,13 @@ func (t *parser) listItem(list []interface{}, i int) ([]interface{}, error) {
 	case err != nil:
 		return list, err
 	case last == '=':
+		if t.isjsonval {
+			v, e := t.jsonVal()
+			if e != nil {
+				return list, e
+			}
+			return setIndex(list, i, v)
+		}
 		vl, e := t.valList()
 		switch e {
 		case nil:
@@ -335,6 +382,138 @@ func (t *parser) listItem(list []interface{}, i int) ([]interface{}, error) {
 	}
 }
 
+// pointerKey parses a key given as a JSON pointer, PointerPrefix already
+// consumed, and its value.
+func (t *parser) pointerKey(data map[string]interface{}) error {
+	stop := runeSet([]rune{'=', ','})
+	k, last, err := runesUntil(t.sc, stop)
+	pointer := string(k)
+	if err != nil || last == ',' {
+		return errors.Errorf("key %q has no value", PointerPrefix+pointer)
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return errors.Errorf("key %q is not a JSON pointer: it must start with '/'", PointerPrefix+pointer)
+	}
+	segments, err := parsePointer(pointer)
+	if err != nil {
+		return err
+	}
+
+	var v interface{}
+	if t.isjsonval {
+		if v, err = t.jsonVal(); err != nil {
+			return errors.Wrapf(err, "key %q", PointerPrefix+pointer)
+		}
+	} else {
+		vl, e := t.valList()
+		switch e {
+		case nil:
+			v = vl
+		case io.EOF:
+			v = ""
+		case ErrNotList:
+			rs, e := t.val()
+			if e != nil && e != io.EOF {
+				return e
+			}
+			if v, err = t.reader(rs); err != nil {
+				return err
+			}
+		default:
+			return e
+		}
+	}
+
+	if _, err := setPointer(data, segments, v); err != nil {
+		return errors.Wrapf(err, "key %q", PointerPrefix+pointer)
+	}
+	return nil
+}
+
+// parsePointer splits an RFC 6901 JSON pointer into its unescaped reference
+// tokens.
+func parsePointer(pointer string) ([]string, error) {
+	segments := strings.Split(pointer[1:], "/")
+	for i, s := range segments {
+		if s == "" {
+			return nil, errors.Errorf("key %q has an empty reference token", PointerPrefix+pointer)
+		}
+		segments[i] = strings.NewReplacer("~1", "/", "~0", "~").Replace(s)
+	}
+	return segments, nil
+}
+
+// setPointer sets val at the location given by the reference tokens of a JSON
+// pointer within v and returns the updated v. Missing maps are created along
+// the way. A token refers to a list index if the value it is applied to is a
+// list, where "-" appends to the list.
+func setPointer(v interface{}, segments []string, val interface{}) (interface{}, error) {
+	if len(segments) == 0 {
+		return val, nil
+	}
+	seg := segments[0]
+
+	if v == nil && seg == "-" {
+		v = []interface{}{}
+	}
+	switch c := v.(type) {
+	case []interface{}:
+		i := len(c)
+		if seg != "-" {
+			var err error
+			if i, err = strconv.Atoi(seg); err != nil {
+				return v, errors.Errorf("invalid list index %q", seg)
+			}
+		}
+		var cur interface{}
+		if i >= 0 && i < len(c) {
+			cur = c[i]
+		}
+		inner, err := setPointer(cur, segments[1:], val)
+		if err != nil {
+			return v, err
+		}
+		return setIndex(c, i, inner)
+	case map[string]interface{}:
+		inner, err := setPointer(c[seg], segments[1:], val)
+		if err != nil {
+			return v, err
+		}
+		c[seg] = inner
+		return c, nil
+	case nil:
+		return setPointer(map[string]interface{}{}, segments, val)
+	default:
+		return v, errors.Errorf("cannot set %q on a value of type %T", seg, v)
+	}
+}
+
+// jsonVal reads a JSON document and the ',' separating it from the next key.
+func (t *parser) jsonVal() (interface{}, error) {
+	dec := json.NewDecoder(t.sc)
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return nil, errors.Wrap(err, "invalid JSON value")
+	}
+
+	// The decoder reads ahead, so put back what it did not consume.
+	rest, _ := ioutil.ReadAll(dec.Buffered())
+	t.sc = bytes.NewBuffer(append(rest, t.sc.Bytes()...))
+
+	for {
+		r, _, err := t.sc.ReadRune()
+		switch {
+		case err != nil:
+			return v, nil
+		case r == ',':
+			return v, nil
+		case r == ' ' || r == '\t' || r == '\n' || r == '\r':
+		default:
+			return nil, errors.Errorf("unexpected data after JSON value: %q", string(r)+t.sc.String())
+		}
+	}
+}
+
 func (t *parser) val() ([]rune, error) {
 	stop := runeSet([]rune{','})
 	v, _, err := runesUntil(t.sc, stop)
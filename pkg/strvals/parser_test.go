@@ -554,6 +554,222 @@ func TestParseIntoFile(t *testing.T) {
 	}
 }
 
+func TestParseJSON(t *testing.T) {
+	tests := []struct {
+		input  string
+		got    map[string]interface{}
+		expect map[string]interface{}
+		err    bool
+	}{
+		{ // set json scalars values, and replace one existing key
+			input: "outer.inner1=\"1\",outer.inner3=3,outer.inner4=true,outer.inner5=null",
+			got: map[string]interface{}{
+				"outer": map[string]interface{}{
+					"inner1": "overwrite",
+					"inner2": "value2",
+				},
+			},
+			expect: map[string]interface{}{
+				"outer": map[string]interface{}{
+					"inner1": "1",
+					"inner2": "value2",
+					"inner3": 3,
+					"inner4": true,
+					"inner5": nil,
+				},
+			},
+		},
+		{ // set json objects and arrays, and replace one existing key
+			input: "outer.inner1={\"a\":\"1\",\"b\":2,\"c\":[1,2,3]},outer.inner3=[\"new value 1\",\"new value 2\"],outer.inner4={\"aa\":\"1\",\"bb\":2,\"cc\":[1,2,3]},outer.inner5=[{\"A\":\"1\",\"B\":2,\"C\":[1,2,3]}]",
+			got: map[string]interface{}{
+				"outer": map[string]interface{}{
+					"inner1": map[string]interface{}{
+						"x": "overwrite",
+					},
+					"inner2": "value2",
+					"inner3": []interface{}{
+						"overwrite",
+					},
+				},
+			},
+			expect: map[string]interface{}{
+				"outer": map[string]interface{}{
+					"inner1": map[string]interface{}{"a": "1", "b": 2, "c": []interface{}{1, 2, 3}},
+					"inner2": "value2",
+					"inner3": []interface{}{"new value 1", "new value 2"},
+					"inner4": map[string]interface{}{"aa": "1", "bb": 2, "cc": []interface{}{1, 2, 3}},
+					"inner5": []interface{}{map[string]interface{}{"A": "1", "B": 2, "C": []interface{}{1, 2, 3}}},
+				},
+			},
+		},
+		{ // list items and whitespace before the separator
+			input: "list[1]={\"name\": \"b\"} ,other=\"x,y\"",
+			got:   map[string]interface{}{},
+			expect: map[string]interface{}{
+				"list":  []interface{}{nil, map[string]interface{}{"name": "b"}},
+				"other": "x,y",
+			},
+		},
+		{ // JSON pointer keys
+			input: "pointer:/metadata/annotations/prometheus.io~1scrape=\"true\",pointer:/a~0b=1",
+			got:   map[string]interface{}{},
+			expect: map[string]interface{}{
+				"metadata": map[string]interface{}{
+					"annotations": map[string]interface{}{"prometheus.io/scrape": "true"},
+				},
+				"a~b": 1,
+			},
+		},
+		{
+			input: "name=",
+			got:   map[string]interface{}{},
+			err:   true,
+		},
+		{
+			input: "name={\"a\":1",
+			got:   map[string]interface{}{},
+			err:   true,
+		},
+		{
+			input: "name=1 2",
+			got:   map[string]interface{}{},
+			err:   true,
+		},
+	}
+	for _, tt := range tests {
+		err := ParseJSON(tt.input, tt.got)
+		if err != nil {
+			if tt.err {
+				continue
+			}
+			t.Fatalf("%s: %s", tt.input, err)
+		}
+		if tt.err {
+			t.Fatalf("%s: Expected error. Got nil", tt.input)
+		}
+
+		y1, err := yaml.Marshal(tt.expect)
+		if err != nil {
+			t.Fatalf("Error serializing expected value: %s", err)
+		}
+		y2, err := yaml.Marshal(tt.got)
+		if err != nil {
+			t.Fatalf("Error serializing parsed value: %s", err)
+		}
+
+		if string(y1) != string(y2) {
+			t.Errorf("%s: Expected:\n%s\nGot:\n%s", tt.input, y1, y2)
+		}
+	}
+}
+
+func TestParsePointer(t *testing.T) {
+	tests := []struct {
+		input  string
+		got    map[string]interface{}
+		expect map[string]interface{}
+		err    bool
+	}{
+		{
+			input: "pointer:/metadata/annotations/prometheus.io~1scrape=true",
+			got:   map[string]interface{}{},
+			expect: map[string]interface{}{
+				"metadata": map[string]interface{}{
+					"annotations": map[string]interface{}{"prometheus.io/scrape": true},
+				},
+			},
+		},
+		{ // pointer and dotted keys can be mixed
+			input: "pointer:/a.b=1,c.d=2,pointer:/e~0f/g=h",
+			got:   map[string]interface{}{},
+			expect: map[string]interface{}{
+				"a.b": 1,
+				"c":   map[string]interface{}{"d": 2},
+				"e~f": map[string]interface{}{"g": "h"},
+			},
+		},
+		{ // numeric tokens index into existing lists, "-" appends
+			input: "pointer:/list/0/name=first,pointer:/list/-=last,pointer:/map/0=zero",
+			got: map[string]interface{}{
+				"list": []interface{}{map[string]interface{}{"name": "old"}},
+			},
+			expect: map[string]interface{}{
+				"list": []interface{}{map[string]interface{}{"name": "first"}, "last"},
+				"map":  map[string]interface{}{"0": "zero"},
+			},
+		},
+		{ // "-" creates a missing list
+			input: "pointer:/list/-={a,b}",
+			got:   map[string]interface{}{},
+			expect: map[string]interface{}{
+				"list": []interface{}{[]interface{}{"a", "b"}},
+			},
+		},
+		{ // without the prefix, a key starting with '/' is a plain key
+			input: "/a/b=1",
+			got:   map[string]interface{}{},
+			expect: map[string]interface{}{
+				"/a/b": 1,
+			},
+		},
+		{
+			input: "pointer:a/b=1",
+			got:   map[string]interface{}{},
+			err:   true,
+		},
+		{
+			input: "pointer:/a/b=1",
+			got:   map[string]interface{}{"a": "scalar"},
+			err:   true,
+		},
+		{
+			input: "pointer:/list/x=1",
+			got:   map[string]interface{}{"list": []interface{}{}},
+			err:   true,
+		},
+		{
+			input: "pointer:/a//b=1",
+			got:   map[string]interface{}{},
+			err:   true,
+		},
+		{
+			input: "pointer:/a",
+			got:   map[string]interface{}{},
+			err:   true,
+		},
+		{
+			input: "pointer:/a,b=1",
+			got:   map[string]interface{}{},
+			err:   true,
+		},
+	}
+	for _, tt := range tests {
+		err := ParseInto(tt.input, tt.got)
+		if err != nil {
+			if tt.err {
+				continue
+			}
+			t.Fatalf("%s: %s", tt.input, err)
+		}
+		if tt.err {
+			t.Fatalf("%s: Expected error. Got nil", tt.input)
+		}
+
+		y1, err := yaml.Marshal(tt.expect)
+		if err != nil {
+			t.Fatalf("Error serializing expected value: %s", err)
+		}
+		y2, err := yaml.Marshal(tt.got)
+		if err != nil {
+			t.Fatalf("Error serializing parsed value: %s", err)
+		}
+
+		if string(y1) != string(y2) {
+			t.Errorf("%s: Expected:\n%s\nGot:\n%s", tt.input, y1, y2)
+		}
+	}
+}
+
 func TestToYAML(t *testing.T) {
 	// The TestParse does the hard part. We just verify that YAML formatting is
 	// happening.
@@ -0,0 +1,312 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package unittest
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+	"k8s.io/client-go/util/jsonpath"
+)
+
+// Assertion is a check of the rendered templates. Exactly one kind of check
+// must be set.
+type Assertion struct {
+	// Template restricts the assertion to a single template, overriding the
+	// templates of the test.
+	Template string `json:"template,omitempty"`
+	// DocumentIndex restricts the assertion to a single document of the
+	// templates. Without it, every document must pass the assertion.
+	DocumentIndex *int `json:"documentIndex,omitempty"`
+
+	// Equal checks that the value at a path equals the given value.
+	Equal *PathValue `json:"equal,omitempty"`
+	// NotEqual checks that the value at a path differs from the given value.
+	NotEqual *PathValue `json:"notEqual,omitempty"`
+	// MatchRegex checks that the string at a path matches a pattern.
+	MatchRegex *PathPattern `json:"matchRegex,omitempty"`
+	// NotMatchRegex checks that the string at a path does not match a pattern.
+	NotMatchRegex *PathPattern `json:"notMatchRegex,omitempty"`
+	// HasDocuments checks the number of rendered documents.
+	HasDocuments *DocumentCount `json:"hasDocuments,omitempty"`
+	// FailedTemplate checks that rendering fails.
+	FailedTemplate *TemplateError `json:"failedTemplate,omitempty"`
+	// MatchSnapshot checks that the documents, or the value at a path, equal
+	// those recorded in the snapshot file of the suite.
+	MatchSnapshot *Snapshot `json:"matchSnapshot,omitempty"`
+}
+
+// PathValue is a value expected at a path of a document.
+type PathValue struct {
+	// Path is a JSONPath expression, such as "spec.replicas" or
+	// "{.spec.containers[0].image}".
+	Path  string      `json:"path"`
+	Value interface{} `json:"value"`
+}
+
+// PathPattern is a regular expression expected to match the value at a path of
+// a document.
+type PathPattern struct {
+	Path    string `json:"path"`
+	Pattern string `json:"pattern"`
+}
+
+// DocumentCount is the number of documents expected to be rendered.
+type DocumentCount struct {
+	Count int `json:"count"`
+}
+
+// TemplateError is the error expected from rendering the templates. If neither
+// field is set, any error passes.
+type TemplateError struct {
+	// ErrorMessage must be contained in the error.
+	ErrorMessage string `json:"errorMessage,omitempty"`
+	// ErrorPattern is a regular expression which must match the error.
+	ErrorPattern string `json:"errorPattern,omitempty"`
+}
+
+// Snapshot compares the documents, or the value at Path of each document, with
+// a snapshot.
+type Snapshot struct {
+	Path string `json:"path,omitempty"`
+}
+
+// Kind returns the name of the check of the assertion, e.g. "equal".
+func (a *Assertion) Kind() string {
+	switch {
+	case a.Equal != nil:
+		return "equal"
+	case a.NotEqual != nil:
+		return "notEqual"
+	case a.MatchRegex != nil:
+		return "matchRegex"
+	case a.NotMatchRegex != nil:
+		return "notMatchRegex"
+	case a.HasDocuments != nil:
+		return "hasDocuments"
+	case a.FailedTemplate != nil:
+		return "failedTemplate"
+	case a.MatchSnapshot != nil:
+		return "matchSnapshot"
+	}
+	return ""
+}
+
+func (a *Assertion) validate() error {
+	n := 0
+	for _, set := range []bool{
+		a.Equal != nil, a.NotEqual != nil, a.MatchRegex != nil, a.NotMatchRegex != nil,
+		a.HasDocuments != nil, a.FailedTemplate != nil, a.MatchSnapshot != nil,
+	} {
+		if set {
+			n++
+		}
+	}
+	switch {
+	case n == 0:
+		return errors.New("no check given")
+	case n > 1:
+		return errors.New("only one check may be given")
+	case a.DocumentIndex != nil && *a.DocumentIndex < 0:
+		return errors.New("documentIndex must not be negative")
+	}
+	for _, p := range []*PathPattern{a.MatchRegex, a.NotMatchRegex} {
+		if p == nil {
+			continue
+		}
+		if _, err := regexp.Compile(p.Pattern); err != nil {
+			return errors.Wrap(err, "invalid pattern")
+		}
+	}
+	if a.FailedTemplate != nil && a.FailedTemplate.ErrorPattern != "" {
+		if _, err := regexp.Compile(a.FailedTemplate.ErrorPattern); err != nil {
+			return errors.Wrap(err, "invalid errorPattern")
+		}
+	}
+	return nil
+}
+
+// check evaluates the assertion against the documents rendered from the
+// selected templates, or the rendering error. It returns an error describing
+// why the assertion failed.
+func (a *Assertion) check(docs []interface{}, renderErr error, snapshot func(interface{}) error) error {
+	if a.FailedTemplate != nil {
+		return a.checkFailed(renderErr)
+	}
+	if renderErr != nil {
+		return renderErr
+	}
+	if a.HasDocuments != nil {
+		if len(docs) != a.HasDocuments.Count {
+			return errors.Errorf("expected %d documents, got %d", a.HasDocuments.Count, len(docs))
+		}
+		return nil
+	}
+
+	if a.DocumentIndex != nil {
+		i := *a.DocumentIndex
+		if i >= len(docs) {
+			return errors.Errorf("documentIndex %d out of range, got %d documents", i, len(docs))
+		}
+		docs = docs[i : i+1]
+	} else if len(docs) == 0 {
+		return errors.New("no documents rendered")
+	}
+
+	if a.MatchSnapshot != nil {
+		if a.MatchSnapshot.Path == "" {
+			return snapshot(docs)
+		}
+		values := make([]interface{}, 0, len(docs))
+		for _, doc := range docs {
+			v, err := lookup(doc, a.MatchSnapshot.Path)
+			if err != nil {
+				return err
+			}
+			values = append(values, v)
+		}
+		return snapshot(values)
+	}
+
+	for i, doc := range docs {
+		if err := a.checkDocument(doc); err != nil {
+			if a.DocumentIndex != nil {
+				i = *a.DocumentIndex
+			}
+			return errors.Wrapf(err, "document %d", i)
+		}
+	}
+	return nil
+}
+
+func (a *Assertion) checkFailed(renderErr error) error {
+	if renderErr == nil {
+		return errors.New("expected rendering to fail, but it succeeded")
+	}
+	msg := renderErr.Error()
+	if want := a.FailedTemplate.ErrorMessage; want != "" && !strings.Contains(msg, want) {
+		return errors.Errorf("expected error containing %q, got %q", want, msg)
+	}
+	if want := a.FailedTemplate.ErrorPattern; want != "" && !regexp.MustCompile(want).MatchString(msg) {
+		return errors.Errorf("expected error matching %q, got %q", want, msg)
+	}
+	return nil
+}
+
+func (a *Assertion) checkDocument(doc interface{}) error {
+	switch {
+	case a.Equal != nil:
+		got, err := lookup(doc, a.Equal.Path)
+		if err != nil {
+			return err
+		}
+		if !reflect.DeepEqual(got, a.Equal.Value) {
+			return errors.Errorf("expected %s to equal %s, got %s", a.Equal.Path, show(a.Equal.Value), show(got))
+		}
+	case a.NotEqual != nil:
+		got, err := lookup(doc, a.NotEqual.Path)
+		if err != nil {
+			return err
+		}
+		if reflect.DeepEqual(got, a.NotEqual.Value) {
+			return errors.Errorf("expected %s not to equal %s", a.NotEqual.Path, show(got))
+		}
+	case a.MatchRegex != nil:
+		s, err := lookupString(doc, a.MatchRegex.Path)
+		if err != nil {
+			return err
+		}
+		if !regexp.MustCompile(a.MatchRegex.Pattern).MatchString(s) {
+			return errors.Errorf("expected %s to match %q, got %q", a.MatchRegex.Path, a.MatchRegex.Pattern, s)
+		}
+	case a.NotMatchRegex != nil:
+		s, err := lookupString(doc, a.NotMatchRegex.Path)
+		if err != nil {
+			return err
+		}
+		if regexp.MustCompile(a.NotMatchRegex.Pattern).MatchString(s) {
+			return errors.Errorf("expected %s not to match %q, got %q", a.NotMatchRegex.Path, a.NotMatchRegex.Pattern, s)
+		}
+	}
+	return nil
+}
+
+// lookup returns the value at the JSONPath p of doc. Missing values are nil,
+// and a path matching several values yields a list of them.
+func lookup(doc interface{}, p string) (interface{}, error) {
+	jp := jsonpath.New(p)
+	jp.AllowMissingKeys(true)
+	if err := jp.Parse(jsonPathTemplate(p)); err != nil {
+		return nil, errors.Wrapf(err, "invalid path %q", p)
+	}
+	results, err := jp.FindResults(doc)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to evaluate path %q", p)
+	}
+
+	var values []interface{}
+	for _, r := range results {
+		for _, v := range r {
+			values = append(values, v.Interface())
+		}
+	}
+	switch len(values) {
+	case 0:
+		return nil, nil
+	case 1:
+		return values[0], nil
+	}
+	return values, nil
+}
+
+func lookupString(doc interface{}, p string) (string, error) {
+	v, err := lookup(doc, p)
+	if err != nil {
+		return "", err
+	}
+	s, ok := v.(string)
+	if !ok {
+		return "", errors.Errorf("expected %s to be a string, got %s", p, show(v))
+	}
+	return s, nil
+}
+
+// jsonPathTemplate turns a plain path such as "spec.replicas" into the
+// template "{.spec.replicas}" understood by the jsonpath package.
+func jsonPathTemplate(p string) string {
+	if strings.HasPrefix(p, "{") {
+		return p
+	}
+	p = strings.TrimPrefix(p, "$")
+	if !strings.HasPrefix(p, ".") && !strings.HasPrefix(p, "[") {
+		p = "." + p
+	}
+	return "{" + p + "}"
+}
+
+// show formats a value for a failure message.
+func show(v interface{}) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(b)
+}
@@ -0,0 +1,39 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*Package unittest runs unit tests against the rendered templates of a chart.
+
+The tests of a chart live in files ending in _test.yaml in its tests directory.
+Each file is a suite of tests, which render the templates of the chart with the
+values, release and capabilities given by the suite and the test, and check the
+rendered documents with assertions:
+
+	suite: deployment
+	templates:
+	  - templates/deployment.yaml
+	tests:
+	  - it: should set the replicas
+	    set:
+	      replicaCount: 3
+	    asserts:
+	      - equal:
+	          path: spec.replicas
+	          value: 3
+	      - matchSnapshot: {}
+
+The snapshots of a suite are stored in the __snapshot__ directory next to it.
+*/
+package unittest // import "helm.sh/helm/v3/pkg/unittest"
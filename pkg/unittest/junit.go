@@ -0,0 +1,100 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package unittest
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+type junitTestSuites struct {
+	XMLName xml.Name         `xml:"testsuites"`
+	Suites  []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Errors   int             `xml:"errors,attr"`
+	Time     string          `xml:"time,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	Classname string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitMessage `xml:"failure,omitempty"`
+	Error     *junitMessage `xml:"error,omitempty"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// WriteJUnit writes the results of the test suites of the chart named chartName
+// as JUnit XML.
+func WriteJUnit(w io.Writer, chartName string, results []*SuiteResult) error {
+	out := junitTestSuites{}
+	for _, r := range results {
+		suite := junitTestSuite{
+			Name:  r.Suite.Name,
+			Tests: len(r.Tests),
+			Time:  junitTime(r.Duration),
+		}
+		for _, t := range r.Tests {
+			c := junitTestCase{
+				Name:      t.Test.It,
+				Classname: chartName + "." + r.Suite.Name,
+				Time:      junitTime(t.Duration),
+			}
+			switch {
+			case t.Err != nil:
+				suite.Errors++
+				c.Error = &junitMessage{Message: t.Err.Error(), Text: t.Err.Error()}
+			case len(t.Failures) > 0:
+				suite.Failures++
+				c.Failure = &junitMessage{
+					Message: fmt.Sprintf("%d of %d assertions failed", len(t.Failures), len(t.Test.Asserts)),
+					Text:    strings.Join(t.Failures, "\n"),
+				}
+			}
+			suite.Cases = append(suite.Cases, c)
+		}
+		out.Suites = append(out.Suites, suite)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(out); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+func junitTime(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
@@ -0,0 +1,339 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package unittest
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/yaml"
+
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/cli/values"
+	"helm.sh/helm/v3/pkg/engine"
+	"helm.sh/helm/v3/pkg/releaseutil"
+)
+
+// Defaults of the release templates are rendered for.
+const (
+	DefaultReleaseName      = "RELEASE-NAME"
+	DefaultReleaseNamespace = "default"
+)
+
+// Rendered templates that are not installed as manifests.
+const (
+	notesFile   = "NOTES.txt"
+	outputsFile = "OUTPUTS.yaml"
+)
+
+// Runner runs the test suites of a chart.
+type Runner struct {
+	// ChartPath is the location of the chart under test.
+	ChartPath string
+	// UpdateSnapshots records the rendered results of matchSnapshot
+	// assertions instead of comparing them, and removes unused snapshots.
+	UpdateSnapshots bool
+	// Strict fails rendering on references to missing values.
+	Strict bool
+}
+
+// SuiteResult is the result of running a test suite.
+type SuiteResult struct {
+	Suite    *Suite
+	Tests    []*TestResult
+	Duration time.Duration
+}
+
+// TestResult is the result of running a test.
+type TestResult struct {
+	Test *Test
+	// Failures describe the failed assertions.
+	Failures []string
+	// Err is set if the test could not be run, e.g. because a values file is
+	// missing.
+	Err      error
+	Duration time.Duration
+}
+
+// Passed reports whether the test ran and all of its assertions passed.
+func (r *TestResult) Passed() bool {
+	return r.Err == nil && len(r.Failures) == 0
+}
+
+// Failed returns the number of tests of the suite which did not pass.
+func (r *SuiteResult) Failed() int {
+	n := 0
+	for _, t := range r.Tests {
+		if !t.Passed() {
+			n++
+		}
+	}
+	return n
+}
+
+// Run runs the tests of the suite.
+func (r *Runner) Run(s *Suite) (*SuiteResult, error) {
+	snaps, err := loadSnapshots(snapshotPath(s.path), r.UpdateSnapshots)
+	if err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	result := &SuiteResult{Suite: s}
+	for _, t := range s.Tests {
+		testStart := time.Now()
+		tr := &TestResult{Test: t}
+		tr.Failures, tr.Err = r.runTest(s, t, snaps)
+		if tr.Err != nil {
+			snaps.keep(t.It)
+		}
+		tr.Duration = time.Since(testStart)
+		result.Tests = append(result.Tests, tr)
+	}
+	result.Duration = time.Since(start)
+
+	return result, snaps.save()
+}
+
+func (r *Runner) runTest(s *Suite, t *Test, snaps *snapshots) ([]string, error) {
+	vals, err := r.values(s, t)
+	if err != nil {
+		return nil, err
+	}
+	caps, err := capabilities(s.Capabilities, t.Capabilities)
+	if err != nil {
+		return nil, err
+	}
+	// Rendering changes the chart, e.g. by removing disabled dependencies, so
+	// each test renders a fresh copy.
+	chrt, err := loader.Load(r.ChartPath)
+	if err != nil {
+		return nil, err
+	}
+	files, renderErr := render(chrt, release(s.Release, t.Release), caps, vals, r.Strict)
+
+	templates := s.Templates
+	if len(t.Templates) > 0 {
+		templates = t.Templates
+	}
+
+	var failures []string
+	snapshotCount := 0
+	for i, a := range t.Asserts {
+		var docs []interface{}
+		if renderErr == nil {
+			selected := templates
+			if a.Template != "" {
+				selected = []string{a.Template}
+			}
+			if docs, err = documents(chrt, files, selected); err != nil {
+				failures = append(failures, fmt.Sprintf("%d: %s: %s", i+1, a.Kind(), err))
+				continue
+			}
+		}
+
+		snapshot := func(v interface{}) error {
+			snapshotCount++
+			return snaps.match(fmt.Sprintf("%s %d", t.It, snapshotCount), v)
+		}
+		if err := a.check(docs, renderErr, snapshot); err != nil {
+			failures = append(failures, fmt.Sprintf("%d: %s: %s", i+1, a.Kind(), err))
+		}
+	}
+	return failures, nil
+}
+
+// values merges the values files and set values of the suite and the test.
+func (r *Runner) values(s *Suite, t *Test) (map[string]interface{}, error) {
+	base := filepath.Dir(s.path)
+	vals := map[string]interface{}{}
+	for _, f := range append(append([]string{}, s.Values...), t.Values...) {
+		if !filepath.IsAbs(f) {
+			f = filepath.Join(base, f)
+		}
+		fileVals, err := chartutil.ReadValuesFile(f)
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to read values file")
+		}
+		vals = values.MergeMaps(vals, fileVals)
+	}
+	for _, set := range []map[string]interface{}{s.Set, t.Set} {
+		keys := make([]string, 0, len(set))
+		for k := range set {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			setValue(vals, k, set[k])
+		}
+	}
+	return vals, nil
+}
+
+// render renders the templates of the chart with the given values, the same
+// way installing the chart would.
+func render(chrt *chart.Chart, rel Release, caps *chartutil.Capabilities, vals map[string]interface{}, strict bool) (map[string]string, error) {
+	if err := chartutil.ProcessDependencies(chrt, vals); err != nil {
+		return nil, err
+	}
+	options := chartutil.ReleaseOptions{
+		Name:      rel.Name,
+		Namespace: rel.Namespace,
+		Revision:  rel.Revision,
+		IsInstall: !rel.IsUpgrade,
+		IsUpgrade: rel.IsUpgrade,
+	}
+	renderVals, err := chartutil.ToRenderValues(chrt, vals, options, caps)
+	if err != nil {
+		return nil, err
+	}
+	// Snapshots must not change from one run to the next, so rendering is
+	// reproducible.
+	var seed int64
+	now := engine.DefaultRenderTime
+	return engine.Engine{Strict: strict, RenderSeed: &seed, RenderTime: &now}.Render(chrt, renderVals)
+}
+
+// documents returns the documents rendered from the templates of chrt, or from
+// all of its manifest templates if none are given.
+func documents(chrt *chart.Chart, files map[string]string, templates []string) ([]interface{}, error) {
+	var names []string
+	if len(templates) == 0 {
+		for name := range files {
+			if isManifest(name) {
+				names = append(names, name)
+			}
+		}
+		sort.Strings(names)
+	}
+	for _, tpl := range templates {
+		name := path.Join(chrt.Name(), tpl)
+		if _, ok := files[name]; !ok {
+			return nil, errors.Errorf("template %s not found in chart", tpl)
+		}
+		names = append(names, name)
+	}
+
+	var docs []interface{}
+	for _, name := range names {
+		manifests := releaseutil.SplitManifests(files[name])
+		keys := make([]string, 0, len(manifests))
+		for k := range manifests {
+			keys = append(keys, k)
+		}
+		sort.Sort(releaseutil.BySplitManifestsOrder(keys))
+		for _, k := range keys {
+			var doc interface{}
+			if err := yaml.Unmarshal([]byte(manifests[k]), &doc); err != nil {
+				return nil, errors.Wrapf(err, "unable to parse %s", name)
+			}
+			// Documents holding nothing but comments are not rendered.
+			if doc != nil {
+				docs = append(docs, doc)
+			}
+		}
+	}
+	return docs, nil
+}
+
+// isManifest reports whether the rendered file name is a template installed as
+// a manifest, that is one under templates/ that is neither a partial nor the
+// notes or outputs of a chart.
+func isManifest(name string) bool {
+	base := path.Base(name)
+	if strings.HasPrefix(base, "_") || base == notesFile || base == outputsFile {
+		return false
+	}
+	for _, dir := range strings.Split(path.Dir(name), "/") {
+		if dir == chartutil.TemplatesDir {
+			return true
+		}
+	}
+	return false
+}
+
+// release returns the release of the suite, overridden by the fields set in
+// the release of the test.
+func release(suite Release, test *Release) Release {
+	rel := Release{
+		Name:      DefaultReleaseName,
+		Namespace: DefaultReleaseNamespace,
+		Revision:  1,
+	}
+	for _, r := range []*Release{&suite, test} {
+		if r == nil {
+			continue
+		}
+		if r.Name != "" {
+			rel.Name = r.Name
+		}
+		if r.Namespace != "" {
+			rel.Namespace = r.Namespace
+		}
+		if r.Revision != 0 {
+			rel.Revision = r.Revision
+		}
+		if r.IsUpgrade {
+			rel.IsUpgrade = true
+		}
+	}
+	return rel
+}
+
+// capabilities returns the default capabilities, overridden by those of the
+// suite and the test.
+func capabilities(suite Capabilities, test *Capabilities) (*chartutil.Capabilities, error) {
+	caps := &chartutil.Capabilities{
+		KubeVersion: chartutil.DefaultCapabilities.KubeVersion,
+		APIVersions: append(chartutil.VersionSet{}, chartutil.DefaultCapabilities.APIVersions...),
+	}
+	for _, c := range []*Capabilities{&suite, test} {
+		if c == nil {
+			continue
+		}
+		if c.KubeVersion != "" {
+			kv, err := chartutil.ParseKubeVersion(c.KubeVersion)
+			if err != nil {
+				return nil, err
+			}
+			caps.KubeVersion = kv
+		}
+		caps.APIVersions = append(caps.APIVersions, c.APIVersions...)
+	}
+	return caps, nil
+}
+
+// setValue sets the value at the dotted key in vals, creating maps on the way.
+func setValue(vals map[string]interface{}, key string, v interface{}) {
+	keys := strings.Split(key, ".")
+	for _, k := range keys[:len(keys)-1] {
+		next, ok := vals[k].(map[string]interface{})
+		if !ok {
+			next = map[string]interface{}{}
+			vals[k] = next
+		}
+		vals = next
+	}
+	vals[keys[len(keys)-1]] = v
+}
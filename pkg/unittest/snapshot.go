@@ -0,0 +1,149 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package unittest
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/pmezard/go-difflib/difflib"
+	"sigs.k8s.io/yaml"
+)
+
+// SnapshotDir is the directory, next to the test suites, holding the snapshots
+// of the suites.
+const SnapshotDir = "__snapshot__"
+
+// snapshots are the recorded results of the matchSnapshot assertions of a
+// suite, by test and position of the assertion in the test.
+type snapshots struct {
+	path    string
+	update  bool
+	entries map[string]interface{}
+	used    map[string]bool
+	changed bool
+}
+
+// snapshotPath returns the location of the snapshot file of the suite at
+// suitePath.
+func snapshotPath(suitePath string) string {
+	base := strings.TrimSuffix(filepath.Base(suitePath), filepath.Ext(suitePath))
+	return filepath.Join(filepath.Dir(suitePath), SnapshotDir, base+".snap")
+}
+
+func loadSnapshots(path string, update bool) (*snapshots, error) {
+	s := &snapshots{
+		path:    path,
+		update:  update,
+		entries: map[string]interface{}{},
+		used:    map[string]bool{},
+	}
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return s, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if err := yaml.Unmarshal(data, &s.entries); err != nil {
+		return nil, errors.Wrapf(err, "unable to parse snapshot file %s", path)
+	}
+	if s.entries == nil {
+		s.entries = map[string]interface{}{}
+	}
+	return s, nil
+}
+
+// match compares v with the snapshot recorded under key. A missing snapshot is
+// recorded, as is a differing one when updating snapshots.
+func (s *snapshots) match(key string, v interface{}) error {
+	s.used[key] = true
+
+	// Round trip the value to compare it in the form it is stored in.
+	b, err := yaml.Marshal(v)
+	if err != nil {
+		return err
+	}
+	var got interface{}
+	if err := yaml.Unmarshal(b, &got); err != nil {
+		return err
+	}
+
+	want, ok := s.entries[key]
+	if ok && reflect.DeepEqual(want, got) {
+		return nil
+	}
+	if !ok || s.update {
+		s.entries[key] = got
+		s.changed = true
+		return nil
+	}
+
+	wantYAML, _ := yaml.Marshal(want)
+	diff, _ := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(strings.TrimSuffix(string(wantYAML), "\n")),
+		B:        difflib.SplitLines(strings.TrimSuffix(string(b), "\n")),
+		FromFile: "snapshot",
+		ToFile:   "rendered",
+		Context:  3,
+	})
+	return errors.Errorf("does not match snapshot %q, run with --update-snapshots to accept the change:\n%s", key, diff)
+}
+
+// keep marks the snapshots of the test it as used, so they are not removed
+// when the test could not be run.
+func (s *snapshots) keep(it string) {
+	for key := range s.entries {
+		if strings.HasPrefix(key, it+" ") {
+			s.used[key] = true
+		}
+	}
+}
+
+// save writes the snapshot file if snapshots were added or updated. When
+// updating snapshots, those no longer used by any test are removed.
+func (s *snapshots) save() error {
+	if s.update {
+		for key := range s.entries {
+			if !s.used[key] {
+				delete(s.entries, key)
+				s.changed = true
+			}
+		}
+	}
+	if !s.changed {
+		return nil
+	}
+	if len(s.entries) == 0 {
+		if err := os.Remove(s.path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+
+	data, err := yaml.Marshal(s.entries)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(s.path, data, 0644)
+}
@@ -0,0 +1,145 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package unittest
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/yaml"
+)
+
+// TestsDir is the directory of a chart holding its unit tests.
+const TestsDir = "tests"
+
+// suiteSuffix is the file name suffix of test suites.
+const suiteSuffix = "_test.yaml"
+
+// Suite is a file of unit tests for the templates of a chart.
+type Suite struct {
+	// Name describes the suite. It defaults to the name of the suite file.
+	Name string `json:"suite,omitempty"`
+	// Templates are the templates the assertions apply to, relative to the
+	// chart directory, e.g. "templates/deployment.yaml". If empty, assertions
+	// apply to all templates of the chart.
+	Templates []string `json:"templates,omitempty"`
+	// Release is the release the templates are rendered for.
+	Release Release `json:"release,omitempty"`
+	// Capabilities are the capabilities of the cluster the templates are
+	// rendered for.
+	Capabilities Capabilities `json:"capabilities,omitempty"`
+	// Values are values files, relative to the suite file, which are merged
+	// with the values of the chart.
+	Values []string `json:"values,omitempty"`
+	// Set are values which override those of the values files, by their
+	// dotted key, e.g. "image.tag".
+	Set map[string]interface{} `json:"set,omitempty"`
+	// Tests are the tests of the suite.
+	Tests []*Test `json:"tests"`
+
+	// path is the location of the suite file.
+	path string
+}
+
+// Test is a single unit test, a set of assertions on the templates of a chart
+// rendered with the same values.
+type Test struct {
+	// It describes the behavior under test, e.g. "should set the replicas".
+	It string `json:"it"`
+	// Templates override the templates of the suite.
+	Templates []string `json:"templates,omitempty"`
+	// Release overrides the release of the suite, field by field.
+	Release *Release `json:"release,omitempty"`
+	// Capabilities override the capabilities of the suite, field by field.
+	Capabilities *Capabilities `json:"capabilities,omitempty"`
+	// Values are values files merged after those of the suite.
+	Values []string `json:"values,omitempty"`
+	// Set are values set after those of the suite.
+	Set map[string]interface{} `json:"set,omitempty"`
+	// Asserts are the assertions on the rendered templates.
+	Asserts []*Assertion `json:"asserts"`
+}
+
+// Release describes the release templates are rendered for.
+type Release struct {
+	Name      string `json:"name,omitempty"`
+	Namespace string `json:"namespace,omitempty"`
+	Revision  int    `json:"revision,omitempty"`
+	IsUpgrade bool   `json:"isUpgrade,omitempty"`
+}
+
+// Capabilities describes the cluster templates are rendered for.
+type Capabilities struct {
+	// KubeVersion is the version of Kubernetes, e.g. "v1.17.0".
+	KubeVersion string `json:"kubeVersion,omitempty"`
+	// APIVersions are available in addition to the default API versions.
+	APIVersions []string `json:"apiVersions,omitempty"`
+}
+
+// Path returns the location of the suite file.
+func (s *Suite) Path() string {
+	return s.path
+}
+
+// LoadSuites loads the test suites of the chart in chartDir, which are the
+// files in its tests directory ending in _test.yaml, in file name order.
+func LoadSuites(chartDir string) ([]*Suite, error) {
+	paths, err := filepath.Glob(filepath.Join(chartDir, TestsDir, "*"+suiteSuffix))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+
+	suites := make([]*Suite, 0, len(paths))
+	for _, p := range paths {
+		s, err := LoadSuite(p)
+		if err != nil {
+			return nil, err
+		}
+		suites = append(suites, s)
+	}
+	return suites, nil
+}
+
+// LoadSuite loads the test suite in the file at path.
+func LoadSuite(path string) (*Suite, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	s := &Suite{path: path}
+	if err := yaml.UnmarshalStrict(data, s); err != nil {
+		return nil, errors.Wrapf(err, "unable to parse test suite %s", path)
+	}
+	if s.Name == "" {
+		s.Name = strings.TrimSuffix(filepath.Base(path), suiteSuffix)
+	}
+	for i, t := range s.Tests {
+		if t.It == "" {
+			return nil, errors.Errorf("test suite %s: test %d has no description", path, i+1)
+		}
+		for j, a := range t.Asserts {
+			if err := a.validate(); err != nil {
+				return nil, errors.Wrapf(err, "test suite %s: %q: assertion %d", path, t.It, j+1)
+			}
+		}
+	}
+	return s, nil
+}
@@ -0,0 +1,3 @@
+{{- define "chart.name" -}}
+{{ .Values.name }}
+{{- end -}}
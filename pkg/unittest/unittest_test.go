@@ -0,0 +1,230 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package unittest
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRun(t *testing.T) {
+	suites, err := LoadSuites("testdata/chart")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(suites) != 1 || suites[0].Name != "config maps" {
+		t.Fatalf("unexpected suites %v", suites)
+	}
+
+	r := &Runner{ChartPath: "testdata/chart"}
+	result, err := r.Run(suites[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Tests) != 3 {
+		t.Fatalf("expected 3 test results, got %d", len(result.Tests))
+	}
+	for _, tr := range result.Tests[:2] {
+		if !tr.Passed() {
+			t.Errorf("expected %q to pass, got %v %v", tr.Test.It, tr.Failures, tr.Err)
+		}
+	}
+
+	expect := []string{
+		`1: notEqual: document 0: expected metadata.name not to equal "web-80"`,
+		`2: hasDocuments: expected 1 documents, got 2`,
+		`3: equal: documentIndex 2 out of range, got 2 documents`,
+		`4: equal: template templates/missing.yaml not found in chart`,
+	}
+	if got := result.Tests[2].Failures; !reflect.DeepEqual(got, expect) {
+		t.Errorf("expected failures\n%q\ngot\n%q", expect, got)
+	}
+	if result.Failed() != 1 {
+		t.Errorf("expected 1 failed test, got %d", result.Failed())
+	}
+}
+
+func TestLoadSuiteInvalid(t *testing.T) {
+	dir, err := ioutil.TempDir("", "helm-unittest-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	tests := []struct {
+		suite string
+		err   string
+	}{{
+		suite: "tests:\n  - asserts: []\n",
+		err:   "test 1 has no description",
+	}, {
+		suite: "tests:\n  - it: a\n    asserts:\n      - {}\n",
+		err:   "assertion 1: no check given",
+	}, {
+		suite: "tests:\n  - it: a\n    asserts:\n      - hasDocuments: {count: 1}\n        failedTemplate: {}\n",
+		err:   "assertion 1: only one check may be given",
+	}, {
+		suite: "tests:\n  - it: a\n    asserts:\n      - matchRegex: {path: a, pattern: '('}\n",
+		err:   "assertion 1: invalid pattern",
+	}, {
+		suite: "tests:\n  - it: a\n    assert: []\n",
+		err:   `unknown field "assert"`,
+	}}
+	for _, tt := range tests {
+		p := filepath.Join(dir, "suite_test.yaml")
+		if err := ioutil.WriteFile(p, []byte(tt.suite), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadSuite(p); err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("expected error containing %q, got %v", tt.err, err)
+		}
+	}
+}
+
+func TestLookup(t *testing.T) {
+	doc := map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"labels": map[string]interface{}{"app.kubernetes.io/name": "web"},
+		},
+		"spec": map[string]interface{}{
+			"containers": []interface{}{
+				map[string]interface{}{"name": "a"},
+				map[string]interface{}{"name": "b"},
+			},
+		},
+	}
+	tests := []struct {
+		path   string
+		expect interface{}
+	}{
+		{`metadata.labels.app\.kubernetes\.io/name`, "web"},
+		{`$.spec.containers[1].name`, "b"},
+		{`{.spec.containers[0].name}`, "a"},
+		{`spec.containers[*].name`, []interface{}{"a", "b"}},
+		{`spec.missing`, nil},
+	}
+	for _, tt := range tests {
+		got, err := lookup(doc, tt.path)
+		if err != nil {
+			t.Errorf("%s: %s", tt.path, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.expect) {
+			t.Errorf("%s: expected %v, got %v", tt.path, tt.expect, got)
+		}
+	}
+}
+
+func TestSnapshots(t *testing.T) {
+	dir, err := ioutil.TempDir("", "helm-unittest-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := snapshotPath(filepath.Join(dir, "deployment_test.yaml"))
+	if expect := filepath.Join(dir, SnapshotDir, "deployment_test.snap"); path != expect {
+		t.Fatalf("expected snapshot path %s, got %s", expect, path)
+	}
+	doc := map[string]interface{}{"replicas": 1}
+
+	// A missing snapshot is recorded.
+	s, err := loadSnapshots(path, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.match("a 1", doc); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.match("b 1", doc); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.save(); err != nil {
+		t.Fatal(err)
+	}
+
+	// A changed value does not match the recorded snapshot.
+	s, err = loadSnapshots(path, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.match("a 1", doc); err != nil {
+		t.Errorf("expected the snapshot to match, got %s", err)
+	}
+	err = s.match("a 1", map[string]interface{}{"replicas": 2})
+	if err == nil || !strings.Contains(err.Error(), "-replicas: 1\n+replicas: 2\n") {
+		t.Errorf("expected a diff of the snapshot, got %v", err)
+	}
+
+	// Updating accepts the change and removes unused snapshots.
+	s, err = loadSnapshots(path, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.match("a 1", map[string]interface{}{"replicas": 2}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.save(); err != nil {
+		t.Fatal(err)
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expect := "a 1:\n  replicas: 2\n"; string(data) != expect {
+		t.Errorf("expected snapshot file %q, got %q", expect, string(data))
+	}
+}
+
+func TestWriteJUnit(t *testing.T) {
+	test := &Test{It: "renders <all>", Asserts: []*Assertion{{}, {}}}
+	results := []*SuiteResult{{
+		Suite: &Suite{Name: "deployment"},
+		Tests: []*TestResult{
+			{Test: test},
+			{Test: test, Failures: []string{"1: equal: a", "2: equal: b"}},
+			{Test: test, Err: errors.New("missing values file")},
+		},
+	}}
+
+	var b bytes.Buffer
+	if err := WriteJUnit(&b, "web", results); err != nil {
+		t.Fatal(err)
+	}
+	expect := `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites>
+  <testsuite name="deployment" tests="3" failures="1" errors="1" time="0.000">
+    <testcase name="renders &lt;all&gt;" classname="web.deployment" time="0.000"></testcase>
+    <testcase name="renders &lt;all&gt;" classname="web.deployment" time="0.000">
+      <failure message="2 of 2 assertions failed">1: equal: a&#xA;2: equal: b</failure>
+    </testcase>
+    <testcase name="renders &lt;all&gt;" classname="web.deployment" time="0.000">
+      <error message="missing values file">missing values file</error>
+    </testcase>
+  </testsuite>
+</testsuites>
+`
+	if b.String() != expect {
+		t.Errorf("expected\n%s\ngot\n%s", expect, b.String())
+	}
+}